@@ -3,70 +3,148 @@ package main
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/defer-panic/news-feed-bot/internal/api"
 	"github.com/defer-panic/news-feed-bot/internal/bot"
 	"github.com/defer-panic/news-feed-bot/internal/bot/middleware"
 	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/canonical"
 	"github.com/defer-panic/news-feed-bot/internal/config"
+	"github.com/defer-panic/news-feed-bot/internal/dashboard"
+	"github.com/defer-panic/news-feed-bot/internal/feed"
 	"github.com/defer-panic/news-feed-bot/internal/fetcher"
+	"github.com/defer-panic/news-feed-bot/internal/logging"
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
 	"github.com/defer-panic/news-feed-bot/internal/notifier"
+	"github.com/defer-panic/news-feed-bot/internal/source"
 	"github.com/defer-panic/news-feed-bot/internal/storage"
 	"github.com/defer-panic/news-feed-bot/internal/summary"
 )
 
+const (
+	httpReadHeaderTimeout = 10 * time.Second
+	httpShutdownTimeout   = 5 * time.Second
+)
+
 func main() {
+	logger, err := logging.New(os.Stderr, config.Get().LogLevel, config.Get().LogFormat)
+	if err != nil {
+		slog.Error("failed to create logger", "error", err)
+		return
+	}
+	slog.SetDefault(logger)
+
 	botAPI, err := tgbotapi.NewBotAPI(config.Get().TelegramBotToken)
 	if err != nil {
-		log.Printf("[ERROR] failed to create botAPI: %v", err)
+		slog.Error("failed to create botAPI", "error", err)
 		return
 	}
 
 	db, err := sqlx.Connect("postgres", config.Get().DatabaseDSN)
 	if err != nil {
-		log.Printf("[ERROR] failed to connect to db: %v", err)
+		slog.Error("failed to connect to db", "error", err)
 		return
 	}
 	defer db.Close()
 
+	summarizer, err := newSummarizer(config.Get().SummaryProviders, storage.NewSummaryCacheStorage(db))
+	if err != nil {
+		slog.Error("failed to create summarizer", "error", err)
+		return
+	}
+
 	var (
-		articleStorage = storage.NewArticleStorage(db)
-		sourceStorage  = storage.NewSourceStorage(db)
-		fetcher        = fetcher.New(
+		articleStorage    = storage.NewArticleStorage(db)
+		sourceStorage     = storage.NewSourceStorage(db)
+		filterRuleStorage = storage.NewFilterRuleStorage(db)
+		topicStorage      = storage.NewTopicStorage(db)
+		channelStorage    = storage.NewChannelStorage(db)
+		httpClient        = source.NewHTTPClient(http.DefaultClient, config.Get().FetchUserAgent, config.Get().FetchMaxBodySize)
+		sourceRegistry    = fetcher.DefaultRegistry(httpClient)
+		linkResolver      = canonical.NewResolver(httpClient)
+		publisherRegistry = notifier.DefaultRegistry(botAPI, http.DefaultClient)
+		fetcher           = fetcher.New(
 			articleStorage,
 			sourceStorage,
-			config.Get().FetchInterval,
-			config.Get().FilterKeywords,
+			sourceRegistry,
+			fetcher.Config{
+				Rules:  filterRuleStorage,
+				Topics: topicStorage,
+				Links:  linkResolver,
+				Schedule: fetcher.Schedule{
+					DefaultInterval: config.Get().FetchInterval,
+					MinInterval:     config.Get().AdaptiveFetchMin,
+					MaxInterval:     config.Get().AdaptiveFetchMax,
+					DefaultTimeout:  config.Get().FetchTimeout,
+				},
+				Filter: fetcher.Filter{
+					Keywords:  config.Get().FilterKeywords,
+					AllowList: config.Get().AllowList,
+				},
+				MaxFailures: config.Get().SourceMaxFailures,
+				Concurrency: fetcher.Concurrency{
+					Max:     config.Get().FetchConcurrency,
+					PerHost: config.Get().FetchHostConcurrency,
+				},
+				DedupWindow: config.Get().DedupWindow,
+			},
 		)
-		summarizer = summary.NewOpenAISummarizer(
-			config.Get().OpenAIKey,
-			config.Get().OpenAIModel,
-			config.Get().OpenAIPrompt,
+		summaries = notifier.NewSummaries(
+			articleStorage,
+			summarizer,
+			config.Get().SummaryInterval,
+			2*config.Get().FetchInterval,
+			config.Get().SummaryMaxAttempts,
 		)
 		notifier = notifier.New(
 			articleStorage,
-			summarizer,
-			botAPI,
+			channelStorage,
+			topicStorage,
+			summaries,
+			publisherRegistry,
 			config.Get().NotificationInterval,
 			2*config.Get().FetchInterval,
-			config.Get().TelegramChannelID,
 		)
 	)
 
+	if err := articleStorage.CanonicalizeLinks(context.Background(), canonical.URL); err != nil {
+		slog.Error("failed to canonicalize links of stored articles", "error", err)
+		return
+	}
+
+	err = channelStorage.BindDefault(context.Background(), config.Get().TelegramChannelID)
+	switch {
+	case errors.Is(err, storage.ErrChatBound):
+		// the other channel keeps posting to the chat, so the bot can still run
+		slog.Error(
+			"chat of the default channel is added as another channel, the default channel is not posted to; "+
+				"delete the other channel with /deletechannel to post articles of the default one",
+			"chat_id", config.Get().TelegramChannelID,
+		)
+	case err != nil:
+		slog.Error("failed to set chat of the default channel", "error", err)
+		return
+	}
+
 	newsBot := botkit.New(botAPI)
 	newsBot.RegisterCmdView(
 		"addsource",
 		middleware.AdminsOnly(
 			config.Get().TelegramChannelID,
-			bot.ViewCmdAddSource(sourceStorage),
+			bot.ViewCmdAddSource(sourceStorage, sourceRegistry),
 		),
 	)
 	newsBot.RegisterCmdView(
@@ -90,6 +168,48 @@ func main() {
 			bot.ViewCmdListSource(sourceStorage),
 		),
 	)
+	newsBot.RegisterCmdView(
+		"setselectors",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetSelectors(sourceStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"setschedule",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetSchedule(sourceStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"settimeout",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetTimeout(sourceStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"sourcehealth",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSourceHealth(sourceStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"regensummary",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdRegenSummary(summaries),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"enablesource",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdEnableSource(sourceStorage),
+		),
+	)
 	newsBot.RegisterCmdView(
 		"deletesource",
 		middleware.AdminsOnly(
@@ -97,11 +217,156 @@ func main() {
 			bot.ViewCmdDeleteSource(sourceStorage),
 		),
 	)
+	newsBot.RegisterCmdView(
+		"addrule",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdAddRule(filterRuleStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"listrules",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdListRules(filterRuleStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"setruledryrun",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetRuleDryRun(filterRuleStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"testrules",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdTestRules(filterRuleStorage, config.Get().FilterKeywords),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"deleterule",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdDeleteRule(filterRuleStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"addtopic",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdAddTopic(topicStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"listtopics",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdListTopics(topicStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"deletetopic",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdDeleteTopic(topicStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"setallowlist",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetAllowList(sourceStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"addchannel",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdAddChannel(channelStorage, publisherRegistry),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"listchannels",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdListChannels(channelStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"subscribe",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSubscribe(channelStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"unsubscribe",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdUnsubscribe(channelStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"setallsources",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetAllSources(channelStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"setdigest",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdSetDigest(channelStorage),
+		),
+	)
+	newsBot.RegisterCmdView(
+		"deletechannel",
+		middleware.AdminsOnly(
+			config.Get().TelegramChannelID,
+			bot.ViewCmdDeleteChannel(channelStorage),
+		),
+	)
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
-	})
+	prometheus.MustRegister(metrics.NewQueueDepthCollector(articleStorage, 2*config.Get().FetchInterval))
+
+	var apiHandler, dashboardHandler http.Handler
+
+	if token := config.Get().AdminAPIToken; token != "" {
+		apiHandler = api.New(sourceStorage, articleStorage, sourceRegistry, fetcher, summaries, token)
+	} else {
+		slog.Info("admin API is disabled, set admin API token to enable it")
+	}
+
+	if password := config.Get().DashboardPassword; password != "" {
+		dashboardHandler = dashboard.New(
+			sourceStorage,
+			sourceRegistry,
+			articleStorage,
+			channelStorage,
+			notifier,
+			config.Get().DashboardUser,
+			password,
+		)
+	} else {
+		slog.Info("dashboard is disabled, set dashboard password to enable it")
+	}
+
+	httpHandler := newHTTPHandler(
+		feed.NewHandler(articleStorage, config.Get().FeedTitle, config.Get().FeedBaseURL, config.Get().FeedSize),
+		apiHandler,
+		dashboardHandler,
+	)
+
+	// the address is bound before anything is started, so a wrong one stops the bot instead of silently
+	// leaving feeds, API, dashboard and metrics unavailable
+	listener, err := net.Listen("tcp", config.Get().HTTPAddr)
+	if err != nil {
+		slog.Error("failed to listen http address", "address", config.Get().HTTPAddr, "error", err)
+		return
+	}
 
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
@@ -109,37 +374,183 @@ func main() {
 	go func(ctx context.Context) {
 		if err := fetcher.Start(ctx); err != nil {
 			if !errors.Is(err, context.Canceled) {
-				log.Printf("[ERROR] failed to run fetcher: %v", err)
+				slog.Error("failed to run fetcher", "error", err)
 				return
 			}
 
-			log.Printf("[INFO] fetcher stopped")
+			slog.Info("fetcher stopped")
 		}
 	}(ctx)
 
 	go func(ctx context.Context) {
-		if err := notifier.Start(ctx); err != nil {
+		if err := summaries.Start(ctx); err != nil {
 			if !errors.Is(err, context.Canceled) {
-				log.Printf("[ERROR] failed to run notifier: %v", err)
+				slog.Error("failed to run summary worker", "error", err)
 				return
 			}
 
-			log.Printf("[INFO] notifier stopped")
+			slog.Info("summary worker stopped")
 		}
 	}(ctx)
 
 	go func(ctx context.Context) {
-		if err := http.ListenAndServe("9.0.0.0:8080", mux); err != nil {
+		if err := notifier.Start(ctx); err != nil {
 			if !errors.Is(err, context.Canceled) {
-				log.Printf("[ERROR] failed to run http server: %v", err)
+				slog.Error("failed to run notifier", "error", err)
 				return
 			}
 
-			log.Printf("[INFO] http server stopped")
+			slog.Info("notifier stopped")
 		}
 	}(ctx)
 
+	go func(ctx context.Context) {
+		if err := serveHTTP(ctx, listener, httpHandler); err != nil {
+			slog.Error("failed to run http server", "error", err)
+			return
+		}
+
+		slog.Info("http server stopped")
+	}(ctx)
+
 	if err := newsBot.Run(ctx); err != nil {
-		log.Printf("[ERROR] failed to run botkit: %v", err)
+		slog.Error("failed to run botkit", "error", err)
 	}
 }
+
+// newHTTPHandler routes requests to health check, metrics and feeds, and to admin API and dashboard
+// if they are enabled, i.e. their handlers are not nil.
+func newHTTPHandler(feedHandler *feed.Handler, apiHandler, dashboardHandler http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc(feed.RSSPath, feedHandler.RSS)
+	mux.HandleFunc(feed.AtomPath, feedHandler.Atom)
+	mux.HandleFunc(feed.JSONPath, feedHandler.JSON)
+
+	if apiHandler != nil {
+		mux.Handle("/api/", apiHandler)
+	}
+
+	if dashboardHandler != nil {
+		mux.Handle("/admin/", dashboardHandler)
+	}
+
+	return mux
+}
+
+// serveHTTP serves requests accepted by the listener until ctx is done, then shuts the server down.
+func serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler) error {
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: httpReadHeaderTimeout}
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			slog.Error("failed to shut down http server", "error", err)
+		}
+	}()
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// newSummarizer creates summarizer using providers in given order: the next provider is used
+// when the previous one fails. Providers with no API key are skipped, and articles are summarized
+// locally if none is left.
+func newSummarizer(providers []string, cacheStorage summary.CacheStorage) (notifier.Summarizer, error) {
+	var (
+		summarizers = make([]summary.Summarizer, 0, len(providers))
+		budget      = summary.Budget{
+			ContextTokens: config.Get().SummaryContextTokens,
+			MaxTokens:     config.Get().SummaryTokenBudget,
+			Parallelism:   config.Get().SummaryConcurrency,
+		}
+	)
+
+	for _, provider := range providers {
+		switch provider {
+		case "openai":
+			if config.Get().OpenAIKey == "" && config.Get().OpenAIBaseURL == "" {
+				slog.Warn("openai summarizer is skipped, set openai key or base URL to enable it")
+				continue
+			}
+
+			summarizers = append(summarizers, withCache(
+				summary.NewMapReduce(
+					summary.NewOpenAISummarizer(
+						config.Get().OpenAIKey,
+						config.Get().OpenAIBaseURL,
+						config.Get().OpenAIModel,
+						config.Get().OpenAIPrompt,
+					),
+					budget,
+				),
+				cacheStorage,
+				config.Get().OpenAIModel,
+				config.Get().OpenAIPrompt,
+			))
+		case "anthropic":
+			if config.Get().AnthropicKey == "" {
+				slog.Warn("anthropic summarizer is skipped, set anthropic key to enable it")
+				continue
+			}
+
+			summarizers = append(summarizers, withCache(
+				summary.NewMapReduce(
+					summary.NewAnthropicSummarizer(
+						http.DefaultClient,
+						config.Get().AnthropicKey,
+						config.Get().AnthropicBaseURL,
+						config.Get().AnthropicModel,
+						config.Get().AnthropicPrompt,
+					),
+					budget,
+				),
+				cacheStorage,
+				config.Get().AnthropicModel,
+				config.Get().AnthropicPrompt,
+			))
+		case "extractive":
+			summarizers = append(summarizers, summary.NewExtractiveSummarizer(config.Get().SummarySentences))
+		default:
+			return nil, fmt.Errorf("unknown summary provider %q", provider)
+		}
+	}
+
+	if len(summarizers) == 0 {
+		slog.Info("no summary provider is configured, articles are summarized with extractive summarizer")
+		return summary.NewExtractiveSummarizer(config.Get().SummarySentences), nil
+	}
+
+	if len(summarizers) == 1 {
+		return summarizers[0], nil
+	}
+
+	return summary.NewFallback(summarizers...), nil
+}
+
+// withCache caches summaries of LLM summarizer unless the cache is disabled with zero size.
+// Every LLM summarizer is cached separately, as the cache key includes the model and the prompt of the summarizer.
+func withCache(summarizer summary.Summarizer, cacheStorage summary.CacheStorage, model, prompt string) summary.Summarizer {
+	if config.Get().SummaryCacheSize <= 0 {
+		return summarizer
+	}
+
+	return summary.NewCache(
+		cacheStorage,
+		summarizer,
+		model,
+		prompt,
+		config.Get().SummaryCacheTTL,
+		config.Get().SummaryCacheSize,
+	)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/feed"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type postedArticlesStub struct{}
+
+func (postedArticlesStub) Posted(context.Context, int64, uint64) ([]model.Article, error) {
+	return []model.Article{{ID: 1, Title: "Go 1.21 is released", Link: "https://go.dev/blog/go1.21"}}, nil
+}
+
+func TestServeHTTP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		served      = make(chan error, 1)
+		handler     = newHTTPHandler(
+			feed.NewHandler(postedArticlesStub{}, "News Feed Bot", "", 10),
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "api") }),
+			nil,
+		)
+		baseURL = "http://" + listener.Addr().String()
+	)
+	defer cancel()
+
+	go func() { served <- serveHTTP(ctx, listener, handler) }()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(baseURL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp.StatusCode, string(body)
+	}
+
+	status, _ := get("/healthz")
+	assert.Equal(t, http.StatusOK, status)
+
+	status, body := get("/metrics")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "go_goroutines")
+
+	status, body = get("/feed.rss")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "Go 1.21 is released")
+
+	status, body = get("/api/sources")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "api", body)
+
+	// disabled dashboard is not served
+	status, _ = get("/admin/")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	cancel()
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(httpShutdownTimeout):
+		t.Fatal("http server is not stopped")
+	}
+}
@@ -0,0 +1,250 @@
+// Package api is the admin REST API for scripting management of sources and articles.
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type SourceStorage interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+	Add(ctx context.Context, source model.Source) (int64, error)
+	Update(ctx context.Context, source model.Source) error
+	Enable(ctx context.Context, id int64) error
+	Delete(ctx context.Context, id int64) error
+}
+
+type ArticleStorage interface {
+	Articles(ctx context.Context, query model.ArticleQuery) ([]model.Article, error)
+	ArticleByID(ctx context.Context, id int64) (*model.Article, error)
+	MarkAsPosted(ctx context.Context, articleID, channelID int64) error
+	Skip(ctx context.Context, articleID int64) error
+}
+
+type SourceKinds interface {
+	Supports(kind string) bool
+	Kinds() []string
+}
+
+// FetchTrigger makes the fetcher fetch all sources without waiting for their schedule.
+type FetchTrigger interface {
+	Trigger()
+}
+
+// SummaryRegenerator replaces the stored summary of an article with a newly generated one.
+type SummaryRegenerator interface {
+	Regenerate(ctx context.Context, articleID int64) (model.Summary, error)
+}
+
+type Handler struct {
+	sources   SourceStorage
+	articles  ArticleStorage
+	kinds     SourceKinds
+	fetcher   FetchTrigger
+	summaries SummaryRegenerator
+	token     string
+}
+
+// New creates handler of the API mounted at /api/. Every request must be authorized
+// with the token in "Authorization: Bearer <token>" header.
+func New(
+	sources SourceStorage,
+	articles ArticleStorage,
+	kinds SourceKinds,
+	fetcher FetchTrigger,
+	summaries SummaryRegenerator,
+	token string,
+) *Handler {
+	return &Handler{
+		sources:   sources,
+		articles:  articles,
+		kinds:     kinds,
+		fetcher:   fetcher,
+		summaries: summaries,
+		token:     token,
+	}
+}
+
+// errorResponse is the body of all failed responses.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// statusError is an error which is reported to the client with its own status code.
+type statusError struct {
+	status  int
+	message string
+}
+
+func (e statusError) Error() string {
+	return e.message
+}
+
+func badRequest(message string) error {
+	return statusError{status: http.StatusBadRequest, message: message}
+}
+
+var errNotFound = statusError{status: http.StatusNotFound, message: "not found"}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+		writeJSON(w, http.StatusUnauthorized, errorResponse{Error: "unauthorized"})
+		return
+	}
+
+	if err := h.route(w, r); err != nil {
+		writeError(w, err)
+	}
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || h.token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) == 1
+}
+
+// route dispatches the request by its path relative to /api/ and method.
+func (h *Handler) route(w http.ResponseWriter, r *http.Request) error {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api"), "/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case path == "sources":
+		return methods(w, r, map[string]handlerFunc{
+			http.MethodGet:  h.listSources,
+			http.MethodPost: h.addSource,
+		})
+	case len(segments) == 2 && segments[0] == "sources":
+		return withID(segments[1], func(id int64) error {
+			return methods(w, r, map[string]handlerFunc{
+				http.MethodGet:    func(w http.ResponseWriter, r *http.Request) error { return h.getSource(w, r, id) },
+				http.MethodPatch:  func(w http.ResponseWriter, r *http.Request) error { return h.updateSource(w, r, id) },
+				http.MethodDelete: func(w http.ResponseWriter, r *http.Request) error { return h.deleteSource(w, r, id) },
+			})
+		})
+	case len(segments) == 3 && segments[0] == "sources" && segments[2] == "enable":
+		return withID(segments[1], func(id int64) error {
+			return methods(w, r, map[string]handlerFunc{
+				http.MethodPost: func(w http.ResponseWriter, r *http.Request) error { return h.enableSource(w, r, id) },
+			})
+		})
+	case path == "articles":
+		return methods(w, r, map[string]handlerFunc{
+			http.MethodGet: h.listArticles,
+		})
+	case len(segments) == 2 && segments[0] == "articles":
+		return withID(segments[1], func(id int64) error {
+			return methods(w, r, map[string]handlerFunc{
+				http.MethodGet: func(w http.ResponseWriter, r *http.Request) error { return h.getArticle(w, r, id) },
+			})
+		})
+	case len(segments) == 3 && segments[0] == "articles" && segments[2] == "posted":
+		return withID(segments[1], func(id int64) error {
+			return methods(w, r, map[string]handlerFunc{
+				http.MethodPost: func(w http.ResponseWriter, r *http.Request) error { return h.markAsPosted(w, r, id) },
+			})
+		})
+	case len(segments) == 3 && segments[0] == "articles" && segments[2] == "skipped":
+		return withID(segments[1], func(id int64) error {
+			return methods(w, r, map[string]handlerFunc{
+				http.MethodPost: func(w http.ResponseWriter, r *http.Request) error { return h.skipArticle(w, r, id) },
+			})
+		})
+	case len(segments) == 3 && segments[0] == "articles" && segments[2] == "summary":
+		return withID(segments[1], func(id int64) error {
+			return methods(w, r, map[string]handlerFunc{
+				http.MethodPost: func(w http.ResponseWriter, r *http.Request) error { return h.regenerateSummary(w, r, id) },
+			})
+		})
+	case path == "fetch":
+		return methods(w, r, map[string]handlerFunc{
+			http.MethodPost: h.triggerFetch,
+		})
+	default:
+		return errNotFound
+	}
+}
+
+type handlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+func methods(w http.ResponseWriter, r *http.Request, handlers map[string]handlerFunc) error {
+	handler, ok := handlers[r.Method]
+	if !ok {
+		allowed := make([]string, 0, len(handlers))
+		for method := range handlers {
+			allowed = append(allowed, method)
+		}
+
+		sort.Strings(allowed)
+		w.Header().Set("Allow", strings.Join(allowed, ", "))
+
+		return statusError{status: http.StatusMethodNotAllowed, message: "method not allowed"}
+	}
+
+	return handler(w, r)
+}
+
+func withID(value string, fn func(id int64) error) error {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return errNotFound
+	}
+
+	return fn(id)
+}
+
+func (h *Handler) triggerFetch(w http.ResponseWriter, _ *http.Request) error {
+	h.fetcher.Trigger()
+	w.WriteHeader(http.StatusAccepted)
+
+	return nil
+}
+
+func readJSON(r *http.Request, v any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: " + err.Error())
+	}
+
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write API response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	var statusErr statusError
+
+	switch {
+	case errors.As(err, &statusErr):
+		writeJSON(w, statusErr.status, errorResponse{Error: statusErr.message})
+	case errors.Is(err, sql.ErrNoRows):
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: errNotFound.message})
+	default:
+		slog.Error("failed to handle API request", "error", err)
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: http.StatusText(http.StatusInternalServerError)})
+	}
+}
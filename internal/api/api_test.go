@@ -0,0 +1,354 @@
+package api_test
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/api"
+	"github.com/defer-panic/news-feed-bot/internal/fetcher"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const token = "secret"
+
+type sourceStorageStub struct {
+	mu      sync.Mutex
+	sources map[int64]model.Source
+	nextID  int64
+}
+
+func (s *sourceStorageStub) Sources(context.Context) ([]model.Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sources []model.Source
+	for id := int64(1); id < s.nextID; id++ {
+		if source, ok := s.sources[id]; ok {
+			sources = append(sources, source)
+		}
+	}
+
+	return sources, nil
+}
+
+func (s *sourceStorageStub) SourceByID(_ context.Context, id int64) (*model.Source, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source, ok := s.sources[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &source, nil
+}
+
+func (s *sourceStorageStub) Add(_ context.Context, source model.Source) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source.ID = s.nextID
+	s.sources[source.ID] = source
+	s.nextID++
+
+	return source.ID, nil
+}
+
+func (s *sourceStorageStub) Update(_ context.Context, source model.Source) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sources[source.ID] = source
+
+	return nil
+}
+
+func (s *sourceStorageStub) Enable(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	source := s.sources[id]
+	source.Health = model.SourceHealth{}
+	s.sources[id] = source
+
+	return nil
+}
+
+func (s *sourceStorageStub) Delete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sources, id)
+
+	return nil
+}
+
+type articleStorageStub struct {
+	mu       sync.Mutex
+	articles map[int64]model.Article
+	query    model.ArticleQuery
+	postedTo []int64
+}
+
+func (s *articleStorageStub) Articles(_ context.Context, query model.ArticleQuery) ([]model.Article, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.query = query
+
+	return []model.Article{s.articles[1]}, nil
+}
+
+func (s *articleStorageStub) ArticleByID(_ context.Context, id int64) (*model.Article, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	article, ok := s.articles[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &article, nil
+}
+
+func (s *articleStorageStub) MarkAsPosted(_ context.Context, articleID, channelID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	article := s.articles[articleID]
+	article.PostedAt = time.Now()
+	s.articles[articleID] = article
+	s.postedTo = append(s.postedTo, channelID)
+
+	return nil
+}
+
+func (s *articleStorageStub) Skip(_ context.Context, articleID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	article := s.articles[articleID]
+	article.SkippedAt = time.Now()
+	s.articles[articleID] = article
+
+	return nil
+}
+
+type summariesStub struct {
+	articles *articleStorageStub
+}
+
+func (s *summariesStub) Regenerate(ctx context.Context, articleID int64) (model.Summary, error) {
+	if _, err := s.articles.ArticleByID(ctx, articleID); err != nil {
+		return model.Summary{}, err
+	}
+
+	s.articles.mu.Lock()
+	defer s.articles.mu.Unlock()
+
+	summary := model.Summary{Text: "Go 1.21 adds slog.", Model: "gpt-4o-mini", PromptHash: "abc"}
+
+	article := s.articles.articles[articleID]
+	article.AISummary = summary.Text
+	article.AISummaryModel = summary.Model
+	article.AISummaryPromptHash = summary.PromptHash
+	article.AISummaryGeneratedAt = time.Now()
+	s.articles.articles[articleID] = article
+
+	return summary, nil
+}
+
+type fetchTriggerStub struct {
+	triggered int
+}
+
+func (f *fetchTriggerStub) Trigger() {
+	f.triggered++
+}
+
+type testAPI struct {
+	srv      *httptest.Server
+	sources  *sourceStorageStub
+	articles *articleStorageStub
+	fetcher  *fetchTriggerStub
+}
+
+func setupAPI(t *testing.T) *testAPI {
+	a := &testAPI{
+		sources: &sourceStorageStub{sources: make(map[int64]model.Source), nextID: 1},
+		articles: &articleStorageStub{articles: map[int64]model.Article{
+			1: {
+				ID:          1,
+				SourceID:    1,
+				SourceName:  "Go Blog",
+				Title:       "Go 1.21 is released",
+				Link:        "https://go.dev/blog/go1.21",
+				PublishedAt: time.Date(2023, 8, 8, 0, 0, 0, 0, time.UTC),
+			},
+		}},
+		fetcher: &fetchTriggerStub{},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/", api.New(
+		a.sources,
+		a.articles,
+		fetcher.DefaultRegistry(nil),
+		a.fetcher,
+		&summariesStub{articles: a.articles},
+		token,
+	))
+
+	a.srv = httptest.NewServer(mux)
+	t.Cleanup(a.srv.Close)
+
+	return a
+}
+
+func (a *testAPI) do(t *testing.T, method, path, body string) (int, map[string]any) {
+	status, raw := a.doRaw(t, method, path, body, token)
+	if len(raw) == 0 {
+		return status, nil
+	}
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(raw, &resp), string(raw))
+
+	return status, resp
+}
+
+func (a *testAPI) doRaw(t *testing.T, method, path, body, token string) (int, []byte) {
+	req, err := http.NewRequest(method, a.srv.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, raw
+}
+
+func TestAPI_Auth(t *testing.T) {
+	a := setupAPI(t)
+
+	for _, token := range []string{"", "wrong"} {
+		status, _ := a.doRaw(t, http.MethodGet, "/api/sources", "", token)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	}
+
+	status, _ := a.doRaw(t, http.MethodGet, "/api/sources", "", token)
+	assert.Equal(t, http.StatusOK, status)
+}
+
+func TestAPI_Sources(t *testing.T) {
+	a := setupAPI(t)
+
+	status, resp := a.do(t, http.MethodPost, "/api/sources", `{"name": "Go Blog", "url": "https://go.dev/blog/feed.atom"}`)
+	require.Equal(t, http.StatusCreated, status, resp)
+	assert.Equal(t, float64(1), resp["id"])
+	assert.Equal(t, "rss", resp["kind"])
+
+	status, resp = a.do(t, http.MethodPost, "/api/sources", `{"name": "Go Blog", "url": "https://go.dev", "kind": "ftp"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, resp["error"], "unknown source kind")
+
+	status, resp = a.do(t, http.MethodPost, "/api/sources", `{"name": "Go Blog"}`)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "url is required", resp["error"])
+
+	status, resp = a.do(t, http.MethodPatch, "/api/sources/1", `{"priority": 5, "allow_list": true}`)
+	require.Equal(t, http.StatusOK, status, resp)
+	assert.Equal(t, float64(5), resp["priority"])
+	assert.Equal(t, true, resp["allow_list"])
+	assert.Equal(t, "Go Blog", resp["name"])
+
+	status, raw := a.doRaw(t, http.MethodGet, "/api/sources", "", token)
+	require.Equal(t, http.StatusOK, status)
+
+	var sources []map[string]any
+	require.NoError(t, json.Unmarshal(raw, &sources))
+	require.Len(t, sources, 1)
+	assert.Equal(t, "https://go.dev/blog/feed.atom", sources[0]["url"])
+
+	status, _ = a.do(t, http.MethodDelete, "/api/sources/1", "")
+	assert.Equal(t, http.StatusNoContent, status)
+
+	status, resp = a.do(t, http.MethodGet, "/api/sources/1", "")
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "not found", resp["error"])
+}
+
+func TestAPI_Articles(t *testing.T) {
+	a := setupAPI(t)
+
+	status, raw := a.doRaw(t, http.MethodGet, "/api/articles?q=go&source_id=1&status=pending&limit=10&offset=20", "", token)
+	require.Equal(t, http.StatusOK, status, string(raw))
+	assert.Equal(t, model.ArticleQuery{Text: "go", SourceID: 1, Status: "pending", Limit: 10, Offset: 20}, a.articles.query)
+
+	var articles []map[string]any
+	require.NoError(t, json.Unmarshal(raw, &articles))
+	require.Len(t, articles, 1)
+	assert.Equal(t, "pending", articles[0]["status"])
+	assert.Equal(t, "Go Blog", articles[0]["source"])
+
+	for _, query := range []string{"status=new", "limit=0", "limit=1000", "offset=-1", "source_id=abc"} {
+		status, _ := a.do(t, http.MethodGet, "/api/articles?"+query, "")
+		assert.Equal(t, http.StatusBadRequest, status, query)
+	}
+
+	status, resp := a.do(t, http.MethodPost, "/api/articles/1/skipped", "")
+	require.Equal(t, http.StatusOK, status, resp)
+	assert.Equal(t, "skipped", resp["status"])
+
+	status, resp = a.do(t, http.MethodPost, "/api/articles/1/posted", `{"channel_id": 2}`)
+	require.Equal(t, http.StatusOK, status, resp)
+	assert.Equal(t, "posted", resp["status"])
+	assert.Equal(t, []int64{2}, a.articles.postedTo)
+
+	status, _ = a.do(t, http.MethodPost, "/api/articles/2/posted", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, resp = a.do(t, http.MethodPost, "/api/articles/1/summary", "")
+	require.Equal(t, http.StatusOK, status, resp)
+	assert.Equal(t, "Go 1.21 adds slog.", resp["ai_summary"])
+	assert.Equal(t, "gpt-4o-mini", resp["ai_summary_model"])
+	assert.NotEmpty(t, resp["ai_summary_generated_at"])
+
+	status, _ = a.do(t, http.MethodPost, "/api/articles/2/summary", "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestAPI_Routing(t *testing.T) {
+	a := setupAPI(t)
+
+	status, _ := a.do(t, http.MethodPost, "/api/fetch", "")
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, 1, a.fetcher.triggered)
+
+	status, _ = a.do(t, http.MethodGet, "/api/fetch", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, status)
+
+	status, _ = a.do(t, http.MethodGet, "/api/unknown", "")
+	assert.Equal(t, http.StatusNotFound, status)
+
+	status, _ = a.do(t, http.MethodGet, "/api/sources/abc", "")
+	assert.Equal(t, http.StatusNotFound, status)
+}
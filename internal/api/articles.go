@@ -0,0 +1,192 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const (
+	defaultArticlesLimit = 50
+	maxArticlesLimit     = 500
+)
+
+type articleResponse struct {
+	ID        int64  `json:"id"`
+	SourceID  int64  `json:"source_id"`
+	Source    string `json:"source"`
+	Title     string `json:"title"`
+	Link      string `json:"link"`
+	Summary   string `json:"summary,omitempty"`
+	AISummary string `json:"ai_summary,omitempty"`
+	// AISummaryModel, AISummaryPromptHash and AISummaryGeneratedAt are set if AISummary is generated.
+	AISummaryModel       string     `json:"ai_summary_model,omitempty"`
+	AISummaryPromptHash  string     `json:"ai_summary_prompt_hash,omitempty"`
+	AISummaryGeneratedAt *time.Time `json:"ai_summary_generated_at,omitempty"`
+	Categories           []string   `json:"categories"`
+	Status               string     `json:"status"`
+	PublishedAt          time.Time  `json:"published_at"`
+	PostedAt             *time.Time `json:"posted_at,omitempty"`
+	SkippedAt            *time.Time `json:"skipped_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+func newArticleResponse(article model.Article) articleResponse {
+	status := model.ArticleStatusPending
+
+	switch {
+	case !article.PostedAt.IsZero():
+		status = model.ArticleStatusPosted
+	case !article.SkippedAt.IsZero():
+		status = model.ArticleStatusSkipped
+	}
+
+	categories := article.Categories
+	if categories == nil {
+		categories = []string{}
+	}
+
+	return articleResponse{
+		ID:                   article.ID,
+		SourceID:             article.SourceID,
+		Source:               article.SourceName,
+		Title:                article.Title,
+		Link:                 article.Link,
+		Summary:              article.Summary,
+		AISummary:            article.AISummary,
+		AISummaryModel:       article.AISummaryModel,
+		AISummaryPromptHash:  article.AISummaryPromptHash,
+		AISummaryGeneratedAt: timeOrNil(article.AISummaryGeneratedAt),
+		Categories:           categories,
+		Status:               status,
+		PublishedAt:          article.PublishedAt,
+		PostedAt:             timeOrNil(article.PostedAt),
+		SkippedAt:            timeOrNil(article.SkippedAt),
+		CreatedAt:            article.CreatedAt,
+	}
+}
+
+// listArticles returns articles, latest first. Query parameters: q searches titles and summaries,
+// source_id, status (pending, posted or skipped), limit and offset.
+func (h *Handler) listArticles(w http.ResponseWriter, r *http.Request) error {
+	params := r.URL.Query()
+
+	query := model.ArticleQuery{
+		Text:   params.Get("q"),
+		Status: params.Get("status"),
+		Limit:  defaultArticlesLimit,
+	}
+
+	switch query.Status {
+	case "", model.ArticleStatusPending, model.ArticleStatusPosted, model.ArticleStatusSkipped:
+	default:
+		return badRequest("status must be one of pending, posted, skipped")
+	}
+
+	for _, param := range []struct {
+		name  string
+		value *uint64
+	}{
+		{name: "limit", value: &query.Limit},
+		{name: "offset", value: &query.Offset},
+	} {
+		if raw := params.Get(param.name); raw != "" {
+			value, err := strconv.ParseUint(raw, 10, 64)
+			if err != nil {
+				return badRequest(param.name + " must be a non-negative integer")
+			}
+
+			*param.value = value
+		}
+	}
+
+	if query.Limit == 0 || query.Limit > maxArticlesLimit {
+		return badRequest("limit must be between 1 and " + strconv.Itoa(maxArticlesLimit))
+	}
+
+	if raw := params.Get("source_id"); raw != "" {
+		sourceID, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || sourceID <= 0 {
+			return badRequest("source_id must be a positive integer")
+		}
+
+		query.SourceID = sourceID
+	}
+
+	articles, err := h.articles.Articles(r.Context(), query)
+	if err != nil {
+		return err
+	}
+
+	resp := make([]articleResponse, 0, len(articles))
+	for _, article := range articles {
+		resp = append(resp, newArticleResponse(article))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+
+	return nil
+}
+
+func (h *Handler) getArticle(w http.ResponseWriter, r *http.Request, id int64) error {
+	return h.writeArticle(w, r, id)
+}
+
+// markAsPosted marks the article as posted to the channel from the request,
+// or to all channels if it's omitted, so the notifier doesn't post it.
+func (h *Handler) markAsPosted(w http.ResponseWriter, r *http.Request, id int64) error {
+	var req struct {
+		ChannelID int64 `json:"channel_id"`
+	}
+
+	if r.ContentLength != 0 {
+		if err := readJSON(r, &req); err != nil {
+			return err
+		}
+	}
+
+	if _, err := h.articles.ArticleByID(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := h.articles.MarkAsPosted(r.Context(), id, req.ChannelID); err != nil {
+		return err
+	}
+
+	return h.writeArticle(w, r, id)
+}
+
+// skipArticle marks the article as skipped, so it's not posted to any channel.
+func (h *Handler) skipArticle(w http.ResponseWriter, r *http.Request, id int64) error {
+	if _, err := h.articles.ArticleByID(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := h.articles.Skip(r.Context(), id); err != nil {
+		return err
+	}
+
+	return h.writeArticle(w, r, id)
+}
+
+// regenerateSummary generates the summary of the article anew, replacing the stored one.
+func (h *Handler) regenerateSummary(w http.ResponseWriter, r *http.Request, id int64) error {
+	if _, err := h.summaries.Regenerate(r.Context(), id); err != nil {
+		return err
+	}
+
+	return h.writeArticle(w, r, id)
+}
+
+func (h *Handler) writeArticle(w http.ResponseWriter, r *http.Request, id int64) error {
+	article, err := h.articles.ArticleByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusOK, newArticleResponse(*article))
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type sourceResponse struct {
+	ID            int64          `json:"id"`
+	Name          string         `json:"name"`
+	URL           string         `json:"url"`
+	Kind          string         `json:"kind"`
+	Priority      int            `json:"priority"`
+	FetchInterval string         `json:"fetch_interval,omitempty"`
+	AdaptiveFetch bool           `json:"adaptive_fetch"`
+	FetchTimeout  string         `json:"fetch_timeout,omitempty"`
+	AllowList     bool           `json:"allow_list"`
+	NextFetchAt   *time.Time     `json:"next_fetch_at,omitempty"`
+	Health        healthResponse `json:"health"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type healthResponse struct {
+	LastSuccessAt       *time.Time `json:"last_success_at,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastErrorAt         *time.Time `json:"last_error_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+}
+
+func newSourceResponse(source model.Source) sourceResponse {
+	resp := sourceResponse{
+		ID:            source.ID,
+		Name:          source.Name,
+		URL:           source.FeedURL,
+		Kind:          source.Kind,
+		Priority:      source.Priority,
+		AdaptiveFetch: source.AdaptiveFetch,
+		AllowList:     source.AllowList,
+		NextFetchAt:   timeOrNil(source.NextFetchAt),
+		Health: healthResponse{
+			LastSuccessAt:       timeOrNil(source.Health.LastSuccessAt),
+			LastError:           source.Health.LastError,
+			LastErrorAt:         timeOrNil(source.Health.LastErrorAt),
+			ConsecutiveFailures: source.Health.ConsecutiveFailures,
+			DisabledAt:          timeOrNil(source.Health.DisabledAt),
+		},
+		CreatedAt: source.CreatedAt,
+	}
+
+	if source.FetchInterval > 0 {
+		resp.FetchInterval = source.FetchInterval.String()
+	}
+
+	if source.FetchTimeout > 0 {
+		resp.FetchTimeout = source.FetchTimeout.String()
+	}
+
+	return resp
+}
+
+func (h *Handler) listSources(w http.ResponseWriter, r *http.Request) error {
+	sources, err := h.sources.Sources(r.Context())
+	if err != nil {
+		return err
+	}
+
+	resp := make([]sourceResponse, 0, len(sources))
+	for _, source := range sources {
+		resp = append(resp, newSourceResponse(source))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+
+	return nil
+}
+
+func (h *Handler) getSource(w http.ResponseWriter, r *http.Request, id int64) error {
+	source, err := h.sources.SourceByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, http.StatusOK, newSourceResponse(*source))
+
+	return nil
+}
+
+func (h *Handler) addSource(w http.ResponseWriter, r *http.Request) error {
+	var req struct {
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Kind     string `json:"kind"`
+		Priority int    `json:"priority"`
+	}
+
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	source := model.Source{
+		Name:     req.Name,
+		FeedURL:  req.URL,
+		Kind:     req.Kind,
+		Priority: req.Priority,
+	}
+
+	if source.Kind == "" {
+		source.Kind = model.SourceKindRSS
+	}
+
+	if err := h.validateSource(source); err != nil {
+		return err
+	}
+
+	id, err := h.sources.Add(r.Context(), source)
+	if err != nil {
+		return err
+	}
+
+	return h.writeSource(w, r, http.StatusCreated, id)
+}
+
+// updateSource updates the fields present in the request.
+func (h *Handler) updateSource(w http.ResponseWriter, r *http.Request, id int64) error {
+	var req struct {
+		Name      *string `json:"name"`
+		URL       *string `json:"url"`
+		Kind      *string `json:"kind"`
+		Priority  *int    `json:"priority"`
+		AllowList *bool   `json:"allow_list"`
+	}
+
+	if err := readJSON(r, &req); err != nil {
+		return err
+	}
+
+	source, err := h.sources.SourceByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	setIfPresent(&source.Name, req.Name)
+	setIfPresent(&source.FeedURL, req.URL)
+	setIfPresent(&source.Kind, req.Kind)
+	setIfPresent(&source.Priority, req.Priority)
+	setIfPresent(&source.AllowList, req.AllowList)
+
+	if err := h.validateSource(*source); err != nil {
+		return err
+	}
+
+	if err := h.sources.Update(r.Context(), *source); err != nil {
+		return err
+	}
+
+	return h.writeSource(w, r, http.StatusOK, id)
+}
+
+// enableSource enables the source disabled after too many failed fetches.
+func (h *Handler) enableSource(w http.ResponseWriter, r *http.Request, id int64) error {
+	if _, err := h.sources.SourceByID(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := h.sources.Enable(r.Context(), id); err != nil {
+		return err
+	}
+
+	return h.writeSource(w, r, http.StatusOK, id)
+}
+
+func (h *Handler) deleteSource(w http.ResponseWriter, r *http.Request, id int64) error {
+	if _, err := h.sources.SourceByID(r.Context(), id); err != nil {
+		return err
+	}
+
+	if err := h.sources.Delete(r.Context(), id); err != nil {
+		return err
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+
+	return nil
+}
+
+func (h *Handler) validateSource(source model.Source) error {
+	if strings.TrimSpace(source.Name) == "" {
+		return badRequest("name is required")
+	}
+
+	if strings.TrimSpace(source.FeedURL) == "" {
+		return badRequest("url is required")
+	}
+
+	if !h.kinds.Supports(source.Kind) {
+		return badRequest("unknown source kind, supported kinds: " + strings.Join(h.kinds.Kinds(), ", "))
+	}
+
+	return nil
+}
+
+func (h *Handler) writeSource(w http.ResponseWriter, r *http.Request, status int, id int64) error {
+	source, err := h.sources.SourceByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	writeJSON(w, status, newSourceResponse(*source))
+
+	return nil
+}
+
+func setIfPresent[T any](field *T, value *T) {
+	if value != nil {
+		*field = *value
+	}
+}
+
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
+}
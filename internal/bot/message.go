@@ -0,0 +1,61 @@
+package bot
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// maxMessageLength is the limit of Telegram message length.
+const maxMessageLength = 4096
+
+// sendBlocks sends the header followed by blocks separated with blank lines in MarkdownV2.
+// Blocks which don't fit into the message are sent in the next ones, a block is never split.
+func sendBlocks(bot *tgbotapi.BotAPI, chatID int64, header string, blocks []string) error {
+	for _, text := range splitMessage(header, blocks, maxMessageLength) {
+		reply := tgbotapi.NewMessage(chatID, text)
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitMessage joins the header and blocks into messages of up to maxLength runes.
+func splitMessage(header string, blocks []string, maxLength int) []string {
+	var (
+		messages []string
+		current  strings.Builder
+		length   = utf8.RuneCountInString(header)
+	)
+
+	current.WriteString(header)
+
+	for _, block := range blocks {
+		blockLength := utf8.RuneCountInString(block)
+
+		if length > 0 && length+len("\n\n")+blockLength > maxLength {
+			messages = append(messages, current.String())
+			current.Reset()
+			length = 0
+		}
+
+		if length > 0 {
+			current.WriteString("\n\n")
+			length += len("\n\n")
+		}
+
+		current.WriteString(block)
+		length += blockLength
+	}
+
+	if length > 0 {
+		messages = append(messages, current.String())
+	}
+
+	return messages
+}
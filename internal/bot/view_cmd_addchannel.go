@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type ChannelStorage interface {
+	Add(ctx context.Context, channel model.Channel) (int64, error)
+}
+
+type ChannelKinds interface {
+	Kinds() []string
+	Validate(channel model.Channel) error
+}
+
+func ViewCmdAddChannel(storage ChannelStorage, kinds ChannelKinds) botkit.ViewFunc {
+	type addChannelArgs struct {
+		Name         string `json:"name"`
+		Kind         string `json:"kind"`
+		ChatID       int64  `json:"chat_id"`
+		URL          string `json:"url"`
+		RoomID       string `json:"room_id"`
+		AccessToken  string `json:"access_token"`
+		PostInterval string `json:"post_interval"`
+		AllSources   bool   `json:"all_sources"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[addChannelArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		if args.Kind == "" {
+			args.Kind = model.ChannelKindTelegram
+		}
+
+		channel := model.Channel{
+			Kind:        args.Kind,
+			ChatID:      args.ChatID,
+			URL:         args.URL,
+			RoomID:      args.RoomID,
+			AccessToken: args.AccessToken,
+			Name:        args.Name,
+			AllSources:  args.AllSources,
+		}
+
+		if err := kinds.Validate(channel); err != nil {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+				"Некорректный канал: %s\\. Доступные типы: %s",
+				markup.EscapeForMarkdown(err.Error()),
+				markup.EscapeForMarkdown(strings.Join(kinds.Kinds(), ", ")),
+			))
+			reply.ParseMode = parseModeMarkdownV2
+
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if args.PostInterval != "" {
+			if channel.PostInterval, err = time.ParseDuration(args.PostInterval); err != nil {
+				return err
+			}
+		}
+
+		channelID, err := storage.Add(ctx, channel)
+		if err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+			"Канал добавлен с ID: `%d`\\. Используйте этот ID для подписки канала на источники\\.",
+			channelID,
+		))
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+type FilterRuleStorage interface {
+	Add(ctx context.Context, rule model.FilterRule) (int64, error)
+}
+
+type filterConditionArgs struct {
+	All   []filterConditionArgs `json:"all"`
+	Any   []filterConditionArgs `json:"any"`
+	Not   *filterConditionArgs  `json:"not"`
+	Field string                `json:"field"`
+	Op    string                `json:"op"`
+	Value string                `json:"value"`
+}
+
+func (c filterConditionArgs) toModel() model.FilterCondition {
+	condition := model.FilterCondition{
+		All:   lo.Map(c.All, func(c filterConditionArgs, _ int) model.FilterCondition { return c.toModel() }),
+		Any:   lo.Map(c.Any, func(c filterConditionArgs, _ int) model.FilterCondition { return c.toModel() }),
+		Field: c.Field,
+		Op:    c.Op,
+		Value: c.Value,
+	}
+
+	if c.Not != nil {
+		not := c.Not.toModel()
+		condition.Not = &not
+	}
+
+	return condition
+}
+
+func ViewCmdAddRule(storage FilterRuleStorage) botkit.ViewFunc {
+	type addRuleArgs struct {
+		Name      string              `json:"name"`
+		SourceID  int64               `json:"source_id"`
+		ChannelID int64               `json:"channel_id"`
+		Action    string              `json:"action"`
+		Condition filterConditionArgs `json:"condition"`
+		Position  int                 `json:"position"`
+		DryRun    bool                `json:"dry_run"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[addRuleArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		rule := model.FilterRule{
+			SourceID:  args.SourceID,
+			ChannelID: args.ChannelID,
+			Name:      args.Name,
+			Action:    args.Action,
+			Condition: args.Condition.toModel(),
+			Position:  args.Position,
+			DryRun:    args.DryRun,
+		}
+
+		if err := rules.Validate(rule); err != nil {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+				"Некорректное правило: %s",
+				markup.EscapeForMarkdown(err.Error()),
+			))
+			reply.ParseMode = parseModeMarkdownV2
+
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		ruleID, err := storage.Add(ctx, rule)
+		if err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+			"Правило добавлено с ID: `%d`\\. Используйте этот ID для удаления правила\\.",
+			ruleID,
+		))
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -3,10 +3,12 @@ package bot
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 
 	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
 	"github.com/defer-panic/news-feed-bot/internal/model"
 )
 
@@ -14,10 +16,16 @@ type SourceStorage interface {
 	Add(ctx context.Context, source model.Source) (int64, error)
 }
 
-func ViewCmdAddSource(storage SourceStorage) botkit.ViewFunc {
+type SourceKinds interface {
+	Supports(kind string) bool
+	Kinds() []string
+}
+
+func ViewCmdAddSource(storage SourceStorage, kinds SourceKinds) botkit.ViewFunc {
 	type addSourceArgs struct {
 		Name     string `json:"name"`
 		URL      string `json:"url"`
+		Kind     string `json:"kind"`
 		Priority int    `json:"priority"`
 	}
 
@@ -27,9 +35,29 @@ func ViewCmdAddSource(storage SourceStorage) botkit.ViewFunc {
 			return err
 		}
 
+		if args.Kind == "" {
+			args.Kind = model.SourceKindRSS
+		}
+
+		if !kinds.Supports(args.Kind) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+				"Неизвестный тип источника `%s`\\. Доступные типы: %s",
+				markup.EscapeForMarkdown(args.Kind),
+				markup.EscapeForMarkdown(strings.Join(kinds.Kinds(), ", ")),
+			))
+			reply.ParseMode = parseModeMarkdownV2
+
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
 		source := model.Source{
 			Name:     args.Name,
 			FeedURL:  args.URL,
+			Kind:     args.Kind,
 			Priority: args.Priority,
 		}
 
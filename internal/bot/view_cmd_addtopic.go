@@ -0,0 +1,73 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+type TopicStorage interface {
+	Add(ctx context.Context, topic model.Topic) (int64, error)
+}
+
+func ViewCmdAddTopic(storage TopicStorage) botkit.ViewFunc {
+	type addTopicArgs struct {
+		Name       string   `json:"name"`
+		SourceID   int64    `json:"source_id"`
+		Keywords   []string `json:"keywords"`
+		Categories []string `json:"categories"`
+		Pattern    string   `json:"pattern"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[addTopicArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		topic := model.Topic{
+			SourceID:   args.SourceID,
+			Name:       args.Name,
+			Keywords:   args.Keywords,
+			Categories: args.Categories,
+			Pattern:    args.Pattern,
+		}
+
+		if err := rules.ValidateTopic(topic); err != nil {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+				"Некорректная тема: %s",
+				markup.EscapeForMarkdown(err.Error()),
+			))
+			reply.ParseMode = parseModeMarkdownV2
+
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		topicID, err := storage.Add(ctx, topic)
+		if err != nil {
+			return err
+		}
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+			"Тема добавлена с ID: `%d`\\. Используйте этот ID для удаления темы\\.",
+			topicID,
+		))
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
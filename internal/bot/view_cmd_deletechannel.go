@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/storage"
+)
+
+type ChannelDeleter interface {
+	Delete(ctx context.Context, channelID int64) error
+}
+
+func ViewCmdDeleteChannel(deleter ChannelDeleter) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		idStr := update.Message.CommandArguments()
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Использование: /deletechannel <id канала>")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		err = deleter.Delete(ctx, id)
+		if errors.Is(err, storage.ErrDefaultChannel) || errors.Is(err, sql.ErrNoRows) {
+			replyText := "Канал не найден"
+			if errors.Is(err, storage.ErrDefaultChannel) {
+				replyText = "Канал по умолчанию нельзя удалить"
+			}
+
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, replyText)
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал успешно удален")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
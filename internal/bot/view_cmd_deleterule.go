@@ -0,0 +1,36 @@
+package bot
+
+import (
+	"context"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type FilterRuleDeleter interface {
+	Delete(ctx context.Context, ruleID int64) error
+}
+
+func ViewCmdDeleteRule(deleter FilterRuleDeleter) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		idStr := update.Message.CommandArguments()
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if err := deleter.Delete(ctx, id); err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Правило успешно удалено")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -42,11 +42,51 @@ func ViewCmdGetSource(provider SourceProvider) botkit.ViewFunc {
 }
 
 func formatSource(source model.Source) string {
-	return fmt.Sprintf(
-		"🌐 *%s*\nID: `%d`\nURL фида: %s\nПриоритет: %d",
+	text := fmt.Sprintf(
+		"🌐 *%s*\nID: `%d`\nURL фида: %s\nТип: `%s`\nПриоритет: %d",
 		markup.EscapeForMarkdown(source.Name),
 		source.ID,
 		markup.EscapeForMarkdown(source.FeedURL),
+		markup.EscapeForMarkdown(source.Kind),
 		source.Priority,
 	)
+
+	if source.FetchInterval > 0 || source.AdaptiveFetch {
+		schedule := "по умолчанию"
+		if source.FetchInterval > 0 {
+			schedule = source.FetchInterval.String()
+		}
+
+		if source.AdaptiveFetch {
+			schedule += ", адаптивный"
+		}
+
+		text += fmt.Sprintf("\nИнтервал: %s", markup.EscapeForMarkdown(schedule))
+	}
+
+	if !source.NextFetchAt.IsZero() {
+		text += fmt.Sprintf(
+			"\nСледующая загрузка: %s",
+			markup.EscapeForMarkdown(source.NextFetchAt.Format("2006-01-02 15:04 MST")),
+		)
+	}
+
+	if source.AllowList {
+		text += "\nБелый список: сохраняются только статьи по темам"
+	}
+
+	if source.Health.Disabled() {
+		text += "\n⛔️ Отключен из\\-за ошибок, см\\. /sourcehealth"
+	}
+
+	if source.Selectors != nil {
+		text += fmt.Sprintf(
+			"\nСелекторы: `%s` / `%s` / `%s`",
+			markup.EscapeForMarkdown(source.Selectors.Item),
+			markup.EscapeForMarkdown(source.Selectors.Title),
+			markup.EscapeForMarkdown(source.Selectors.Link),
+		)
+	}
+
+	return text
 }
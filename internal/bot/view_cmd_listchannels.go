@@ -0,0 +1,95 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type ChannelLister interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+}
+
+func ViewCmdListChannels(lister ChannelLister) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		channels, err := lister.Channels(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			channelInfos = lo.Map(channels, func(channel model.Channel, _ int) string { return formatChannel(channel) })
+			msgText      = fmt.Sprintf(
+				"Список каналов \\(всего %d\\):\n\n%s",
+				len(channels),
+				strings.Join(channelInfos, "\n\n"),
+			)
+		)
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func formatChannel(channel model.Channel) string {
+	interval := "по умолчанию"
+	if channel.PostInterval > 0 {
+		interval = markup.EscapeForMarkdown(channel.PostInterval.String())
+	}
+
+	sources := "все"
+	if !channel.AllSources {
+		sources = lo.Ternary(len(channel.SourceIDs) == 0, "нет", strings.Join(lo.Map(
+			channel.SourceIDs,
+			func(id int64, _ int) string { return fmt.Sprintf("`%d`", id) },
+		), ", "))
+	}
+
+	// webhook URLs and tokens are secrets, so only Telegram chat is shown
+	target := markup.EscapeForMarkdown(channel.Kind)
+	if channel.Kind == model.ChannelKindTelegram {
+		target = fmt.Sprintf("чат `%d`", channel.ChatID)
+	}
+
+	text := fmt.Sprintf(
+		"📢 *%s*\nID: `%d`\nКуда: %s\nИнтервал публикации: %s\nИсточники: %s\nПравил: %d",
+		markup.EscapeForMarkdown(channel.Name),
+		channel.ID,
+		target,
+		interval,
+		sources,
+		len(channel.Rules),
+	)
+
+	if channel.Digest.Schedule != "" {
+		text += fmt.Sprintf(
+			"\nДайджест: `%s` \\(%s\\), статей: %d",
+			escapeForCode(channel.Digest.Schedule),
+			markup.EscapeForMarkdown(channel.Digest.Timezone),
+			channel.Digest.Size,
+		)
+
+		if channel.Digest.GroupBy != "" {
+			text += fmt.Sprintf(", группировка: %s", markup.EscapeForMarkdown(channel.Digest.GroupBy))
+		}
+	}
+
+	if channel.Default {
+		text += "\n⭐️ Канал из конфигурации"
+	}
+
+	return text
+}
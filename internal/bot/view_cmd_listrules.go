@@ -0,0 +1,99 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type FilterRuleLister interface {
+	FilterRules(ctx context.Context) ([]model.FilterRule, error)
+}
+
+func ViewCmdListRules(lister FilterRuleLister) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		filterRules, err := lister.FilterRules(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			ruleInfos = lo.Map(filterRules, func(rule model.FilterRule, _ int) string { return formatFilterRule(rule) })
+			msgText   = fmt.Sprintf(
+				"Список правил фильтрации \\(всего %d\\):\n\n%s",
+				len(filterRules),
+				strings.Join(ruleInfos, "\n\n"),
+			)
+		)
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func formatFilterRule(rule model.FilterRule) string {
+	scope := "все источники"
+	if rule.SourceID != 0 {
+		scope = fmt.Sprintf("источник `%d`", rule.SourceID)
+	}
+
+	if rule.ChannelID != 0 {
+		scope += fmt.Sprintf(", отбирает статьи для канала `%d`", rule.ChannelID)
+	}
+
+	text := fmt.Sprintf(
+		"📏 *%s*\nID: `%d`\nДействие: `%s`\nПрименяется к: %s\nПозиция: %s\nУсловие: `%s`",
+		markup.EscapeForMarkdown(rule.Name),
+		rule.ID,
+		rule.Action,
+		scope,
+		markup.EscapeForMarkdown(strconv.Itoa(rule.Position)),
+		escapeForCode(formatFilterCondition(rule.Condition)),
+	)
+
+	if rule.DryRun {
+		text += "\n🧪 Пробный режим: правило только сообщает о совпадениях"
+	}
+
+	return text
+}
+
+// formatFilterCondition renders condition in the same terms it's configured with,
+// e.g. any(title contains "go", not(domain equals "example.com")).
+func formatFilterCondition(condition model.FilterCondition) string {
+	join := func(conditions []model.FilterCondition) string {
+		return strings.Join(lo.Map(conditions, func(c model.FilterCondition, _ int) string {
+			return formatFilterCondition(c)
+		}), ", ")
+	}
+
+	switch {
+	case len(condition.All) > 0:
+		return fmt.Sprintf("all(%s)", join(condition.All))
+	case len(condition.Any) > 0:
+		return fmt.Sprintf("any(%s)", join(condition.Any))
+	case condition.Not != nil:
+		return fmt.Sprintf("not(%s)", formatFilterCondition(*condition.Not))
+	default:
+		return fmt.Sprintf("%s %s %q", condition.Field, condition.Op, condition.Value)
+	}
+}
+
+// escapeForCode escapes text to be put into inline code block of MarkdownV2 message.
+func escapeForCode(text string) string {
+	return strings.NewReplacer("\\", "\\\\", "`", "\\`").Replace(text)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"strings"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"github.com/samber/lo"
@@ -28,22 +27,11 @@ func ViewCmdListSource(lister SourceLister) botkit.ViewFunc {
 			return sources[i].Priority > sources[j].Priority
 		})
 
-		var (
-			sourceInfos = lo.Map(sources, func(source model.Source, _ int) string { return formatSource(source) })
-			msgText     = fmt.Sprintf(
-				"Список источников \\(всего %d\\):\n\n%s",
-				len(sources),
-				strings.Join(sourceInfos, "\n\n"),
-			)
+		return sendBlocks(
+			bot,
+			update.Message.Chat.ID,
+			fmt.Sprintf("Список источников \\(всего %d\\):", len(sources)),
+			lo.Map(sources, func(source model.Source, _ int) string { return formatSource(source) }),
 		)
-
-		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
-		reply.ParseMode = parseModeMarkdownV2
-
-		if _, err := bot.Send(reply); err != nil {
-			return err
-		}
-
-		return nil
 	}
 }
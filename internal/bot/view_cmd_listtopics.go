@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type TopicLister interface {
+	Topics(ctx context.Context) ([]model.Topic, error)
+}
+
+func ViewCmdListTopics(lister TopicLister) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		topics, err := lister.Topics(ctx)
+		if err != nil {
+			return err
+		}
+
+		var (
+			topicInfos = lo.Map(topics, func(topic model.Topic, _ int) string { return formatTopic(topic) })
+			msgText    = fmt.Sprintf(
+				"Список тем \\(всего %d\\):\n\n%s",
+				len(topics),
+				strings.Join(topicInfos, "\n\n"),
+			)
+		)
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func formatTopic(topic model.Topic) string {
+	scope := "все источники"
+	if topic.SourceID != 0 {
+		scope = fmt.Sprintf("источник `%d`", topic.SourceID)
+	}
+
+	text := fmt.Sprintf(
+		"🏷 *%s*\nID: `%d`\nПрименяется к: %s\nСохранено статей по теме: %d",
+		markup.EscapeForMarkdown(topic.Name),
+		topic.ID,
+		scope,
+		topic.Admitted,
+	)
+
+	if len(topic.Keywords) > 0 {
+		text += fmt.Sprintf("\nКлючевые слова: %s", markup.EscapeForMarkdown(strings.Join(topic.Keywords, ", ")))
+	}
+
+	if len(topic.Categories) > 0 {
+		text += fmt.Sprintf("\nКатегории: %s", markup.EscapeForMarkdown(strings.Join(topic.Categories, ", ")))
+	}
+
+	if topic.Pattern != "" {
+		text += fmt.Sprintf("\nРегулярное выражение: `%s`", escapeForCode(topic.Pattern))
+	}
+
+	return text
+}
@@ -0,0 +1,54 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type SummaryRegenerator interface {
+	Regenerate(ctx context.Context, articleID int64) (model.Summary, error)
+}
+
+func ViewCmdRegenSummary(regenerator SummaryRegenerator) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		idStr := update.Message.CommandArguments()
+
+		id, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Использование: /regensummary <id статьи>")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		summary, err := regenerator.Regenerate(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Статья не найдена")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Саммари обновлено ("+summary.Model+"):\n\n"+summary.Text)
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type AllowListSetter interface {
+	SetAllowList(ctx context.Context, sourceID int64, allowList bool) error
+}
+
+func ViewCmdSetAllowList(setter AllowListSetter) botkit.ViewFunc {
+	type setAllowListArgs struct {
+		SourceID int64 `json:"source_id"`
+		Enabled  bool  `json:"enabled"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setAllowListArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		if err := setter.SetAllowList(ctx, args.SourceID, args.Enabled); err != nil {
+			return err
+		}
+
+		msgText := "Режим белого списка для источника выключен"
+		if args.Enabled {
+			msgText = "Режим белого списка для источника включен: сохраняются только статьи по темам"
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
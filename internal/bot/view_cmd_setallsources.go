@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type AllSourcesSetter interface {
+	SetAllSources(ctx context.Context, channelID int64, allSources bool) error
+}
+
+func ViewCmdSetAllSources(setter AllSourcesSetter) botkit.ViewFunc {
+	type setAllSourcesArgs struct {
+		ChannelID int64 `json:"channel_id"`
+		Enabled   bool  `json:"enabled"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setAllSourcesArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		err = setter.SetAllSources(ctx, args.ChannelID, args.Enabled)
+		if errors.Is(err, sql.ErrNoRows) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал не найден")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msgText := "Канал получает статьи только из источников, на которые подписан"
+		if args.Enabled {
+			msgText = "Канал получает статьи из всех источников"
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,98 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/notifier"
+)
+
+type DigestSetter interface {
+	SetDigest(ctx context.Context, channelID int64, digest model.Digest) error
+}
+
+func ViewCmdSetDigest(setter DigestSetter) botkit.ViewFunc {
+	const (
+		defaultTimezone = "UTC"
+		defaultSize     = 10
+	)
+
+	type setDigestArgs struct {
+		ChannelID int64  `json:"channel_id"`
+		Schedule  string `json:"schedule"`
+		Timezone  string `json:"timezone"`
+		Size      int    `json:"size"`
+		GroupBy   string `json:"group_by"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setDigestArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		digest := model.Digest{
+			Schedule: args.Schedule,
+			Timezone: args.Timezone,
+			Size:     args.Size,
+			GroupBy:  args.GroupBy,
+		}
+
+		if digest.Timezone == "" {
+			digest.Timezone = defaultTimezone
+		}
+
+		if digest.Size == 0 {
+			digest.Size = defaultSize
+		}
+
+		if digest.Schedule != "" {
+			if err := notifier.ValidateDigest(digest); err != nil {
+				reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+					"Некорректные настройки дайджеста: %s",
+					markup.EscapeForMarkdown(err.Error()),
+				))
+				reply.ParseMode = parseModeMarkdownV2
+
+				if _, err := bot.Send(reply); err != nil {
+					return err
+				}
+
+				return nil
+			}
+		}
+
+		err = setter.SetDigest(ctx, args.ChannelID, digest)
+		if errors.Is(err, sql.ErrNoRows) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал не найден")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msgText := "Режим дайджеста выключен, статьи публикуются по одной"
+		if digest.Schedule != "" {
+			msgText = "Режим дайджеста включен"
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,43 @@
+package bot
+
+import (
+	"context"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type FilterRuleDryRunSetter interface {
+	SetDryRun(ctx context.Context, ruleID int64, dryRun bool) error
+}
+
+func ViewCmdSetRuleDryRun(setter FilterRuleDryRunSetter) botkit.ViewFunc {
+	type setRuleDryRunArgs struct {
+		RuleID int64 `json:"rule_id"`
+		DryRun bool  `json:"dry_run"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setRuleDryRunArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		if err := setter.SetDryRun(ctx, args.RuleID, args.DryRun); err != nil {
+			return err
+		}
+
+		msgText := "Правило включено"
+		if args.DryRun {
+			msgText = "Правило переведено в пробный режим"
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, msgText)
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type ScheduleSetter interface {
+	SetSchedule(ctx context.Context, sourceID int64, interval time.Duration, adaptive bool) error
+}
+
+func ViewCmdSetSchedule(setter ScheduleSetter) botkit.ViewFunc {
+	type setScheduleArgs struct {
+		SourceID int64  `json:"source_id"`
+		Interval string `json:"interval"`
+		Adaptive bool   `json:"adaptive"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setScheduleArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		var interval time.Duration
+
+		if args.Interval != "" {
+			interval, err = time.ParseDuration(args.Interval)
+			if err != nil || interval < time.Second {
+				// empty interval resets it to the default one, so zero must not be stored silently
+				reply := tgbotapi.NewMessage(
+					update.Message.Chat.ID,
+					"Некорректный интервал: он должен быть не меньше 1s, пустой интервал сбрасывает расписание",
+				)
+
+				if _, err := bot.Send(reply); err != nil {
+					return err
+				}
+
+				return nil
+			}
+		}
+
+		if err := setter.SetSchedule(ctx, args.SourceID, interval, args.Adaptive); err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Расписание успешно обновлено")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,70 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+type SelectorsSetter interface {
+	SetScrapeSelectors(ctx context.Context, sourceID int64, selectors model.ScrapeSelectors) error
+}
+
+func ViewCmdSetSelectors(setter SelectorsSetter) botkit.ViewFunc {
+	type setSelectorsArgs struct {
+		SourceID   int64  `json:"source_id"`
+		Item       string `json:"item"`
+		Title      string `json:"title"`
+		Link       string `json:"link"`
+		Date       string `json:"date"`
+		DateLayout string `json:"date_layout"`
+		Summary    string `json:"summary"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setSelectorsArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		selectors := model.ScrapeSelectors{
+			Item:       args.Item,
+			Title:      args.Title,
+			Link:       args.Link,
+			Date:       args.Date,
+			DateLayout: args.DateLayout,
+			Summary:    args.Summary,
+		}
+
+		if err := source.ValidateScrapeSelectors(selectors); err != nil {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, fmt.Sprintf(
+				"Некорректные селекторы: %s",
+				markup.EscapeForMarkdown(err.Error()),
+			))
+			reply.ParseMode = parseModeMarkdownV2
+
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err := setter.SetScrapeSelectors(ctx, args.SourceID, selectors); err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Селекторы успешно обновлены")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
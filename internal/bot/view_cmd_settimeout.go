@@ -0,0 +1,58 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type TimeoutSetter interface {
+	SetFetchTimeout(ctx context.Context, sourceID int64, timeout time.Duration) error
+}
+
+func ViewCmdSetTimeout(setter TimeoutSetter) botkit.ViewFunc {
+	type setTimeoutArgs struct {
+		SourceID int64  `json:"source_id"`
+		Timeout  string `json:"timeout"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[setTimeoutArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		var timeout time.Duration
+
+		if args.Timeout != "" {
+			timeout, err = time.ParseDuration(args.Timeout)
+			if err != nil || timeout < time.Second {
+				// empty timeout resets it to the default one, so zero must not be stored silently
+				reply := tgbotapi.NewMessage(
+					update.Message.Chat.ID,
+					"Некорректный таймаут: он должен быть не меньше 1s, пустой таймаут сбрасывает его к значению по умолчанию",
+				)
+
+				if _, err := bot.Send(reply); err != nil {
+					return err
+				}
+
+				return nil
+			}
+		}
+
+		if err := setter.SetFetchTimeout(ctx, args.SourceID, timeout); err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Таймаут успешно обновлен")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const maxErrorLength = 300
+
+func ViewCmdSourceHealth(lister SourceLister) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		sources, err := lister.Sources(ctx)
+		if err != nil {
+			return err
+		}
+
+		broken := lo.Filter(sources, func(source model.Source, _ int) bool {
+			return source.Health.ConsecutiveFailures > 0 || source.Health.Disabled()
+		})
+
+		if len(broken) == 0 {
+			if _, err := bot.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Все источники работают нормально")); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		sort.SliceStable(broken, func(i, j int) bool {
+			return broken[i].Health.ConsecutiveFailures > broken[j].Health.ConsecutiveFailures
+		})
+
+		// there may be too many sources for a single message
+		return sendBlocks(
+			bot,
+			update.Message.Chat.ID,
+			fmt.Sprintf("Проблемные источники \\(всего %d\\):", len(broken)),
+			lo.Map(broken, func(source model.Source, _ int) string { return formatSourceHealth(source) }),
+		)
+	}
+}
+
+func formatSourceHealth(source model.Source) string {
+	var (
+		health = source.Health
+		status = "⚠️"
+	)
+
+	if health.Disabled() {
+		status = "⛔️"
+	}
+
+	lastError := health.LastError
+	if len([]rune(lastError)) > maxErrorLength {
+		lastError = string([]rune(lastError)[:maxErrorLength]) + "…"
+	}
+
+	text := fmt.Sprintf(
+		"%s *%s*\nID: `%d`\nОшибок подряд: %d\nПоследняя ошибка: %s\n",
+		status,
+		markup.EscapeForMarkdown(source.Name),
+		source.ID,
+		health.ConsecutiveFailures,
+		markup.EscapeForMarkdown(health.LastErrorAt.Format("2006-01-02 15:04 MST")),
+	)
+
+	if !health.LastSuccessAt.IsZero() {
+		text += fmt.Sprintf(
+			"Последняя успешная загрузка: %s\n",
+			markup.EscapeForMarkdown(health.LastSuccessAt.Format("2006-01-02 15:04 MST")),
+		)
+	}
+
+	if health.Disabled() {
+		text += fmt.Sprintf(
+			"Отключен: %s\n",
+			markup.EscapeForMarkdown(health.DisabledAt.Format("2006-01-02 15:04 MST")),
+		)
+	}
+
+	return text + fmt.Sprintf("```\n%s\n```", markup.EscapeForMarkdown(lastError))
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type channelSourceArgs struct {
+	ChannelID int64 `json:"channel_id"`
+	SourceID  int64 `json:"source_id"`
+}
+
+type ChannelSubscriber interface {
+	Subscribe(ctx context.Context, channelID, sourceID int64) error
+}
+
+func ViewCmdSubscribe(subscriber ChannelSubscriber) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[channelSourceArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		err = subscriber.Subscribe(ctx, args.ChannelID, args.SourceID)
+		if errors.Is(err, sql.ErrNoRows) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал или источник не найден")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал подписан на источник")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+// ViewCmdTestRules checks the given item against filter rules without storing anything
+// and reports which rule decided its fate. If channel_id is given, rules of the channel are checked instead.
+func ViewCmdTestRules(lister FilterRuleLister, filterKeywords []string) botkit.ViewFunc {
+	type testRulesArgs struct {
+		SourceID   int64    `json:"source_id"`
+		ChannelID  int64    `json:"channel_id"`
+		Title      string   `json:"title"`
+		Summary    string   `json:"summary"`
+		Link       string   `json:"link"`
+		Categories []string `json:"categories"`
+	}
+
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[testRulesArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		filterRules, err := lister.FilterRules(ctx)
+		if err != nil {
+			return err
+		}
+
+		filterRules = lo.Filter(filterRules, func(rule model.FilterRule, _ int) bool {
+			return rule.ChannelID == args.ChannelID
+		})
+
+		// invalid rules are ignored by fetcher and notifier as well, so they don't affect the result
+		ruleSet, _ := rules.Build(filterRules, lo.Ternary(args.ChannelID == 0, filterKeywords, nil))
+
+		decision := ruleSet.Evaluate(args.SourceID, model.Item{
+			Title:      args.Title,
+			Summary:    args.Summary,
+			Link:       args.Link,
+			Categories: args.Categories,
+		})
+
+		reply := tgbotapi.NewMessage(update.Message.Chat.ID, formatRulesDecision(decision, args.ChannelID != 0))
+		reply.ParseMode = parseModeMarkdownV2
+
+		if _, err := bot.Send(reply); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
+
+func formatRulesDecision(decision rules.Decision, channel bool) string {
+	var (
+		text     string
+		admitted = lo.Ternary(channel, "опубликована в канале", "сохранена")
+	)
+
+	switch {
+	case decision.Rule == nil && channel:
+		text = "Ни одно правило не сработало, статья будет опубликована, если канал подписан на источник"
+	case decision.Rule == nil:
+		text = "Ни одно правило не сработало, статья будет сохранена"
+	case decision.Skip():
+		text = fmt.Sprintf("❌ Статья будет пропущена по правилу *%s*", formatRuleRef(*decision.Rule))
+	default:
+		text = fmt.Sprintf("✅ Статья будет %s по правилу *%s*", admitted, formatRuleRef(*decision.Rule))
+	}
+
+	if len(decision.DryRun) == 0 {
+		return text
+	}
+
+	dryRun := make([]string, 0, len(decision.DryRun))
+	for _, rule := range decision.DryRun {
+		dryRun = append(dryRun, fmt.Sprintf("• %s: `%s`", formatRuleRef(rule), rule.Action))
+	}
+
+	return fmt.Sprintf("%s\n\n🧪 Правила в пробном режиме:\n%s", text, strings.Join(dryRun, "\n"))
+}
+
+func formatRuleRef(rule model.FilterRule) string {
+	if rule.ID == 0 {
+		return markup.EscapeForMarkdown(rule.Name)
+	}
+
+	return fmt.Sprintf("%s \\(\\#%d\\)", markup.EscapeForMarkdown(rule.Name), rule.ID)
+}
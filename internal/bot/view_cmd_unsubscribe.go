@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit"
+)
+
+type ChannelUnsubscriber interface {
+	Unsubscribe(ctx context.Context, channelID, sourceID int64) error
+}
+
+func ViewCmdUnsubscribe(unsubscriber ChannelUnsubscriber) botkit.ViewFunc {
+	return func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error {
+		args, err := botkit.ParseJSON[channelSourceArgs](update.Message.CommandArguments())
+		if err != nil {
+			return err
+		}
+
+		err = unsubscriber.Unsubscribe(ctx, args.ChannelID, args.SourceID)
+		if errors.Is(err, sql.ErrNoRows) {
+			reply := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал не подписан на источник")
+			if _, err := bot.Send(reply); err != nil {
+				return err
+			}
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msg := tgbotapi.NewMessage(update.Message.Chat.ID, "Канал отписан от источника")
+		if _, err := bot.Send(msg); err != nil {
+			return err
+		}
+
+		return nil
+	}
+}
@@ -2,11 +2,14 @@ package botkit
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"runtime/debug"
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/logging"
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
 )
 
 type Bot struct {
@@ -47,7 +50,7 @@ func (b *Bot) Run(ctx context.Context) error {
 func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 	defer func() {
 		if p := recover(); p != nil {
-			log.Printf("[ERROR] panic recovered: %v\n%s", p, string(debug.Stack()))
+			slog.ErrorContext(ctx, "panic recovered", "panic", p, "stack", string(debug.Stack()))
 		}
 	}()
 
@@ -70,13 +73,26 @@ func (b *Bot) handleUpdate(ctx context.Context, update tgbotapi.Update) {
 
 	view = cmdView
 
+	ctx = logging.With(ctx, "command", cmd, "chat_id", update.Message.Chat.ID)
+	if update.Message.From != nil {
+		// messages in channels have no sender
+		ctx = logging.With(ctx, "user_id", update.Message.From.ID)
+	}
+
+	metrics.BotCommands.WithLabelValues(cmd).Inc()
+
 	if err := view(ctx, b.api, update); err != nil {
-		log.Printf("[ERROR] failed to execute view: %v", err)
+		slog.ErrorContext(ctx, "failed to execute view", "error", err)
+		metrics.BotCommandErrors.WithLabelValues(cmd).Inc()
 
 		if _, err := b.api.Send(tgbotapi.NewMessage(update.Message.Chat.ID, "Internal error")); err != nil {
-			log.Printf("[ERROR] failed to send error message: %v", err)
+			slog.ErrorContext(ctx, "failed to send error message", "error", err)
 		}
+
+		return
 	}
+
+	slog.DebugContext(ctx, "command is executed")
 }
 
 type ViewFunc func(ctx context.Context, bot *tgbotapi.BotAPI, update tgbotapi.Update) error
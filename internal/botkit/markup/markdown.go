@@ -41,8 +41,20 @@ var (
 		"!",
 		"\\!",
 	)
+
+	linkReplacer = strings.NewReplacer(
+		"\\",
+		"\\\\",
+		")",
+		"\\)",
+	)
 )
 
 func EscapeForMarkdown(src string) string {
 	return replacer.Replace(src)
 }
+
+// EscapeForLink escapes URL of inline link, e.g. [text](url).
+func EscapeForLink(url string) string {
+	return linkReplacer.Replace(url)
+}
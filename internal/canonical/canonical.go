@@ -0,0 +1,119 @@
+// Package canonical normalizes article links, so the same article is recognized
+// regardless of tracking parameters, scheme, "www." prefix and other cosmetic differences.
+package canonical
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// trackingParams are query parameters which never change page content:
+// tracking data and switches to AMP version of the page.
+var trackingParams = map[string]struct{}{
+	"fbclid":     {},
+	"gclid":      {},
+	"dclid":      {},
+	"yclid":      {},
+	"msclkid":    {},
+	"igshid":     {},
+	"mc_cid":     {},
+	"mc_eid":     {},
+	"_ga":        {},
+	"_gl":        {},
+	"_hsenc":     {},
+	"_hsmi":      {},
+	"mkt_tok":    {},
+	"ref_src":    {},
+	"ref_url":    {},
+	"amp":        {},
+	"outputtype": {},
+}
+
+// trackingParamPrefixes are prefixes of tracking parameters families.
+var trackingParamPrefixes = []string{"utm_", "oly_", "pk_", "hmb_"}
+
+// URL returns canonical form of the link: scheme is https, host is lowercase without "www." and default port,
+// tracking parameters and fragment are removed, the rest of query parameters are sorted
+// and trailing slash is trimmed. Links which can't be parsed are returned as is.
+func URL(link string) string {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || u.Host == "" {
+		return link
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if scheme != "http" && scheme != "https" {
+		return link
+	}
+
+	host := strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+	if port := u.Port(); port != "" && port != "80" && port != "443" {
+		host += ":" + port
+	}
+
+	u.Scheme = "https"
+	u.Host = host
+	u.User = nil
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.RawQuery = cleanQuery(u.Query()).Encode()
+
+	if u.Path != "" && u.Path != "/" {
+		u.Path = strings.TrimSuffix(path.Clean(u.Path), "/")
+		u.RawPath = ""
+	} else {
+		u.Path = ""
+	}
+
+	return u.String()
+}
+
+// IsAMP reports whether the link looks like an AMP version of a page.
+func IsAMP(link string) bool {
+	u, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if strings.HasPrefix(host, "amp.") || strings.HasSuffix(host, ".cdn.ampproject.org") {
+		return true
+	}
+
+	for _, segment := range strings.Split(strings.ToLower(u.Path), "/") {
+		if segment == "amp" || strings.HasSuffix(segment, ".amp") || strings.HasSuffix(segment, ".amp.html") {
+			return true
+		}
+	}
+
+	query := u.Query()
+
+	return query.Has("amp") || strings.EqualFold(query.Get("outputType"), "amp")
+}
+
+func cleanQuery(query url.Values) url.Values {
+	for param := range query {
+		if isTrackingParam(param) {
+			query.Del(param)
+		}
+	}
+
+	return query
+}
+
+func isTrackingParam(param string) bool {
+	param = strings.ToLower(param)
+
+	if _, ok := trackingParams[param]; ok {
+		return true
+	}
+
+	for _, prefix := range trackingParamPrefixes {
+		if strings.HasPrefix(param, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,90 @@
+package canonical_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/defer-panic/news-feed-bot/internal/canonical"
+)
+
+func TestURL(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected string
+	}{
+		{
+			link:     "https://example.com/post",
+			expected: "https://example.com/post",
+		},
+		{
+			link:     "http://WWW.Example.com:80/post/?utm_source=rss&utm_medium=feed#comments",
+			expected: "https://example.com/post",
+		},
+		{
+			link:     "https://example.com/post?page=2&fbclid=abc&id=1",
+			expected: "https://example.com/post?id=1&page=2",
+		},
+		{
+			link:     "https://example.com/news/post?amp=1",
+			expected: "https://example.com/news/post",
+		},
+		{
+			link:     "https://example.com:8443/a/./b/",
+			expected: "https://example.com:8443/a/b",
+		},
+		{
+			link:     "https://example.com/",
+			expected: "https://example.com",
+		},
+		{
+			link:     "mailto:gopher@example.com",
+			expected: "mailto:gopher@example.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, tt.expected, canonical.URL(tt.link))
+		})
+	}
+}
+
+func TestIsAMP(t *testing.T) {
+	assert.True(t, canonical.IsAMP("https://amp.example.com/post"))
+	assert.True(t, canonical.IsAMP("https://example.com/news/post/amp"))
+	assert.True(t, canonical.IsAMP("https://example.com/amp/news/post"))
+	assert.True(t, canonical.IsAMP("https://example.com/news/post.amp.html"))
+	assert.True(t, canonical.IsAMP("https://example.com/news/post?outputType=amp"))
+	assert.False(t, canonical.IsAMP("https://example.com/news/example-post"))
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(`<html><head>
+			<title>Post</title>
+			<link rel="canonical" href="/news/post/?utm_source=amp">
+		</head><body></body></html>`))
+	}))
+	defer server.Close()
+
+	var (
+		resolver = canonical.NewResolver(nil)
+		expected = canonical.URL(server.URL + "/news/post")
+	)
+
+	assert.Equal(t, expected, resolver.Resolve(context.Background(), server.URL+"/news/post/amp"))
+	assert.Equal(t, expected, resolver.Resolve(context.Background(), server.URL+"/news/post/amp"))
+	assert.EqualValues(t, 1, requests.Load(), "resolved links should be cached")
+
+	assert.Equal(t, expected, resolver.Resolve(context.Background(), server.URL+"/news/post?utm_source=rss"))
+	assert.EqualValues(t, 1, requests.Load(), "regular pages should not be fetched")
+}
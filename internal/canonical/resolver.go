@@ -0,0 +1,141 @@
+package canonical
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+const (
+	// resolveTimeout limits fetching of a single page to find its canonical link.
+	resolveTimeout = 10 * time.Second
+	// resolverCacheSize is the number of resolved links kept to avoid fetching the same page on every fetch.
+	resolverCacheSize = 4096
+)
+
+// Resolver canonicalizes links. Links which look like AMP pages are resolved
+// to the page referenced by their <link rel="canonical">, as AMP path can't be reliably guessed.
+// Other pages are not fetched, as it would double the number of requests to the sources.
+type Resolver struct {
+	client *source.HTTPClient
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+func NewResolver(client *source.HTTPClient) *Resolver {
+	if client == nil {
+		client = source.NewHTTPClient(http.DefaultClient, "", 0)
+	}
+
+	return &Resolver{
+		client: client,
+		cache:  make(map[string]string),
+	}
+}
+
+// Resolve returns canonical form of the link. If canonical link of AMP page can't be fetched,
+// link is only normalized.
+func (r *Resolver) Resolve(ctx context.Context, link string) string {
+	if !IsAMP(link) {
+		return URL(link)
+	}
+
+	r.mu.Lock()
+	canonical, ok := r.cache[link]
+	r.mu.Unlock()
+
+	if ok {
+		return canonical
+	}
+
+	canonical = URL(link)
+
+	if resolved, ok := r.fetchCanonical(ctx, link); ok {
+		canonical = URL(resolved)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.cache) >= resolverCacheSize {
+		r.cache = make(map[string]string)
+	}
+
+	r.cache[link] = canonical
+
+	return canonical
+}
+
+func (r *Resolver) fetchCanonical(ctx context.Context, link string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+
+	resp, err := r.client.Get(ctx, link, "text/html", model.CacheValidators{})
+	if err != nil || resp.NotModified {
+		return "", false
+	}
+
+	href, ok := canonicalHref(resp.Body)
+	if !ok {
+		return "", false
+	}
+
+	base, err := url.Parse(link)
+	if err != nil {
+		return "", false
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return "", false
+	}
+
+	return base.ResolveReference(ref).String(), true
+}
+
+// canonicalHref looks for <link rel="canonical"> in the page head.
+func canonicalHref(page []byte) (string, bool) {
+	tokenizer := html.NewTokenizer(bytes.NewReader(page))
+
+	for {
+		switch tokenizer.Next() {
+		case html.ErrorToken:
+			return "", false
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+
+			if token.Data == "body" {
+				return "", false
+			}
+
+			if token.Data != "link" {
+				continue
+			}
+
+			var rel, href string
+
+			for _, attr := range token.Attr {
+				switch attr.Key {
+				case "rel":
+					rel = attr.Val
+				case "href":
+					href = attr.Val
+				}
+			}
+
+			if strings.EqualFold(strings.TrimSpace(rel), "canonical") && href != "" {
+				return href, true
+			}
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Package cron parses cron expressions and finds times they fire at.
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidExpression = errors.New("cron expression must have 5 fields: minute, hour, day of month, month, day of week")
+
+var aliases = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	minute, hour, dom, month, dow bits
+	// domRestricted and dowRestricted are set unless the field is *. If both are restricted,
+	// the day matches either of them, as in classic cron.
+	domRestricted, dowRestricted bool
+}
+
+type bits uint64
+
+func (b bits) has(n int) bool {
+	return b&(1<<uint(n)) != 0
+}
+
+type bounds struct {
+	min, max int
+}
+
+var (
+	minuteBounds = bounds{0, 59}
+	hourBounds   = bounds{0, 23}
+	domBounds    = bounds{1, 31}
+	monthBounds  = bounds{1, 12}
+	dowBounds    = bounds{0, 7}
+)
+
+// Parse parses standard 5-field cron expression, e.g. "0 9 * * 1-5", or one of aliases like @daily.
+// Fields support lists, ranges and steps: "1,15", "9-18", "*/10", "0-30/5". Sunday is either 0 or 7.
+func Parse(expr string) (*Schedule, error) {
+	if alias, ok := aliases[strings.TrimSpace(expr)]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, ErrInvalidExpression
+	}
+
+	var (
+		s   Schedule
+		err error
+	)
+
+	for _, f := range []struct {
+		name   string
+		value  string
+		bounds bounds
+		bits   *bits
+	}{
+		{"minute", fields[0], minuteBounds, &s.minute},
+		{"hour", fields[1], hourBounds, &s.hour},
+		{"day of month", fields[2], domBounds, &s.dom},
+		{"month", fields[3], monthBounds, &s.month},
+		{"day of week", fields[4], dowBounds, &s.dow},
+	} {
+		if *f.bits, err = parseField(f.value, f.bounds); err != nil {
+			return nil, fmt.Errorf("%s: %w", f.name, err)
+		}
+	}
+
+	if s.dow.has(7) {
+		s.dow |= 1
+	}
+
+	s.domRestricted = fields[2] != "*"
+	s.dowRestricted = fields[4] != "*"
+
+	return &s, nil
+}
+
+func parseField(field string, b bounds) (bits, error) {
+	var result bits
+
+	for _, part := range strings.Split(field, ",") {
+		r, err := parseRange(part, b)
+		if err != nil {
+			return 0, err
+		}
+
+		result |= r
+	}
+
+	return result, nil
+}
+
+func parseRange(part string, b bounds) (bits, error) {
+	var (
+		rangePart, stepPart, hasStep = strings.Cut(part, "/")
+		start, end                   = b.min, b.max
+		step                         = 1
+		err                          error
+	)
+
+	switch {
+	case rangePart == "*":
+	case strings.Contains(rangePart, "-"):
+		from, to, _ := strings.Cut(rangePart, "-")
+
+		if start, err = parseNumber(from, b); err != nil {
+			return 0, err
+		}
+
+		if end, err = parseNumber(to, b); err != nil {
+			return 0, err
+		}
+	default:
+		if start, err = parseNumber(rangePart, b); err != nil {
+			return 0, err
+		}
+
+		if !hasStep {
+			end = start
+		}
+	}
+
+	if hasStep {
+		if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+			return 0, fmt.Errorf("invalid step %q", stepPart)
+		}
+	}
+
+	if start > end {
+		return 0, fmt.Errorf("invalid range %q", rangePart)
+	}
+
+	var result bits
+	for n := start; n <= end; n += step {
+		result |= 1 << uint(n)
+	}
+
+	return result, nil
+}
+
+func parseNumber(s string, b bounds) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+
+	if n < b.min || n > b.max {
+		return 0, fmt.Errorf("value %d is out of range %d-%d", n, b.min, b.max)
+	}
+
+	return n, nil
+}
+
+// Next returns the first time after t the schedule fires at, in location of t.
+// Zero time is returned if the schedule never fires, e.g. on February 30.
+func (s *Schedule) Next(t time.Time) time.Time {
+	var (
+		loc       = t.Location()
+		yearLimit = t.Year() + 5
+	)
+
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+
+	for t.Year() <= yearLimit {
+		if !s.month.has(int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.hour.has(t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+
+		if !s.minute.has(t.Minute()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+			continue
+		}
+
+		return t
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	var (
+		dom = s.dom.has(t.Day())
+		dow = s.dow.has(int(t.Weekday()))
+	)
+
+	if s.domRestricted && s.dowRestricted {
+		return dom || dow
+	}
+
+	return dom && dow
+}
@@ -0,0 +1,101 @@
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/cron"
+)
+
+func TestParse(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"a * * * *",
+	} {
+		_, err := cron.Parse(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	tests := []struct {
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{
+			expr:     "0 9 * * *",
+			from:     time.Date(2026, 10, 18, 8, 59, 30, 0, time.UTC),
+			expected: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 9 * * *",
+			from:     time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 9 * * *",
+			from:     time.Date(2026, 10, 18, 10, 0, 0, 0, moscow),
+			expected: time.Date(2026, 10, 19, 9, 0, 0, 0, moscow),
+		},
+		{
+			expr:     "*/15 9-10 * * 1-5",
+			from:     time.Date(2026, 10, 16, 10, 50, 0, 0, time.UTC), // Friday
+			expected: time.Date(2026, 10, 19, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 0 1,15 * *",
+			from:     time.Date(2026, 12, 20, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 12 13 * 5", // either 13th or Friday
+			from:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 10, 23, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 0 * * 7",
+			from:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), // Sunday
+			expected: time.Date(2026, 10, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "@daily",
+			from:     time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 0 29 2 *",
+			from:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			expr:     "0 0 30 2 *",
+			from:     time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+			expected: time.Time{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := cron.Parse(tt.expr)
+			require.NoError(t, err)
+
+			next := schedule.Next(tt.from)
+			assert.True(t, tt.expected.Equal(next), "got %v", next)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+// Package dashboard is the web admin UI served by the bot's HTTP server.
+package dashboard
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"embed"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+//go:embed templates/*.html
+var templatesFS embed.FS
+
+const (
+	queueSize   = 50
+	historySize = 100
+)
+
+type SourceStorage interface {
+	Sources(ctx context.Context) ([]model.Source, error)
+	SourceByID(ctx context.Context, id int64) (*model.Source, error)
+	Update(ctx context.Context, source model.Source) error
+	Enable(ctx context.Context, id int64) error
+}
+
+type SourceKinds interface {
+	Supports(kind string) bool
+	Kinds() []string
+}
+
+type ArticleStorage interface {
+	Posted(ctx context.Context, channelID int64, limit uint64) ([]model.Article, error)
+	SetQueuePriority(ctx context.Context, articleID int64, priority int) error
+}
+
+type ChannelProvider interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+}
+
+// QueueProvider returns articles to be posted to the channel in the order the notifier posts them.
+type QueueProvider interface {
+	Queue(ctx context.Context, channel model.Channel, limit int) ([]model.Article, error)
+}
+
+type Handler struct {
+	sources  SourceStorage
+	kinds    SourceKinds
+	articles ArticleStorage
+	channels ChannelProvider
+	queue    QueueProvider
+	user     string
+	password string
+
+	pages map[string]*template.Template
+}
+
+// New creates handler of the dashboard mounted at /admin/. Every request must be authorized
+// with HTTP basic auth.
+func New(
+	sources SourceStorage,
+	kinds SourceKinds,
+	articles ArticleStorage,
+	channels ChannelProvider,
+	queue QueueProvider,
+	user string,
+	password string,
+) *Handler {
+	funcs := template.FuncMap{
+		"time": formatTime,
+		"inc":  func(i int) int { return i + 1 },
+	}
+	pages := make(map[string]*template.Template)
+
+	for _, page := range []string{"sources", "source", "queue", "history"} {
+		pages[page] = template.Must(
+			template.New(page).Funcs(funcs).ParseFS(templatesFS, "templates/layout.html", "templates/"+page+".html"),
+		)
+	}
+
+	return &Handler{
+		sources:  sources,
+		kinds:    kinds,
+		articles: articles,
+		channels: channels,
+		queue:    queue,
+		user:     user,
+		password: password,
+		pages:    pages,
+	}
+}
+
+var (
+	errNotFound  = errors.New("not found")
+	errForbidden = errors.New("forbidden")
+)
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="News Feed Bot", charset="UTF-8"`)
+		http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method == http.MethodPost && !sameOrigin(r) {
+		// browsers send basic auth credentials with forms submitted from other sites
+		h.writeError(w, errForbidden)
+		return
+	}
+
+	if err := h.route(w, r); err != nil {
+		h.writeError(w, err)
+	}
+}
+
+func (h *Handler) authorized(r *http.Request) bool {
+	user, password, ok := r.BasicAuth()
+	if !ok || h.password == "" {
+		return false
+	}
+
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(h.user)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(h.password)) == 1
+
+	return userOK && passwordOK
+}
+
+// sameOrigin reports whether the request is sent from the dashboard itself.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		origin = r.Header.Get("Referer")
+	}
+
+	if origin == "" {
+		// not a browser
+		return true
+	}
+
+	u, err := url.Parse(origin)
+
+	return err == nil && u.Host == r.Host
+}
+
+// route dispatches the request by its path relative to /admin/ and method.
+func (h *Handler) route(w http.ResponseWriter, r *http.Request) error {
+	path := strings.Trim(strings.TrimPrefix(r.URL.Path, "/admin"), "/")
+	segments := strings.Split(path, "/")
+
+	switch {
+	case path == "":
+		http.Redirect(w, r, "/admin/sources", http.StatusFound)
+		return nil
+	case path == "sources" && r.Method == http.MethodGet:
+		return h.sourcesPage(w, r)
+	case len(segments) == 2 && segments[0] == "sources":
+		return withID(segments[1], func(id int64) error {
+			switch r.Method {
+			case http.MethodGet:
+				return h.sourcePage(w, r, id)
+			case http.MethodPost:
+				return h.updateSource(w, r, id)
+			default:
+				return errNotFound
+			}
+		})
+	case len(segments) == 3 && segments[0] == "sources" && segments[2] == "enable" && r.Method == http.MethodPost:
+		return withID(segments[1], func(id int64) error { return h.enableSource(w, r, id) })
+	case path == "queue" && r.Method == http.MethodGet:
+		return h.queuePage(w, r)
+	case len(segments) == 2 && segments[0] == "queue" && r.Method == http.MethodPost:
+		return withID(segments[1], func(id int64) error { return h.setQueuePriority(w, r, id) })
+	case path == "history" && r.Method == http.MethodGet:
+		return h.historyPage(w, r)
+	default:
+		return errNotFound
+	}
+}
+
+func withID(value string, fn func(id int64) error) error {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return errNotFound
+	}
+
+	return fn(id)
+}
+
+// page is the data common to all pages.
+type page struct {
+	Title   string
+	Section string
+	Error   string
+	Data    any
+}
+
+func (h *Handler) render(w http.ResponseWriter, status int, name string, p page) error {
+	// render to buffer, so template errors don't produce half of the page
+	var buf bytes.Buffer
+	if err := h.pages[name].ExecuteTemplate(&buf, "layout", p); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+
+	return err
+}
+
+func (h *Handler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errNotFound), errors.Is(err, sql.ErrNoRows):
+		http.Error(w, "Не найдено", http.StatusNotFound)
+	case errors.Is(err, errForbidden):
+		http.Error(w, "Запрос отклонен", http.StatusForbidden)
+	default:
+		slog.Error("failed to handle dashboard request", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "—"
+	}
+
+	return t.Local().Format("02.01.2006 15:04")
+}
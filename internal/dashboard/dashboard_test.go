@@ -0,0 +1,235 @@
+package dashboard_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/dashboard"
+	"github.com/defer-panic/news-feed-bot/internal/fetcher"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type sourceStorageStub struct {
+	sources map[int64]model.Source
+}
+
+func (s *sourceStorageStub) Sources(context.Context) ([]model.Source, error) {
+	return []model.Source{s.sources[1], s.sources[2]}, nil
+}
+
+func (s *sourceStorageStub) SourceByID(_ context.Context, id int64) (*model.Source, error) {
+	source, ok := s.sources[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	return &source, nil
+}
+
+func (s *sourceStorageStub) Update(_ context.Context, source model.Source) error {
+	s.sources[source.ID] = source
+	return nil
+}
+
+func (s *sourceStorageStub) Enable(_ context.Context, id int64) error {
+	source := s.sources[id]
+	source.Health = model.SourceHealth{}
+	s.sources[id] = source
+
+	return nil
+}
+
+type articleStorageStub struct {
+	posted     map[int64][]model.Article
+	priorities map[int64]int
+}
+
+func (s *articleStorageStub) Posted(_ context.Context, channelID int64, _ uint64) ([]model.Article, error) {
+	return s.posted[channelID], nil
+}
+
+func (s *articleStorageStub) SetQueuePriority(_ context.Context, articleID int64, priority int) error {
+	s.priorities[articleID] = priority
+	return nil
+}
+
+type channelProviderStub []model.Channel
+
+func (s channelProviderStub) Channels(context.Context) ([]model.Channel, error) {
+	return s, nil
+}
+
+type queueProviderStub map[int64][]model.Article
+
+func (s queueProviderStub) Queue(_ context.Context, channel model.Channel, _ int) ([]model.Article, error) {
+	return s[channel.ID], nil
+}
+
+type testDashboard struct {
+	srv      *httptest.Server
+	sources  *sourceStorageStub
+	articles *articleStorageStub
+}
+
+func setupDashboard(t *testing.T) *testDashboard {
+	d := &testDashboard{
+		sources: &sourceStorageStub{sources: map[int64]model.Source{
+			1: {ID: 1, Name: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom", Kind: "atom", Priority: 2},
+			2: {
+				ID:      2,
+				Name:    "Broken <feed>",
+				FeedURL: "https://example.com/rss",
+				Kind:    "rss",
+				Health: model.SourceHealth{
+					LastError:           "unexpected status code 500",
+					ConsecutiveFailures: 10,
+					DisabledAt:          time.Now(),
+				},
+			},
+		}},
+		articles: &articleStorageStub{
+			posted: map[int64][]model.Article{
+				2: {{ID: 3, Title: "Posted to Go channel", Link: "https://example.com/3", PostedAt: time.Now()}},
+			},
+			priorities: make(map[int64]int),
+		},
+	}
+
+	channels := channelProviderStub{
+		{ID: 1, Name: "Main", Default: true},
+		{ID: 2, Name: "Go"},
+	}
+	queue := queueProviderStub{
+		1: {
+			{ID: 1, Title: "First in queue", Link: "https://example.com/1", SourceName: "Go Blog", QueuePriority: 5},
+			{ID: 2, Title: "Second in queue", Link: "https://example.com/2", SourceName: "Go Blog"},
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", dashboard.New(
+		d.sources,
+		fetcher.DefaultRegistry(nil),
+		d.articles,
+		channels,
+		queue,
+		"admin",
+		"secret",
+	))
+
+	d.srv = httptest.NewServer(mux)
+	t.Cleanup(d.srv.Close)
+
+	return d
+}
+
+func (d *testDashboard) get(t *testing.T, path string) (int, string) {
+	req, err := http.NewRequest(http.MethodGet, d.srv.URL+path, nil)
+	require.NoError(t, err)
+	req.SetBasicAuth("admin", "secret")
+
+	return do(t, req)
+}
+
+func (d *testDashboard) post(t *testing.T, path string, form url.Values, origin string) (int, string) {
+	req, err := http.NewRequest(http.MethodPost, d.srv.URL+path, strings.NewReader(form.Encode()))
+	require.NoError(t, err)
+	req.SetBasicAuth("admin", "secret")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Origin", origin)
+
+	return do(t, req)
+}
+
+func do(t *testing.T, req *http.Request) (int, string) {
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	return resp.StatusCode, string(body)
+}
+
+func TestDashboard_Auth(t *testing.T) {
+	d := setupDashboard(t)
+
+	resp, err := http.Get(d.srv.URL + "/admin/sources")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.Contains(t, resp.Header.Get("WWW-Authenticate"), "Basic")
+}
+
+func TestDashboard_Sources(t *testing.T) {
+	d := setupDashboard(t)
+
+	status, body := d.get(t, "/admin/sources")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `<a href="/admin/sources/1">Go Blog</a>`)
+	assert.Contains(t, body, "Broken &lt;feed&gt;")
+	assert.Contains(t, body, "unexpected status code 500")
+	assert.Contains(t, body, `action="/admin/sources/2/enable"`)
+
+	status, body = d.get(t, "/admin/sources/1")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, `<option value="atom" selected>atom</option>`)
+
+	form := url.Values{
+		"name":       {"The Go Blog"},
+		"url":        {"https://go.dev/blog/feed.atom"},
+		"kind":       {"atom"},
+		"priority":   {"7"},
+		"allow_list": {"1"},
+	}
+
+	status, _ = d.post(t, "/admin/sources/1", form, "https://evil.example.com")
+	assert.Equal(t, http.StatusForbidden, status)
+	assert.Equal(t, "Go Blog", d.sources.sources[1].Name)
+
+	status, body = d.post(t, "/admin/sources/1", form, d.srv.URL)
+	require.Equal(t, http.StatusOK, status, body) // redirected to the list
+	assert.Equal(t, "The Go Blog", d.sources.sources[1].Name)
+	assert.Equal(t, 7, d.sources.sources[1].Priority)
+	assert.True(t, d.sources.sources[1].AllowList)
+
+	form.Set("priority", "high")
+	status, body = d.post(t, "/admin/sources/1", form, d.srv.URL)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, "Приоритет должен быть целым числом")
+
+	status, _ = d.get(t, "/admin/sources/3")
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestDashboard_Queue(t *testing.T) {
+	d := setupDashboard(t)
+
+	status, body := d.get(t, "/admin/queue")
+	require.Equal(t, http.StatusOK, status)
+	assert.Less(t, strings.Index(body, "First in queue"), strings.Index(body, "Second in queue"))
+	assert.Contains(t, body, `<option value="1" selected>Main (по умолчанию)</option>`)
+
+	status, _ = d.post(t, "/admin/queue/2", url.Values{"queue_priority": {"10"}, "channel": {"1"}}, d.srv.URL)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[int64]int{2: 10}, d.articles.priorities)
+
+	status, body = d.get(t, "/admin/history?channel=2")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, body, "Posted to Go channel")
+
+	status, _ = d.get(t, "/admin/history?channel=5")
+	assert.Equal(t, http.StatusNotFound, status)
+}
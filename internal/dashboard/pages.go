@@ -0,0 +1,165 @@
+package dashboard
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+func (h *Handler) sourcesPage(w http.ResponseWriter, r *http.Request) error {
+	sources, err := h.sources.Sources(r.Context())
+	if err != nil {
+		return err
+	}
+
+	return h.render(w, http.StatusOK, "sources", page{Title: "Источники", Section: "sources", Data: sources})
+}
+
+type sourceForm struct {
+	Source model.Source
+	Kinds  []string
+}
+
+func (h *Handler) sourcePage(w http.ResponseWriter, r *http.Request, id int64) error {
+	source, err := h.sources.SourceByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return h.renderSourceForm(w, http.StatusOK, *source, "")
+}
+
+func (h *Handler) renderSourceForm(w http.ResponseWriter, status int, source model.Source, formErr string) error {
+	return h.render(w, status, "source", page{
+		Title:   source.Name,
+		Section: "sources",
+		Error:   formErr,
+		Data:    sourceForm{Source: source, Kinds: h.kinds.Kinds()},
+	})
+}
+
+func (h *Handler) updateSource(w http.ResponseWriter, r *http.Request, id int64) error {
+	source, err := h.sources.SourceByID(r.Context(), id)
+	if err != nil {
+		return err
+	}
+
+	source.Name = strings.TrimSpace(r.PostFormValue("name"))
+	source.FeedURL = strings.TrimSpace(r.PostFormValue("url"))
+	source.Kind = r.PostFormValue("kind")
+	source.AllowList = r.PostFormValue("allow_list") != ""
+
+	priority, err := strconv.Atoi(r.PostFormValue("priority"))
+
+	switch {
+	case err != nil:
+		return h.renderSourceForm(w, http.StatusBadRequest, *source, "Приоритет должен быть целым числом")
+	case source.Name == "":
+		return h.renderSourceForm(w, http.StatusBadRequest, *source, "Укажите название")
+	case source.FeedURL == "":
+		return h.renderSourceForm(w, http.StatusBadRequest, *source, "Укажите URL")
+	case !h.kinds.Supports(source.Kind):
+		return h.renderSourceForm(w, http.StatusBadRequest, *source, "Неизвестный тип источника")
+	}
+
+	source.Priority = priority
+
+	if err := h.sources.Update(r.Context(), *source); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/admin/sources", http.StatusSeeOther)
+
+	return nil
+}
+
+func (h *Handler) enableSource(w http.ResponseWriter, r *http.Request, id int64) error {
+	if err := h.sources.Enable(r.Context(), id); err != nil {
+		return err
+	}
+
+	http.Redirect(w, r, "/admin/sources", http.StatusSeeOther)
+
+	return nil
+}
+
+// channelPage is the data of pages showing articles of one of the channels.
+type channelPage struct {
+	Channels []model.Channel
+	Channel  model.Channel
+	Articles []model.Article
+}
+
+// selectChannel returns channels and the one from "channel" parameter, the default channel if it's absent.
+func (h *Handler) selectChannel(r *http.Request) ([]model.Channel, model.Channel, error) {
+	channels, err := h.channels.Channels(r.Context())
+	if err != nil {
+		return nil, model.Channel{}, err
+	}
+
+	id, _ := strconv.ParseInt(r.FormValue("channel"), 10, 64)
+
+	for _, channel := range channels {
+		if channel.ID == id || (id == 0 && channel.Default) {
+			return channels, channel, nil
+		}
+	}
+
+	return nil, model.Channel{}, errNotFound
+}
+
+func (h *Handler) queuePage(w http.ResponseWriter, r *http.Request) error {
+	channels, channel, err := h.selectChannel(r)
+	if err != nil {
+		return err
+	}
+
+	articles, err := h.queue.Queue(r.Context(), channel, queueSize)
+	if err != nil {
+		return err
+	}
+
+	return h.render(w, http.StatusOK, "queue", page{
+		Title:   "Очередь",
+		Section: "queue",
+		Data:    channelPage{Channels: channels, Channel: channel, Articles: articles},
+	})
+}
+
+func (h *Handler) setQueuePriority(w http.ResponseWriter, r *http.Request, articleID int64) error {
+	priority, err := strconv.Atoi(r.PostFormValue("queue_priority"))
+	if err != nil {
+		http.Error(w, "Приоритет должен быть целым числом", http.StatusBadRequest)
+		return nil
+	}
+
+	if err := h.articles.SetQueuePriority(r.Context(), articleID, priority); err != nil {
+		return err
+	}
+
+	channelID, _ := strconv.ParseInt(r.PostFormValue("channel"), 10, 64)
+	http.Redirect(w, r, fmt.Sprintf("/admin/queue?channel=%d", channelID), http.StatusSeeOther)
+
+	return nil
+}
+
+func (h *Handler) historyPage(w http.ResponseWriter, r *http.Request) error {
+	channels, channel, err := h.selectChannel(r)
+	if err != nil {
+		return err
+	}
+
+	articles, err := h.articles.Posted(r.Context(), channel.ID, historySize)
+	if err != nil {
+		return err
+	}
+
+	return h.render(w, http.StatusOK, "history", page{
+		Title:   "История",
+		Section: "history",
+		Data:    channelPage{Channels: channels, Channel: channel, Articles: articles},
+	})
+}
@@ -0,0 +1,139 @@
+// Package dedup detects near-duplicate articles, e.g. the same story published by several outlets
+// under slightly different titles and links.
+package dedup
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// TitleSimilarityThreshold is the minimal Jaccard similarity of title words for articles to be duplicates.
+	TitleSimilarityThreshold = 0.8
+	// SimHashDistanceThreshold is the maximal number of differing SimHash bits for articles to be duplicates.
+	SimHashDistanceThreshold = 3
+
+	// minTitleTokens protects short titles (e.g. "Go 1.21") from being clustered together.
+	minTitleTokens = 4
+	// minContentTokens is the minimal number of words for content to get a SimHash.
+	minContentTokens = 20
+)
+
+var htmlTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// Fingerprint is a compact representation of an article used to compare it with others.
+type Fingerprint struct {
+	// SimHash of article content, zero if the content is too short to be compared.
+	SimHash uint64
+
+	title map[string]struct{}
+}
+
+// New builds fingerprint of the article with given title and content (plain text or HTML).
+func New(title, content string) Fingerprint {
+	return Fingerprint{
+		SimHash: SimHash(Tokens(content)),
+		title:   tokenSet(Tokens(title)),
+	}
+}
+
+// FromSimHash restores fingerprint of the stored article from its title and precomputed SimHash.
+func FromSimHash(title string, simHash uint64) Fingerprint {
+	return Fingerprint{
+		SimHash: simHash,
+		title:   tokenSet(Tokens(title)),
+	}
+}
+
+// IsDuplicate reports whether both fingerprints describe the same story:
+// either their titles are nearly the same, or their contents are.
+func (f Fingerprint) IsDuplicate(other Fingerprint) bool {
+	if len(f.title) >= minTitleTokens && len(other.title) >= minTitleTokens &&
+		jaccard(f.title, other.title) >= TitleSimilarityThreshold {
+		return true
+	}
+
+	if f.SimHash == 0 || other.SimHash == 0 {
+		return false
+	}
+
+	return Distance(f.SimHash, other.SimHash) <= SimHashDistanceThreshold
+}
+
+// Tokens splits text into lowercase words, dropping HTML tags and punctuation.
+func Tokens(text string) []string {
+	text = htmlTagRe.ReplaceAllString(text, " ")
+
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SimHash computes 64-bit SimHash of words. Texts shorter than minContentTokens words get zero hash,
+// as there are too few features for the hash to be meaningful.
+func SimHash(tokens []string) uint64 {
+	if len(tokens) < minContentTokens {
+		return 0
+	}
+
+	var weights [64]int
+
+	for _, token := range tokens {
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(token))
+		sum := h.Sum64()
+
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var hash uint64
+
+	for bit, weight := range weights {
+		if weight > 0 {
+			hash |= 1 << bit
+		}
+	}
+
+	return hash
+}
+
+// Distance returns the number of differing bits of two hashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func tokenSet(tokens []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(tokens))
+
+	for _, token := range tokens {
+		set[token] = struct{}{}
+	}
+
+	return set
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	var intersection int
+
+	for token := range a {
+		if _, ok := b[token]; ok {
+			intersection++
+		}
+	}
+
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,79 @@
+package dedup_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/defer-panic/news-feed-bot/internal/dedup"
+)
+
+const story = `<p>The Go team has announced the release of Go 1.21, which brings new built-in functions min, max and clear,
+improvements to type inference, a new log/slog package for structured logging and profile-guided optimization
+which is now generally available and can improve performance of typical programs by several percent.</p>`
+
+func TestTokens(t *testing.T) {
+	assert.Equal(t,
+		[]string{"go", "1", "21", "is", "released", "привет", "мир"},
+		dedup.Tokens("<b>Go 1.21</b> is released! Привет, мир."),
+	)
+}
+
+func TestFingerprint_IsDuplicate(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     dedup.Fingerprint
+		expected bool
+	}{
+		{
+			name:     "similar titles",
+			a:        dedup.New("The bits of Go we avoid (and why)", ""),
+			b:        dedup.New("The Bits of Go We Avoid, and Why | Go Time", ""),
+			expected: true,
+		},
+		{
+			name:     "different titles",
+			a:        dedup.New("The bits of Go we avoid (and why)", ""),
+			b:        dedup.New("This will blow your docs off", ""),
+			expected: false,
+		},
+		{
+			name:     "short titles are not compared",
+			a:        dedup.New("Go 1.21", ""),
+			b:        dedup.New("Go 1.21!", ""),
+			expected: false,
+		},
+		{
+			name:     "same content under different titles",
+			a:        dedup.New("Go 1.21 is released", story),
+			b:        dedup.New("Go 1.21: what's new", strings.ReplaceAll(story, "several", "a few")),
+			expected: true,
+		},
+		{
+			name:     "different content",
+			a:        dedup.New("Go 1.21 is released", story),
+			b:        dedup.New("Rust 1.72 is released", strings.Repeat("Rust gets new features and faster compile times. ", 5)),
+			expected: false,
+		},
+		{
+			name:     "restored from stored hash",
+			a:        dedup.New("Go 1.21 is released", story),
+			b:        dedup.FromSimHash("Go 1.21: what's new", dedup.New("Go 1.21 is released", story).SimHash),
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.a.IsDuplicate(tt.b))
+			assert.Equal(t, tt.expected, tt.b.IsDuplicate(tt.a))
+		})
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	assert.Zero(t, dedup.SimHash(dedup.Tokens("too short to be hashed")))
+	assert.NotZero(t, dedup.SimHash(dedup.Tokens(story)))
+	assert.Equal(t, 0, dedup.Distance(dedup.SimHash(dedup.Tokens(story)), dedup.SimHash(dedup.Tokens(story))))
+}
@@ -0,0 +1,307 @@
+// Package feed serves articles posted by the bot as RSS, Atom and JSON Feed,
+// so the curated stream can be followed in a feed reader.
+package feed
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// Paths the feeds are served at.
+const (
+	RSSPath  = "/feed.rss"
+	AtomPath = "/feed.atom"
+	JSONPath = "/feed.json"
+)
+
+type ArticleProvider interface {
+	Posted(ctx context.Context, channelID int64, limit uint64) ([]model.Article, error)
+}
+
+type Handler struct {
+	articles ArticleProvider
+	title    string
+	baseURL  string
+	limit    uint64
+}
+
+// NewHandler creates handlers of feeds with up to limit latest posted articles. Links to feeds
+// are built from baseURL, or from the request if it's empty.
+func NewHandler(articles ArticleProvider, title string, baseURL string, limit uint64) *Handler {
+	return &Handler{
+		articles: articles,
+		title:    title,
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+		limit:    limit,
+	}
+}
+
+// feed is what is common to all feed formats.
+type feed struct {
+	title    string
+	selfURL  string
+	homeURL  string
+	updated  time.Time
+	articles []model.Article
+}
+
+// RSS serves RSS 2.0 feed.
+func (h *Handler) RSS(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	doc := rssDoc{
+		Version: "2.0",
+		AtomNS:  atomNS,
+		Channel: rssChannel{
+			Title:         f.title,
+			Link:          f.homeURL,
+			Description:   f.title,
+			LastBuildDate: formatRSSDate(f.updated),
+			Self:          atomLink{Href: f.selfURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+
+	for _, article := range f.articles {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       article.Title,
+			Link:        article.Link,
+			GUID:        rssGUID{Value: article.Link, IsPermaLink: true},
+			Description: content(article),
+			PubDate:     formatRSSDate(article.PostedAt),
+			Categories:  article.Categories,
+			Source:      &rssSource{Value: article.SourceName},
+		})
+	}
+
+	writeXML(w, "application/rss+xml; charset=utf-8", doc)
+}
+
+// Atom serves Atom 1.0 feed.
+func (h *Handler) Atom(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	doc := atomFeed{
+		NS:      atomNS,
+		ID:      f.selfURL,
+		Title:   f.title,
+		Updated: f.updated.Format(time.RFC3339),
+		Author:  atomAuthor{Name: f.title},
+		Links: []atomLink{
+			{Href: f.selfURL, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.homeURL, Rel: "alternate"},
+		},
+	}
+
+	for _, article := range f.articles {
+		entry := atomEntry{
+			ID:        article.Link,
+			Title:     article.Title,
+			Link:      atomLink{Href: article.Link, Rel: "alternate"},
+			Updated:   article.PostedAt.Format(time.RFC3339),
+			Published: article.PublishedAt.Format(time.RFC3339),
+			Summary:   atomText{Type: "html", Value: content(article)},
+		}
+
+		if article.SourceName != "" {
+			entry.Author = &atomAuthor{Name: article.SourceName}
+		}
+
+		for _, category := range article.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	writeXML(w, "application/atom+xml; charset=utf-8", doc)
+}
+
+// JSON serves JSON Feed 1.1.
+func (h *Handler) JSON(w http.ResponseWriter, r *http.Request) {
+	f, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	doc := jsonFeed{
+		Version:     "https://jsonfeed.org/version/1.1",
+		Title:       f.title,
+		HomePageURL: f.homeURL,
+		FeedURL:     f.selfURL,
+		Items:       make([]jsonFeedItem, 0, len(f.articles)),
+	}
+
+	for _, article := range f.articles {
+		item := jsonFeedItem{
+			ID:            article.Link,
+			URL:           article.Link,
+			Title:         article.Title,
+			ContentHTML:   content(article),
+			DatePublished: article.PostedAt.Format(time.RFC3339),
+			Tags:          article.Categories,
+		}
+
+		if article.SourceName != "" {
+			item.Authors = []jsonFeedAuthor{{Name: article.SourceName}}
+		}
+
+		doc.Items = append(doc.Items, item)
+	}
+
+	w.Header().Set("Content-Type", "application/feed+json; charset=utf-8")
+
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		slog.Error("failed to write feed", "error", err)
+	}
+}
+
+// load gets articles of the feed. The feed is limited to a single channel with "channel" query parameter.
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) (feed, bool) {
+	var channelID int64
+
+	if value := r.URL.Query().Get("channel"); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			http.Error(w, "invalid channel id", http.StatusBadRequest)
+			return feed{}, false
+		}
+
+		channelID = id
+	}
+
+	articles, err := h.articles.Posted(r.Context(), channelID, h.limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "failed to get posted articles", "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return feed{}, false
+	}
+
+	baseURL := h.baseURL
+	if baseURL == "" {
+		baseURL = requestBaseURL(r)
+	}
+
+	// nothing is served at the root, so the RSS feed of the same channel is the home of all feeds
+	homeURL := baseURL + RSSPath
+	if channelID != 0 {
+		homeURL += "?channel=" + strconv.FormatInt(channelID, 10)
+	}
+
+	f := feed{
+		title:    h.title,
+		selfURL:  baseURL + r.URL.RequestURI(),
+		homeURL:  homeURL,
+		updated:  time.Now().UTC(),
+		articles: articles,
+	}
+
+	if len(articles) > 0 {
+		// articles are ordered by the time they were posted, latest first
+		f.updated = articles[0].PostedAt
+	}
+
+	return f, true
+}
+
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+
+	if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+		scheme = proto
+	}
+
+	return scheme + "://" + r.Host
+}
+
+// content is HTML content of the article: AI summary if it's generated, summary from the source otherwise.
+// Summary from the source is reduced to text, as its HTML comes from third parties and may contain scripts
+// run by feed readers.
+func content(article model.Article) string {
+	text := article.AISummary
+	if text == "" {
+		text = htmlText(article.Summary)
+	}
+
+	return strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+var lineBreaks = regexp.MustCompile(`\s*\n\s*`)
+
+// htmlText returns text of the HTML fragment with line breaks between blocks. Scripts and styles are dropped.
+func htmlText(fragment string) string {
+	var (
+		text      strings.Builder
+		tokenizer = nethtml.NewTokenizer(strings.NewReader(fragment))
+		// hidden is the depth of elements which text is not shown
+		hidden int
+	)
+
+	for {
+		tokenType := tokenizer.Next()
+
+		switch tokenType {
+		case nethtml.ErrorToken:
+			return strings.TrimSpace(lineBreaks.ReplaceAllString(text.String(), "\n"))
+		case nethtml.TextToken:
+			if hidden == 0 {
+				text.Write(tokenizer.Text())
+			}
+		case nethtml.StartTagToken, nethtml.EndTagToken, nethtml.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+
+			switch atom.Lookup(name) {
+			case atom.Script, atom.Style:
+				if tokenType == nethtml.StartTagToken {
+					hidden++
+				} else if tokenType == nethtml.EndTagToken && hidden > 0 {
+					hidden--
+				}
+			case atom.Br, atom.P, atom.Div, atom.Li, atom.Blockquote, atom.Pre,
+				atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+				text.WriteString("\n")
+			}
+		}
+	}
+}
+
+func formatRSSDate(t time.Time) string {
+	return t.Format(time.RFC1123Z)
+}
+
+func writeXML(w http.ResponseWriter, contentType string, doc any) {
+	w.Header().Set("Content-Type", contentType)
+
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		slog.Error("failed to write feed", "error", err)
+		return
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err := enc.Encode(doc); err != nil {
+		slog.Error("failed to write feed", "error", err)
+	}
+}
@@ -0,0 +1,177 @@
+package feed_test
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/feed"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+var articles = []model.Article{
+	{
+		ID:          2,
+		SourceName:  "Go Blog",
+		Title:       "Go 1.21 <is> released",
+		Link:        "https://go.dev/blog/go1.21",
+		Summary:     "<p>Go 1.21 is out</p>",
+		AISummary:   "New slog & slices packages.",
+		Categories:  []string{"go"},
+		PublishedAt: time.Date(2023, 8, 8, 0, 0, 0, 0, time.UTC),
+		PostedAt:    time.Date(2023, 8, 8, 12, 0, 0, 0, time.UTC),
+	},
+	{
+		ID:          1,
+		SourceName:  "Go Time",
+		Title:       "Go Time #281",
+		Link:        "https://changelog.com/gotime/281",
+		Summary:     "<p>Episode 281</p><script>alert('xss')</script><p>Generics &amp; <b>iterators</b></p>",
+		PublishedAt: time.Date(2023, 8, 7, 0, 0, 0, 0, time.UTC),
+		PostedAt:    time.Date(2023, 8, 7, 12, 0, 0, 0, time.UTC),
+	},
+}
+
+type articleProviderStub struct {
+	channelID int64
+	limit     uint64
+}
+
+func (s *articleProviderStub) Posted(_ context.Context, channelID int64, limit uint64) ([]model.Article, error) {
+	s.channelID = channelID
+	s.limit = limit
+
+	return articles, nil
+}
+
+func newServer(t *testing.T) (*articleProviderStub, *httptest.Server) {
+	provider := &articleProviderStub{}
+	handler := feed.NewHandler(provider, "News Feed", "", 20)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(feed.RSSPath, handler.RSS)
+	mux.HandleFunc(feed.AtomPath, handler.Atom)
+	mux.HandleFunc(feed.JSONPath, handler.JSON)
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return provider, srv
+}
+
+func TestHandler(t *testing.T) {
+	provider, srv := newServer(t)
+
+	tests := []struct {
+		path  string
+		fetch func(url string) ([]model.Item, error)
+		// the parser doesn't read categories of Atom entries
+		noCategories bool
+	}{
+		{
+			path: "/feed.rss",
+			fetch: func(url string) ([]model.Item, error) {
+				return source.NewRSSSourceFromModel(model.Source{FeedURL: url}).Fetch(context.Background())
+			},
+		},
+		{
+			path: "/feed.atom",
+			fetch: func(url string) ([]model.Item, error) {
+				return source.NewRSSSourceFromModel(model.Source{FeedURL: url}).Fetch(context.Background())
+			},
+			noCategories: true,
+		},
+		{
+			path: "/feed.json",
+			fetch: func(url string) ([]model.Item, error) {
+				return source.NewJSONFeedSourceFromModel(model.Source{FeedURL: url}).Fetch(context.Background())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			items, err := tt.fetch(srv.URL + tt.path + "?channel=3")
+			require.NoError(t, err)
+			require.Len(t, items, 2)
+
+			assert.Equal(t, int64(3), provider.channelID)
+			assert.Equal(t, uint64(20), provider.limit)
+
+			assert.Equal(t, "Go 1.21 <is> released", items[0].Title)
+			assert.Equal(t, "https://go.dev/blog/go1.21", items[0].Link)
+			assert.Equal(t, "New slog &amp; slices packages.", items[0].Summary)
+			if !tt.noCategories {
+				assert.Equal(t, []string{"go"}, items[0].Categories)
+			}
+			assert.True(t, articles[0].PostedAt.Equal(items[0].Date), "got %v", items[0].Date)
+
+			// HTML of the source is reduced to text
+			assert.Equal(t, "Episode 281<br>Generics &amp; iterators", items[1].Summary)
+		})
+	}
+}
+
+func TestHandler_RSSSelfLink(t *testing.T) {
+	_, srv := newServer(t)
+
+	resp, err := http.Get(srv.URL + "/feed.rss")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, "application/rss+xml; charset=utf-8", resp.Header.Get("Content-Type"))
+
+	var doc struct {
+		Channel struct {
+			Links []struct {
+				Href string `xml:"href,attr"`
+				Rel  string `xml:"rel,attr"`
+			} `xml:"http://www.w3.org/2005/Atom link"`
+		} `xml:"channel"`
+	}
+	require.NoError(t, xml.NewDecoder(resp.Body).Decode(&doc))
+
+	require.Len(t, doc.Channel.Links, 1)
+	assert.Equal(t, srv.URL+"/feed.rss", doc.Channel.Links[0].Href)
+	assert.Equal(t, "self", doc.Channel.Links[0].Rel)
+}
+
+func TestHandler_InvalidChannel(t *testing.T) {
+	_, srv := newServer(t)
+
+	resp, err := http.Get(srv.URL + "/feed.json?channel=abc")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestHandler_HomeURL(t *testing.T) {
+	_, srv := newServer(t)
+
+	resp, err := http.Get(srv.URL + feed.JSONPath + "?channel=3")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var doc struct {
+		HomePageURL string `json:"home_page_url"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	// the home of the feed is served
+	assert.Equal(t, srv.URL+feed.RSSPath+"?channel=3", doc.HomePageURL)
+
+	home, err := http.Get(doc.HomePageURL)
+	require.NoError(t, err)
+	defer home.Body.Close()
+
+	assert.Equal(t, http.StatusOK, home.StatusCode)
+}
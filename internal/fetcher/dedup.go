@@ -0,0 +1,147 @@
+package fetcher
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/dedup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// dedupIndex holds fingerprints of recent articles, so near-duplicates
+// published by other sources are linked to the stored article instead of being stored again.
+type dedupIndex struct {
+	mu      sync.Mutex
+	entries []*dedupEntry
+}
+
+type dedupEntry struct {
+	canonicalLink string
+	sourceID      int64
+	fingerprint   dedup.Fingerprint
+	// stored is closed once the article is stored. Until then the entry is pending,
+	// and its duplicates wait for it, as they can't be linked to an article which doesn't exist yet.
+	stored chan struct{}
+}
+
+func newDedupIndex(fingerprints []model.ArticleFingerprint) *dedupIndex {
+	index := &dedupIndex{entries: make([]*dedupEntry, 0, len(fingerprints))}
+
+	for _, fingerprint := range fingerprints {
+		entry := &dedupEntry{
+			canonicalLink: fingerprint.CanonicalLink,
+			sourceID:      fingerprint.SourceID,
+			fingerprint:   dedup.FromSimHash(fingerprint.Title, fingerprint.SimHash),
+			stored:        make(chan struct{}),
+		}
+		close(entry.stored)
+
+		index.entries = append(index.entries, entry)
+	}
+
+	return index
+}
+
+// claim returns canonical link of the stored article from another source which the given article duplicates.
+// If there is no such article, the given one is added to the index as pending and returned, so its duplicates
+// from sources fetched at the same time are detected too; the caller must resolve it once the article is stored
+// or failed to be stored. Duplicates of a pending article wait until it's resolved. Nil index detects nothing.
+func (i *dedupIndex) claim(
+	ctx context.Context,
+	article model.Article,
+	fingerprint dedup.Fingerprint,
+) (string, *dedupEntry, error) {
+	if i == nil {
+		return "", nil, nil
+	}
+
+	for {
+		originalLink, pending, wait := i.lookup(article, fingerprint)
+		if wait == nil {
+			return originalLink, pending, nil
+		}
+
+		select {
+		case <-wait.stored:
+			// the original is resolved, claim again
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		}
+	}
+}
+
+// lookup finds the stored article the given one duplicates or the pending one to wait for.
+// If there is neither, the given article is added to the index as pending.
+func (i *dedupIndex) lookup(
+	article model.Article,
+	fingerprint dedup.Fingerprint,
+) (originalLink string, pending *dedupEntry, wait *dedupEntry) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for _, entry := range i.entries {
+		if entry.canonicalLink == article.CanonicalLink {
+			return "", nil, nil
+		}
+	}
+
+	for _, entry := range i.entries {
+		if entry.sourceID == article.SourceID || !entry.fingerprint.IsDuplicate(fingerprint) {
+			continue
+		}
+
+		select {
+		case <-entry.stored:
+			return entry.canonicalLink, nil, nil
+		default:
+			wait = entry
+		}
+	}
+
+	if wait != nil {
+		return "", nil, wait
+	}
+
+	pending = &dedupEntry{
+		canonicalLink: article.CanonicalLink,
+		sourceID:      article.SourceID,
+		fingerprint:   fingerprint,
+		stored:        make(chan struct{}),
+	}
+
+	i.entries = append(i.entries, pending)
+
+	return "", pending, nil
+}
+
+// resolve marks the pending entry stored. If the article failed to be stored, the entry is removed
+// and its duplicates are claimed again. Nil entry is ignored.
+func (i *dedupIndex) resolve(entry *dedupEntry, stored bool) {
+	if entry == nil {
+		return
+	}
+
+	if !stored {
+		i.mu.Lock()
+		i.entries = lo.Without(i.entries, entry)
+		i.mu.Unlock()
+	}
+
+	close(entry.stored)
+}
+
+func (f *Fetcher) dedupIndex(ctx context.Context, now time.Time) (*dedupIndex, error) {
+	if f.config.DedupWindow <= 0 {
+		return nil, nil
+	}
+
+	fingerprints, err := f.articles.RecentFingerprints(ctx, now.Add(-f.config.DedupWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return newDedupIndex(fingerprints), nil
+}
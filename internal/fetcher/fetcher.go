@@ -2,25 +2,43 @@ package fetcher
 
 import (
 	"context"
-	"log"
-	"strings"
-	"sync"
+	"log/slog"
+	"strconv"
 	"time"
 
-	"github.com/tomakado/containers/set"
-
+	"github.com/defer-panic/news-feed-bot/internal/canonical"
+	"github.com/defer-panic/news-feed-bot/internal/dedup"
+	"github.com/defer-panic/news-feed-bot/internal/logging"
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
 	"github.com/defer-panic/news-feed-bot/internal/model"
-	src "github.com/defer-panic/news-feed-bot/internal/source"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
 )
 
 //go:generate moq --out=mocks/mock_article_storage.go --pkg=mocks . ArticleStorage
 type ArticleStorage interface {
-	Store(ctx context.Context, article model.Article) error
+	Store(ctx context.Context, article model.Article) (bool, error)
+	RecentFingerprints(ctx context.Context, since time.Time) ([]model.ArticleFingerprint, error)
+	StoreDuplicate(ctx context.Context, originalLink string, duplicate model.Article) error
 }
 
 //go:generate moq --out=mocks/mock_sources_provider.go --pkg=mocks . SourcesProvider
 type SourcesProvider interface {
 	Sources(ctx context.Context) ([]model.Source, error)
+	SetCacheValidators(ctx context.Context, sourceID int64, validators model.CacheValidators) error
+	SetNextFetch(ctx context.Context, sourceID int64, nextFetchAt time.Time, adaptiveInterval time.Duration) error
+	RecordFetchSuccess(ctx context.Context, sourceID int64) error
+	RecordFetchFailure(ctx context.Context, sourceID int64, fetchErr string, disableAfter int) (bool, error)
+}
+
+//go:generate moq --out=mocks/mock_rules_provider.go --pkg=mocks . RulesProvider
+type RulesProvider interface {
+	FilterRules(ctx context.Context) ([]model.FilterRule, error)
+}
+
+//go:generate moq --out=mocks/mock_topics_provider.go --pkg=mocks . TopicsProvider
+type TopicsProvider interface {
+	Topics(ctx context.Context) ([]model.Topic, error)
+	AddAdmitted(ctx context.Context, admitted map[int64]int64) error
 }
 
 //go:generate moq --out=mocks/mock_source.go --pkg=mocks . Source
@@ -30,111 +48,353 @@ type Source interface {
 	Fetch(ctx context.Context) ([]model.Item, error)
 }
 
+// LinkResolver returns canonical link of the article, so the same article
+// is stored once regardless of tracking parameters and other cosmetic differences of its links.
+type LinkResolver interface {
+	Resolve(ctx context.Context, link string) string
+}
+
+// ConditionalSource is a Source which supports conditional fetching
+// and exposes validators of the last successful fetch.
+type ConditionalSource interface {
+	CacheValidators() model.CacheValidators
+}
+
 type Fetcher struct {
 	articles ArticleStorage
 	sources  SourcesProvider
+	registry *Registry
+	config   Config
+
+	trigger chan struct{}
+}
+
+// Config configures optional dependencies and behavior of the fetcher. Zero value fetches sources
+// with no filtering, duplicates detection, concurrency limits or custom schedule.
+type Config struct {
+	// Rules provides filtering rules, nil means no rules besides Filter.Keywords.
+	Rules RulesProvider
+	// Topics provides topics for allow-list mode, nil means no topics.
+	Topics TopicsProvider
+	// Links resolves canonical links, nil means links are only normalized with canonical.URL.
+	Links LinkResolver
 
-	fetchInterval  time.Duration
-	filterKeywords []string
+	Schedule Schedule
+	Filter   Filter
+	// MaxFailures is the number of failed fetches in a row after which source is disabled, zero means never.
+	MaxFailures int
+	Concurrency Concurrency
+	// DedupWindow is how old stored articles new ones are compared with to detect duplicates, zero disables it.
+	DedupWindow time.Duration
 }
 
-func New(
-	articleStorage ArticleStorage,
-	sourcesProvider SourcesProvider,
-	fetchInterval time.Duration,
-	filterKeywords []string,
-) *Fetcher {
+func New(articleStorage ArticleStorage, sourcesProvider SourcesProvider, registry *Registry, config Config) *Fetcher {
 	return &Fetcher{
-		articles:       articleStorage,
-		sources:        sourcesProvider,
-		fetchInterval:  fetchInterval,
-		filterKeywords: filterKeywords,
+		articles: articleStorage,
+		sources:  sourcesProvider,
+		registry: registry,
+		config:   config,
+		trigger:  make(chan struct{}, 1),
 	}
 }
 
+// Start fetches sources on schedule until the context is canceled.
 func (f *Fetcher) Start(ctx context.Context) error {
-	ticker := time.NewTicker(f.fetchInterval)
+	ticker := time.NewTicker(scheduleCheckInterval)
 	defer ticker.Stop()
 
-	if err := f.Fetch(ctx); err != nil {
-		return err
-	}
+	f.fetchOrLog(ctx, false)
 
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
-			if err := f.Fetch(ctx); err != nil {
-				return err
-			}
+			f.fetchOrLog(ctx, false)
+		case <-f.trigger:
+			f.fetchOrLog(ctx, true)
 		}
 	}
 }
 
+// fetchOrLog fetches sources and logs the failure, e.g. of the storage. Sources which were due
+// remain due, so they are fetched on the next check instead of fetching being stopped for good.
+func (f *Fetcher) fetchOrLog(ctx context.Context, force bool) {
+	if err := f.fetch(ctx, force); err != nil && ctx.Err() == nil {
+		slog.ErrorContext(ctx, "failed to fetch sources", "error", err)
+	}
+}
+
+// Trigger makes the running fetcher fetch all sources regardless of their schedule
+// as soon as it's done with the current fetch. Doesn't block.
+func (f *Fetcher) Trigger() {
+	select {
+	case f.trigger <- struct{}{}:
+	default:
+		// already triggered
+	}
+}
+
+// Fetch fetches all sources which are due according to their schedule.
 func (f *Fetcher) Fetch(ctx context.Context) error {
+	return f.fetch(ctx, false)
+}
+
+// FetchAll fetches all enabled sources, even the ones which are not due yet.
+func (f *Fetcher) FetchAll(ctx context.Context) error {
+	return f.fetch(ctx, true)
+}
+
+func (f *Fetcher) fetch(ctx context.Context, force bool) error {
 	sources, err := f.sources.Sources(ctx)
 	if err != nil {
 		return err
 	}
 
-	var wg sync.WaitGroup
+	var (
+		jobs []fetchJob
+		now  = time.Now()
+	)
 
-	for _, source := range sources {
-		wg.Add(1)
+	for _, sourceModel := range sources {
+		if sourceModel.Health.Disabled() || !(force || f.config.Schedule.isDue(sourceModel, now)) {
+			continue
+		}
 
-		go func(source Source) {
-			defer wg.Done()
+		source, err := f.registry.New(sourceModel)
+		if err != nil {
+			sourceCtx := sourceContext(ctx, sourceModel)
 
-			items, err := source.Fetch(ctx)
-			if err != nil {
-				log.Printf("[ERROR] failed to fetch items from source %q: %v", source.Name(), err)
-				return
-			}
+			slog.ErrorContext(sourceCtx, "failed to create source", "kind", sourceModel.Kind, "error", err)
+			metrics.FetchErrors.WithLabelValues(strconv.FormatInt(sourceModel.ID, 10)).Inc()
+			f.recordFailure(sourceCtx, sourceModel, err)
 
-			if err := f.processItems(ctx, source, items); err != nil {
-				log.Printf("[ERROR] failed to process items from source %q: %v", source.Name(), err)
-				return
-			}
-		}(src.NewRSSSourceFromModel(source))
+			continue
+		}
+
+		jobs = append(jobs, fetchJob{
+			source: source,
+			model:  sourceModel,
+			host:   hostOf(sourceModel.FeedURL),
+		})
+	}
+
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	index, err := f.dedupIndex(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	ruleSet, err := f.ruleSet(ctx)
+	if err != nil {
+		return err
 	}
 
-	wg.Wait()
+	topicSet, err := f.topicSet(ctx)
+	if err != nil {
+		return err
+	}
+
+	cycle := &fetchCycle{dedup: index, rules: ruleSet, topics: topicSet}
+
+	f.config.Concurrency.runPool(ctx, jobs, func(job fetchJob) {
+		f.fetchSource(ctx, job.source, job.model, cycle)
+	})
 
 	return nil
 }
 
-func (f *Fetcher) processItems(ctx context.Context, source Source, items []model.Item) error {
+// fetchCycle is the state shared by all sources fetched at once.
+type fetchCycle struct {
+	dedup  *dedupIndex
+	rules  *rules.Set
+	topics *rules.Topics
+}
+
+// sourceContext adds fields of the source to logs written with the context.
+func sourceContext(ctx context.Context, source model.Source) context.Context {
+	return logging.With(ctx, "source_id", source.ID, "source", source.Name)
+}
+
+func (f *Fetcher) fetchSource(ctx context.Context, source Source, sourceModel model.Source, cycle *fetchCycle) {
+	ctx = sourceContext(ctx, sourceModel)
+	sourceID := strconv.FormatInt(source.ID(), 10)
+
+	started := time.Now()
+	items, err := f.fetchWithTimeout(ctx, source, f.config.Schedule.timeout(sourceModel))
+	metrics.FetchDuration.WithLabelValues(sourceID).Observe(time.Since(started).Seconds())
+
+	defer f.scheduleNextFetch(ctx, sourceModel, items)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to fetch items", "error", err)
+		metrics.FetchErrors.WithLabelValues(sourceID).Inc()
+		f.recordFailure(ctx, sourceModel, err)
+
+		return
+	}
+
+	slog.DebugContext(ctx, "fetched items", "items", len(items), "duration", time.Since(started))
+	metrics.Items.WithLabelValues(sourceID, metrics.ItemFetched).Add(float64(len(items)))
+
+	if err := f.sources.RecordFetchSuccess(ctx, source.ID()); err != nil {
+		slog.ErrorContext(ctx, "failed to record successful fetch", "error", err)
+	}
+
+	allowList := f.config.Filter.AllowList || sourceModel.AllowList
+
+	if err := f.processItems(ctx, source, items, allowList, cycle); err != nil {
+		slog.ErrorContext(ctx, "failed to process items", "error", err)
+		return
+	}
+
+	if err := f.storeCacheValidators(ctx, source, sourceModel.Validators); err != nil {
+		slog.ErrorContext(ctx, "failed to store cache validators", "error", err)
+	}
+}
+
+func (f *Fetcher) fetchWithTimeout(ctx context.Context, source Source, timeout time.Duration) ([]model.Item, error) {
+	if timeout <= 0 {
+		return source.Fetch(ctx)
+	}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return source.Fetch(fetchCtx)
+}
+
+func (f *Fetcher) recordFailure(ctx context.Context, source model.Source, fetchErr error) {
+	disabled, err := f.sources.RecordFetchFailure(ctx, source.ID, fetchErr.Error(), f.config.MaxFailures)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to record failed fetch", "error", err)
+		return
+	}
+
+	if disabled && !source.Health.Disabled() {
+		slog.WarnContext(ctx, "source is disabled after too many failed fetches in a row", "failures", f.config.MaxFailures)
+	}
+}
+
+func (f *Fetcher) scheduleNextFetch(ctx context.Context, source model.Source, items []model.Item) {
+	var (
+		now              = time.Now()
+		interval         = f.config.Schedule.nextInterval(source, items, now)
+		adaptiveInterval time.Duration
+	)
+
+	if source.AdaptiveFetch {
+		adaptiveInterval = interval
+	}
+
+	if err := f.sources.SetNextFetch(ctx, source.ID, now.Add(interval), adaptiveInterval); err != nil {
+		slog.ErrorContext(ctx, "failed to schedule next fetch", "error", err)
+	}
+}
+
+func (f *Fetcher) storeCacheValidators(ctx context.Context, source Source, previous model.CacheValidators) error {
+	conditional, ok := source.(ConditionalSource)
+	if !ok {
+		return nil
+	}
+
+	validators := conditional.CacheValidators()
+	if validators == previous {
+		return nil
+	}
+
+	return f.sources.SetCacheValidators(ctx, source.ID(), validators)
+}
+
+func (f *Fetcher) processItems(
+	ctx context.Context,
+	source Source,
+	items []model.Item,
+	allowList bool,
+	cycle *fetchCycle,
+) error {
+	var (
+		admitted = make(map[int64]int64)
+		sourceID = strconv.FormatInt(source.ID(), 10)
+	)
+	defer f.recordAdmitted(ctx, admitted)
+
 	for _, item := range items {
 		item.Date = item.Date.UTC()
 
-		if f.itemShouldBeSkipped(item) {
-			log.Printf("[INFO] item %q (%s) from source %q should be skipped", item.Title, item.Link, source.Name())
+		topics, ok := f.admit(ctx, source, item, allowList, cycle)
+		if !ok {
+			metrics.Items.WithLabelValues(sourceID, metrics.ItemSkipped).Inc()
+			continue
+		}
+
+		var (
+			fingerprint = dedup.New(item.Title, item.Summary)
+			article     = model.Article{
+				SourceID:      source.ID(),
+				Title:         item.Title,
+				Link:          item.Link,
+				CanonicalLink: f.canonicalLink(ctx, item.Link),
+				Summary:       item.Summary,
+				Categories:    item.Categories,
+				SimHash:       fingerprint.SimHash,
+				PublishedAt:   item.Date,
+			}
+		)
+
+		originalLink, pending, err := cycle.dedup.claim(ctx, article, fingerprint)
+		if err != nil {
+			return err
+		}
+
+		if originalLink != "" {
+			slog.InfoContext(
+				ctx,
+				"item is a duplicate of stored article",
+				"title", item.Title,
+				"link", item.Link,
+				"original_link", originalLink,
+			)
+
+			if err := f.articles.StoreDuplicate(ctx, originalLink, article); err != nil {
+				return err
+			}
+
+			metrics.Items.WithLabelValues(sourceID, metrics.ItemDuplicate).Inc()
+
 			continue
 		}
 
-		if err := f.articles.Store(ctx, model.Article{
-			SourceID:    source.ID(),
-			Title:       item.Title,
-			Link:        item.Link,
-			Summary:     item.Summary,
-			PublishedAt: item.Date,
-		}); err != nil {
+		stored, err := f.articles.Store(ctx, article)
+		// an article with the same link which already exists is an original for duplicates as well
+		cycle.dedup.resolve(pending, err == nil)
+
+		if err != nil {
 			return err
 		}
+
+		if !stored {
+			metrics.Items.WithLabelValues(sourceID, metrics.ItemExists).Inc()
+			continue
+		}
+
+		metrics.Items.WithLabelValues(sourceID, metrics.ItemStored).Inc()
+
+		for _, topic := range topics {
+			admitted[topic.ID]++
+		}
 	}
 
 	return nil
 }
 
-func (f *Fetcher) itemShouldBeSkipped(item model.Item) bool {
-	categoriesSet := set.New(item.Categories...)
-
-	for _, keyword := range f.filterKeywords {
-		if categoriesSet.Contains(keyword) || strings.Contains(strings.ToLower(item.Title), keyword) {
-			return true
-		}
+func (f *Fetcher) canonicalLink(ctx context.Context, link string) string {
+	if f.config.Links == nil {
+		return canonical.URL(link)
 	}
 
-	return false
+	return f.config.Links.Resolve(ctx, link)
 }
@@ -3,15 +3,22 @@ package fetcher_test
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/defer-panic/news-feed-bot/internal/fetcher"
 	"github.com/defer-panic/news-feed-bot/internal/fetcher/mocks"
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
 	"github.com/defer-panic/news-feed-bot/internal/model"
 )
 
@@ -21,62 +28,793 @@ var feed1 []byte
 //go:embed testdata/feed2.xml
 var feed2 []byte
 
+//go:embed testdata/feed3.xml
+var feed3 []byte
+
 func TestFetcher_Fetch(t *testing.T) {
 	var (
 		source1Server   = setupFeedSever(feed1)
 		source2Server   = setupFeedSever(feed2)
-		sourcesProvider = &mocks.SourcesProviderMock{
-			SourcesFunc: func(ctx context.Context) ([]model.Source, error) {
-				return []model.Source{
-					{
-						ID:       1,
-						Name:     "dev.to",
-						FeedURL:  source1Server.URL,
-						Priority: 10,
-					},
-					{
-						ID:       2,
-						Name:     "Go Time Podcast",
-						FeedURL:  source2Server.URL,
-						Priority: 100,
-					},
-				}, nil
+		sourcesProvider = newSourcesProvider(
+			model.Source{
+				ID:       1,
+				Name:     "dev.to",
+				FeedURL:  source1Server.URL,
+				Priority: 10,
 			},
-		}
+			model.Source{
+				ID:       2,
+				Name:     "Go Time Podcast",
+				FeedURL:  source2Server.URL,
+				Priority: 100,
+			},
+		)
 	)
 
 	t.Run("should fetch articles from all sources", func(t *testing.T) {
 		var (
-			articles       = make(map[string]model.Article)
 			articleStorage = &mocks.ArticleStorageMock{
-				StoreFunc: func(ctx context.Context, article model.Article) error {
-					articles[article.Link] = article
-					return nil
-				},
+				StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
 			}
-			fetcher = fetcher.New(articleStorage, sourcesProvider, 0, nil)
+			fetcher = fetcher.New(
+				articleStorage,
+				sourcesProvider,
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{},
+			)
 		)
 
 		require.NoError(t, fetcher.Fetch(context.Background()))
-		assert.Len(t, articles, 4)
+		assert.Len(t, storedArticles(articleStorage), 4)
 	})
 
 	t.Run("should filter articles by keywords", func(t *testing.T) {
 		var (
-			articles       = make(map[string]model.Article)
 			articleStorage = &mocks.ArticleStorageMock{
-				StoreFunc: func(ctx context.Context, article model.Article) error {
-					articles[article.Link] = article
-					return nil
-				},
+				StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
 			}
 			filterKeywords = []string{"leetcode"}
-			fetcher        = fetcher.New(articleStorage, sourcesProvider, 0, filterKeywords)
+			fetcher        = fetcher.New(
+				articleStorage,
+				sourcesProvider,
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					Filter: fetcher.Filter{Keywords: filterKeywords},
+				},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+		assert.Len(t, storedArticles(articleStorage), 3)
+	})
+
+	t.Run("should filter articles by rules", func(t *testing.T) {
+		var (
+			articleStorage = &mocks.ArticleStorageMock{
+				StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+			}
+			rulesProvider = &mocks.RulesProviderMock{
+				FilterRulesFunc: func(context.Context) ([]model.FilterRule, error) {
+					return []model.FilterRule{
+						{
+							ID:        1,
+							Name:      "no docs on go time",
+							SourceID:  2,
+							Action:    model.FilterActionExclude,
+							Condition: model.FilterCondition{Field: "title", Op: "contains", Value: "docs"},
+						},
+						{
+							ID:        2,
+							Name:      "keep leetcode",
+							Action:    model.FilterActionInclude,
+							Condition: model.FilterCondition{Field: "categories", Op: "regex", Value: "^(?i)leetcode$"},
+						},
+						{
+							ID:        3,
+							Name:      "exclude everything",
+							Action:    model.FilterActionExclude,
+							Condition: model.FilterCondition{Field: "link", Op: "contains", Value: "/"},
+							DryRun:    true,
+						},
+						{
+							ID:        4,
+							Name:      "channel rule",
+							ChannelID: 1,
+							Action:    model.FilterActionExclude,
+							Condition: model.FilterCondition{Field: "link", Op: "contains", Value: "/"},
+						},
+					}, nil
+				},
+			}
+			fetcher = fetcher.New(
+				articleStorage,
+				sourcesProvider,
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					Rules:  rulesProvider,
+					Filter: fetcher.Filter{Keywords: []string{"leetcode"}},
+				},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		stored := storedArticles(articleStorage)
+		assert.Len(t, stored, 3)
+		assert.Contains(t, stored, "https://dev.to/digitebs/climbing-stairs-leetcode-70-4n1j")
+		assert.NotContains(t, stored, "https://changelog.com/gotime/268")
+	})
+
+	t.Run("should store cache validators of modified sources", func(t *testing.T) {
+		var (
+			etagServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Content-Type", "application/xml; charset=utf-8")
+				w.Header().Add("ETag", `"v2"`)
+				_, _ = w.Write(feed1)
+			}))
+			validators      = make(map[int64]model.CacheValidators)
+			sourcesProvider = newSourcesProvider(
+				model.Source{
+					ID:         1,
+					Name:       "dev.to",
+					FeedURL:    etagServer.URL,
+					Validators: model.CacheValidators{ETag: `"v1"`},
+				},
+				model.Source{
+					ID:      2,
+					Name:    "Go Time Podcast",
+					FeedURL: source2Server.URL,
+				},
+			)
+			articleStorage = &mocks.ArticleStorageMock{
+				StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+			}
+			fetcher = fetcher.New(
+				articleStorage,
+				sourcesProvider,
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{},
+			)
 		)
+		defer etagServer.Close()
+
+		sourcesProvider.SetCacheValidatorsFunc = func(ctx context.Context, sourceID int64, v model.CacheValidators) error {
+			validators[sourceID] = v
+			return nil
+		}
 
 		require.NoError(t, fetcher.Fetch(context.Background()))
-		assert.Len(t, articles, 3)
+		assert.Equal(t, map[int64]model.CacheValidators{1: {ETag: `"v2"`}}, validators)
 	})
+
+	t.Run("should skip sources of unknown kind", func(t *testing.T) {
+		var (
+			sourcesProvider = newSourcesProvider(
+				model.Source{
+					ID:       1,
+					Name:     "dev.to",
+					FeedURL:  source1Server.URL,
+					Kind:     "telepathy",
+					Priority: 10,
+				},
+				model.Source{
+					ID:       2,
+					Name:     "Go Time Podcast",
+					FeedURL:  source2Server.URL,
+					Kind:     "rss",
+					Priority: 100,
+				},
+			)
+			articleStorage = &mocks.ArticleStorageMock{
+				StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+			}
+			fetcher = fetcher.New(
+				articleStorage,
+				sourcesProvider,
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		articles := storedArticles(articleStorage)
+		require.Len(t, articles, 2)
+
+		for _, article := range articles {
+			assert.Equal(t, int64(2), article.SourceID)
+		}
+	})
+}
+
+func TestFetcher_Fetch_Schedule(t *testing.T) {
+	var (
+		source1Server   = setupFeedSever(feed1)
+		source2Server   = setupFeedSever(feed2)
+		now             = time.Now()
+		mu              sync.Mutex
+		nextFetches     = make(map[int64]time.Time)
+		sourcesProvider = newSourcesProvider(
+			model.Source{
+				ID:            1,
+				Name:          "dev.to",
+				FeedURL:       source1Server.URL,
+				FetchInterval: time.Hour,
+				NextFetchAt:   now.Add(-time.Minute),
+			},
+			model.Source{
+				ID:          2,
+				Name:        "Go Time Podcast",
+				FeedURL:     source2Server.URL,
+				NextFetchAt: now.Add(time.Minute),
+			},
+		)
+		articleStorage = &mocks.ArticleStorageMock{
+			StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+		}
+		fetcher = fetcher.New(
+			articleStorage,
+			sourcesProvider,
+			fetcher.DefaultRegistry(nil),
+			fetcher.Config{
+				Schedule: fetcher.Schedule{DefaultInterval: 10 * time.Minute},
+			},
+		)
+	)
+
+	sourcesProvider.SetNextFetchFunc = func(ctx context.Context, sourceID int64, next time.Time, _ time.Duration) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		nextFetches[sourceID] = next
+
+		return nil
+	}
+
+	require.NoError(t, fetcher.Fetch(context.Background()))
+	require.Len(t, nextFetches, 1)
+	assert.WithinDuration(t, now.Add(time.Hour), nextFetches[1], 5*time.Second)
+
+	require.NoError(t, fetcher.FetchAll(context.Background()))
+	require.Len(t, nextFetches, 2)
+	assert.WithinDuration(t, now.Add(10*time.Minute), nextFetches[2], 5*time.Second)
+}
+
+func TestFetcher_Fetch_AdaptiveSchedule(t *testing.T) {
+	var (
+		sourceServer    = setupFeedSever(feed1)
+		intervals       = make(map[int64]time.Duration)
+		sourcesProvider = newSourcesProvider(model.Source{
+			ID:            1,
+			Name:          "dev.to",
+			FeedURL:       sourceServer.URL,
+			AdaptiveFetch: true,
+		})
+		articleStorage = &mocks.ArticleStorageMock{
+			StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+		}
+		schedule = fetcher.Schedule{
+			DefaultInterval: 10 * time.Minute,
+			MinInterval:     2 * time.Minute,
+			MaxInterval:     24 * time.Hour,
+		}
+		fetcher = fetcher.New(
+			articleStorage,
+			sourcesProvider,
+			fetcher.DefaultRegistry(nil),
+			fetcher.Config{
+				Schedule: schedule,
+			},
+		)
+	)
+
+	sourcesProvider.SetNextFetchFunc = func(ctx context.Context, sourceID int64, _ time.Time, interval time.Duration) error {
+		intervals[sourceID] = interval
+		return nil
+	}
+
+	require.NoError(t, fetcher.Fetch(context.Background()))
+
+	// items in the feed are years old, so the source is backed off to the max interval.
+	assert.Equal(t, map[int64]time.Duration{1: 24 * time.Hour}, intervals)
+}
+
+func TestFetcher_Fetch_Health(t *testing.T) {
+	var (
+		brokenServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		healthyServer   = setupFeedSever(feed1)
+		disabledServer  = setupFeedSever(feed2)
+		mu              sync.Mutex
+		failures        = make(map[int64]string)
+		successes       = make(map[int64]bool)
+		sourcesProvider = newSourcesProvider(
+			model.Source{ID: 1, Name: "broken", FeedURL: brokenServer.URL},
+			model.Source{ID: 2, Name: "healthy", FeedURL: healthyServer.URL},
+			model.Source{
+				ID:      3,
+				Name:    "disabled",
+				FeedURL: disabledServer.URL,
+				Health:  model.SourceHealth{ConsecutiveFailures: 5, DisabledAt: time.Now()},
+			},
+		)
+		articleStorage = &mocks.ArticleStorageMock{
+			StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+		}
+		fetcher = fetcher.New(
+			articleStorage,
+			sourcesProvider,
+			fetcher.DefaultRegistry(nil),
+			fetcher.Config{
+				MaxFailures: 5,
+			},
+		)
+	)
+	defer brokenServer.Close()
+
+	sourcesProvider.RecordFetchFailureFunc = func(_ context.Context, id int64, err string, disableAfter int) (bool, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, 5, disableAfter)
+		failures[id] = err
+
+		return false, nil
+	}
+	sourcesProvider.RecordFetchSuccessFunc = func(_ context.Context, id int64) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		successes[id] = true
+
+		return nil
+	}
+
+	require.NoError(t, fetcher.Fetch(context.Background()))
+	assert.Equal(t, map[int64]string{1: "unexpected status code: 500"}, failures)
+	assert.Equal(t, map[int64]bool{2: true}, successes)
+}
+
+func TestFetcher_Fetch_Timeout(t *testing.T) {
+	var (
+		release    = make(chan struct{})
+		slowServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		}))
+		failures        = make(map[int64]string)
+		sourcesProvider = newSourcesProvider(model.Source{
+			ID:           1,
+			Name:         "slow",
+			FeedURL:      slowServer.URL,
+			FetchTimeout: 50 * time.Millisecond,
+		})
+		articleStorage = &mocks.ArticleStorageMock{
+			StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+		}
+		fetcher = fetcher.New(
+			articleStorage,
+			sourcesProvider,
+			fetcher.DefaultRegistry(nil),
+			fetcher.Config{
+				Schedule: fetcher.Schedule{DefaultTimeout: time.Minute},
+			},
+		)
+	)
+	defer slowServer.Close()
+	defer close(release)
+
+	sourcesProvider.RecordFetchFailureFunc = func(_ context.Context, id int64, err string, _ int) (bool, error) {
+		failures[id] = err
+		return false, nil
+	}
+
+	started := time.Now()
+
+	require.NoError(t, fetcher.Fetch(context.Background()))
+	assert.Less(t, time.Since(started), time.Second)
+	assert.Contains(t, failures[1], context.DeadlineExceeded.Error())
+}
+
+func TestFetcher_Fetch_Concurrency(t *testing.T) {
+	var (
+		inFlight, maxInFlight atomic.Int32
+		ts                    = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+
+			for {
+				maximum := maxInFlight.Load()
+				if current <= maximum || maxInFlight.CompareAndSwap(maximum, current) {
+					break
+				}
+			}
+
+			time.Sleep(20 * time.Millisecond)
+
+			w.Header().Add("Content-Type", "application/xml; charset=utf-8")
+			_, _ = w.Write(feed1)
+		}))
+		sources = make([]model.Source, 0, 6)
+	)
+	defer ts.Close()
+
+	for i := 1; i <= 6; i++ {
+		sources = append(sources, model.Source{ID: int64(i), Name: "dev.to", FeedURL: ts.URL})
+	}
+
+	for _, tc := range []struct {
+		name        string
+		concurrency fetcher.Concurrency
+		expectedMax int32
+	}{
+		{name: "should limit total concurrency", concurrency: fetcher.Concurrency{Max: 2}, expectedMax: 2},
+		{name: "should limit concurrency per host", concurrency: fetcher.Concurrency{Max: 4, PerHost: 1}, expectedMax: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			maxInFlight.Store(0)
+
+			var (
+				articleStorage = &mocks.ArticleStorageMock{
+					StoreFunc: func(ctx context.Context, article model.Article) (bool, error) { return true, nil },
+				}
+				fetcher = fetcher.New(
+					articleStorage,
+					newSourcesProvider(sources...),
+					fetcher.DefaultRegistry(nil),
+					fetcher.Config{
+						Concurrency: tc.concurrency,
+					},
+				)
+			)
+
+			require.NoError(t, fetcher.Fetch(context.Background()))
+			assert.Equal(t, tc.expectedMax, maxInFlight.Load())
+			assert.Len(t, articleStorage.StoreCalls(), 12)
+		})
+	}
+}
+
+func TestFetcher_Fetch_Duplicates(t *testing.T) {
+	var (
+		goTimeServer = setupFeedSever(feed2)
+		weeklyServer = setupFeedSever(feed3)
+		goTime       = model.Source{ID: 1, Name: "Go Time Podcast", FeedURL: goTimeServer.URL, Priority: 100}
+		weekly       = model.Source{ID: 2, Name: "Gopher Weekly", FeedURL: weeklyServer.URL, Priority: 10}
+	)
+	defer goTimeServer.Close()
+	defer weeklyServer.Close()
+
+	newArticleStorage := func(fingerprints ...model.ArticleFingerprint) *mocks.ArticleStorageMock {
+		return &mocks.ArticleStorageMock{
+			StoreFunc: func(context.Context, model.Article) (bool, error) { return true, nil },
+			RecentFingerprintsFunc: func(context.Context, time.Time) ([]model.ArticleFingerprint, error) {
+				return fingerprints, nil
+			},
+			StoreDuplicateFunc: func(context.Context, string, model.Article) error { return nil },
+		}
+	}
+
+	t.Run("should link duplicates fetched at the same time", func(t *testing.T) {
+		var (
+			articleStorage = newArticleStorage()
+			fetcher        = fetcher.New(
+				articleStorage,
+				newSourcesProvider(goTime, weekly),
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					Concurrency: fetcher.Concurrency{Max: 1},
+					DedupWindow: 48 * time.Hour,
+				},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		stored := storedArticles(articleStorage)
+		assert.Len(t, stored, 3)
+		assert.NotContains(t, stored, "https://gopherweekly.example.com/2023/03/bits-of-go-we-avoid")
+
+		require.Len(t, articleStorage.StoreDuplicateCalls(), 1)
+		duplicate := articleStorage.StoreDuplicateCalls()[0]
+		assert.Equal(t, "https://changelog.com/gotime/269", duplicate.OriginalLink)
+		assert.Equal(t, "https://gopherweekly.example.com/2023/03/bits-of-go-we-avoid", duplicate.Duplicate.Link)
+		assert.Equal(t, weekly.ID, duplicate.Duplicate.SourceID)
+	})
+
+	t.Run("should link duplicates fetched concurrently once the original is stored", func(t *testing.T) {
+		var (
+			mu             sync.Mutex
+			storedLinks    = make(map[string]bool)
+			articleStorage = newArticleStorage()
+			fetcher        = fetcher.New(
+				articleStorage,
+				newSourcesProvider(goTime, weekly),
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					Concurrency: fetcher.Concurrency{Max: 2},
+					DedupWindow: 48 * time.Hour,
+				},
+			)
+		)
+
+		articleStorage.StoreFunc = func(_ context.Context, article model.Article) (bool, error) {
+			// slow storage lets the other source find the duplicate while the original is being stored
+			time.Sleep(20 * time.Millisecond)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			storedLinks[article.CanonicalLink] = true
+
+			return true, nil
+		}
+		articleStorage.StoreDuplicateFunc = func(_ context.Context, originalLink string, _ model.Article) error {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !storedLinks[originalLink] {
+				t.Errorf("duplicate of %s is stored before the original", originalLink)
+			}
+
+			return nil
+		}
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		assert.Len(t, storedArticles(articleStorage), 3)
+		assert.Len(t, articleStorage.StoreDuplicateCalls(), 1)
+	})
+
+	t.Run("should link duplicates of stored articles", func(t *testing.T) {
+		var (
+			articleStorage = newArticleStorage(model.ArticleFingerprint{
+				CanonicalLink: "https://changelog.com/gotime/269",
+				Title:         "The bits of Go we avoid (and why)",
+				SourceID:      goTime.ID,
+			})
+			fetcher = fetcher.New(
+				articleStorage,
+				newSourcesProvider(weekly),
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					DedupWindow: 48 * time.Hour,
+				},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		assert.Len(t, storedArticles(articleStorage), 1)
+		require.Len(t, articleStorage.StoreDuplicateCalls(), 1)
+		assert.Equal(t, "https://changelog.com/gotime/269", articleStorage.StoreDuplicateCalls()[0].OriginalLink)
+	})
+
+	t.Run("should not detect duplicates if disabled", func(t *testing.T) {
+		var (
+			articleStorage = newArticleStorage()
+			fetcher        = fetcher.New(
+				articleStorage,
+				newSourcesProvider(goTime, weekly),
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		assert.Len(t, storedArticles(articleStorage), 4)
+		assert.Empty(t, articleStorage.StoreDuplicateCalls())
+		assert.Empty(t, articleStorage.RecentFingerprintsCalls())
+	})
+}
+
+func TestFetcher_Start_StorageFailure(t *testing.T) {
+	var (
+		server   = setupFeedSever(feed2)
+		failures atomic.Int32
+		stored   = make(chan struct{}, 1)
+	)
+	defer server.Close()
+
+	articleStorage := &mocks.ArticleStorageMock{
+		StoreFunc: func(context.Context, model.Article) (bool, error) {
+			select {
+			case stored <- struct{}{}:
+			default:
+			}
+
+			return true, nil
+		},
+		RecentFingerprintsFunc: func(context.Context, time.Time) ([]model.ArticleFingerprint, error) {
+			// the storage fails once
+			if failures.Add(1) == 1 {
+				return nil, errors.New("connection refused")
+			}
+
+			return nil, nil
+		},
+	}
+
+	fetcher := fetcher.New(
+		articleStorage,
+		newSourcesProvider(model.Source{ID: 1, Name: "Go Time Podcast", FeedURL: server.URL}),
+		fetcher.DefaultRegistry(nil),
+		fetcher.Config{DedupWindow: 48 * time.Hour},
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan error, 1)
+	go func() { started <- fetcher.Start(ctx) }()
+
+	// the next fetch is triggered instead of waiting for the schedule check
+	fetcher.Trigger()
+
+	select {
+	case <-stored:
+	case err := <-started:
+		t.Fatalf("fetcher is stopped: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("articles are not stored after the storage failure")
+	}
+
+	cancel()
+
+	assert.ErrorIs(t, <-started, context.Canceled)
+	assert.Len(t, articleStorage.RecentFingerprintsCalls(), 2)
+}
+
+func TestFetcher_Fetch_CanonicalLinks(t *testing.T) {
+	var (
+		server         = setupFeedSever(feed3)
+		articleStorage = &mocks.ArticleStorageMock{
+			StoreFunc: func(context.Context, model.Article) (bool, error) { return true, nil },
+		}
+		fetcher = fetcher.New(
+			articleStorage,
+			newSourcesProvider(model.Source{ID: 1, Name: "Gopher Weekly", FeedURL: server.URL}),
+			fetcher.DefaultRegistry(nil),
+			fetcher.Config{},
+		)
+	)
+	defer server.Close()
+
+	require.NoError(t, fetcher.Fetch(context.Background()))
+
+	article, ok := storedArticles(articleStorage)["https://gopherweekly.example.com/2023/03/pgo/?utm_source=rss&utm_medium=feed"]
+	require.True(t, ok, "original link should be kept")
+	assert.Equal(t, "https://gopherweekly.example.com/2023/03/pgo", article.CanonicalLink)
+}
+
+func TestFetcher_Fetch_AllowList(t *testing.T) {
+	var (
+		devToServer  = setupFeedSever(feed1)
+		goTimeServer = setupFeedSever(feed2)
+		devTo        = model.Source{ID: 1, Name: "dev.to", FeedURL: devToServer.URL}
+		goTime       = model.Source{ID: 2, Name: "Go Time Podcast", FeedURL: goTimeServer.URL}
+	)
+	defer devToServer.Close()
+	defer goTimeServer.Close()
+
+	newTopicsProvider := func() *mocks.TopicsProviderMock {
+		return &mocks.TopicsProviderMock{
+			TopicsFunc: func(context.Context) ([]model.Topic, error) {
+				return []model.Topic{
+					{ID: 1, Name: "algorithms", Categories: []string{"LeetCode"}},
+					{ID: 2, Name: "docs", SourceID: goTime.ID, Keywords: []string{"docs"}},
+				}, nil
+			},
+			AddAdmittedFunc: func(context.Context, map[int64]int64) error { return nil },
+		}
+	}
+
+	admitted := func(topicsProvider *mocks.TopicsProviderMock) map[int64]int64 {
+		total := make(map[int64]int64)
+
+		for _, call := range topicsProvider.AddAdmittedCalls() {
+			for topicID, count := range call.Admitted {
+				total[topicID] += count
+			}
+		}
+
+		return total
+	}
+
+	t.Run("should store only items matching topics", func(t *testing.T) {
+		var (
+			articleStorage = &mocks.ArticleStorageMock{
+				StoreFunc: func(context.Context, model.Article) (bool, error) { return true, nil },
+			}
+			topicsProvider = newTopicsProvider()
+			fetcher        = fetcher.New(
+				articleStorage,
+				newSourcesProvider(devTo, goTime),
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					Topics: topicsProvider,
+					Filter: fetcher.Filter{AllowList: true},
+				},
+			)
+		)
+
+		docsItems := testutil.ToFloat64(metrics.TopicItems.WithLabelValues("2"))
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		stored := storedArticles(articleStorage)
+		assert.Len(t, stored, 2)
+		assert.Contains(t, stored, "https://dev.to/digitebs/climbing-stairs-leetcode-70-4n1j")
+		assert.Contains(t, stored, "https://changelog.com/gotime/268")
+		assert.Equal(t, map[int64]int64{1: 1, 2: 1}, admitted(topicsProvider))
+		assert.Equal(t, docsItems+1, testutil.ToFloat64(metrics.TopicItems.WithLabelValues("2")))
+	})
+
+	t.Run("should apply allow-list to sources with it enabled", func(t *testing.T) {
+		goTime := goTime
+		goTime.AllowList = true
+
+		var (
+			articleStorage = &mocks.ArticleStorageMock{
+				StoreFunc: func(_ context.Context, article model.Article) (bool, error) {
+					// the leetcode article is already stored, so it's not counted again
+					return !strings.Contains(article.Link, "leetcode"), nil
+				},
+			}
+			topicsProvider = newTopicsProvider()
+			fetcher        = fetcher.New(
+				articleStorage,
+				newSourcesProvider(devTo, goTime),
+				fetcher.DefaultRegistry(nil),
+				fetcher.Config{
+					Topics: topicsProvider,
+				},
+			)
+		)
+
+		require.NoError(t, fetcher.Fetch(context.Background()))
+
+		stored := storedArticles(articleStorage)
+		assert.Len(t, stored, 3)
+		assert.NotContains(t, stored, "https://changelog.com/gotime/269")
+		assert.Equal(t, map[int64]int64{2: 1}, admitted(topicsProvider))
+	})
+}
+
+// newSourcesProvider returns provider of given sources which accepts all state updates.
+func newSourcesProvider(sources ...model.Source) *mocks.SourcesProviderMock {
+	return &mocks.SourcesProviderMock{
+		SourcesFunc: func(ctx context.Context) ([]model.Source, error) {
+			return sources, nil
+		},
+		SetCacheValidatorsFunc: func(context.Context, int64, model.CacheValidators) error {
+			return nil
+		},
+		SetNextFetchFunc: func(context.Context, int64, time.Time, time.Duration) error {
+			return nil
+		},
+		RecordFetchSuccessFunc: func(context.Context, int64) error {
+			return nil
+		},
+		RecordFetchFailureFunc: func(context.Context, int64, string, int) (bool, error) {
+			return false, nil
+		},
+	}
+}
+
+// storedArticles returns articles passed to the storage mock by link.
+func storedArticles(storage *mocks.ArticleStorageMock) map[string]model.Article {
+	articles := make(map[string]model.Article)
+
+	for _, call := range storage.StoreCalls() {
+		articles[call.Article.Link] = call.Article
+	}
+
+	return articles
 }
 
 func setupFeedSever(feed []byte) *httptest.Server {
@@ -0,0 +1,121 @@
+package fetcher
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+// Filter configures which fetched items are stored.
+type Filter struct {
+	// Keywords skip items with any of them in title or categories, see rules.KeywordsRule.
+	Keywords []string
+	// AllowList makes all sources store only items matching topics, see model.Topic.
+	AllowList bool
+}
+
+// admit decides whether the item is stored: filter rules are checked first, and if none
+// of them matched, in allow-list mode the item must match a topic. Returns topics the item matched.
+func (f *Fetcher) admit(
+	ctx context.Context,
+	source Source,
+	item model.Item,
+	allowList bool,
+	cycle *fetchCycle,
+) ([]model.Topic, bool) {
+	decision := cycle.rules.Evaluate(source.ID(), item)
+
+	for _, rule := range decision.DryRun {
+		slog.InfoContext(
+			ctx,
+			"dry-run rule would apply to item",
+			"rule_id", rule.ID,
+			"rule", rule.Name,
+			"action", rule.Action,
+			"title", item.Title,
+			"link", item.Link,
+		)
+	}
+
+	if decision.Skip() {
+		slog.InfoContext(
+			ctx,
+			"item is skipped by rule",
+			"rule_id", decision.Rule.ID,
+			"rule", decision.Rule.Name,
+			"title", item.Title,
+			"link", item.Link,
+		)
+
+		return nil, false
+	}
+
+	topics := cycle.topics.Match(source.ID(), item)
+
+	if allowList && decision.Rule == nil && len(topics) == 0 {
+		slog.InfoContext(ctx, "item doesn't match any topic", "title", item.Title, "link", item.Link)
+		return nil, false
+	}
+
+	return topics, true
+}
+
+func (f *Fetcher) ruleSet(ctx context.Context) (*rules.Set, error) {
+	var filterRules []model.FilterRule
+
+	if f.config.Rules != nil {
+		var err error
+
+		if filterRules, err = f.config.Rules.FilterRules(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// rules of channels select articles to post, they don't affect what is stored
+	filterRules = lo.Filter(filterRules, func(rule model.FilterRule, _ int) bool { return rule.ChannelID == 0 })
+
+	ruleSet, err := rules.Build(filterRules, f.config.Filter.Keywords)
+	if err != nil {
+		slog.ErrorContext(ctx, "some filter rules are invalid and ignored", "error", err)
+	}
+
+	return ruleSet, nil
+}
+
+func (f *Fetcher) topicSet(ctx context.Context) (*rules.Topics, error) {
+	if f.config.Topics == nil {
+		return nil, nil
+	}
+
+	topics, err := f.config.Topics.Topics(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	topicSet, err := rules.BuildTopics(topics)
+	if err != nil {
+		slog.ErrorContext(ctx, "some topics are invalid and ignored", "error", err)
+	}
+
+	return topicSet, nil
+}
+
+func (f *Fetcher) recordAdmitted(ctx context.Context, admitted map[int64]int64) {
+	if f.config.Topics == nil || len(admitted) == 0 {
+		return
+	}
+
+	for topicID, count := range admitted {
+		metrics.TopicItems.WithLabelValues(strconv.FormatInt(topicID, 10)).Add(float64(count))
+	}
+
+	if err := f.config.Topics.AddAdmitted(ctx, admitted); err != nil {
+		slog.ErrorContext(ctx, "failed to record items admitted by topics", "error", err)
+	}
+}
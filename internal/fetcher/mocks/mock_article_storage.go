@@ -8,6 +8,7 @@ import (
 	"github.com/defer-panic/news-feed-bot/internal/fetcher"
 	"github.com/defer-panic/news-feed-bot/internal/model"
 	"sync"
+	"time"
 )
 
 // Ensure, that ArticleStorageMock does implement fetcher.ArticleStorage.
@@ -20,9 +21,15 @@ var _ fetcher.ArticleStorage = &ArticleStorageMock{}
 //
 //		// make and configure a mocked fetcher.ArticleStorage
 //		mockedArticleStorage := &ArticleStorageMock{
-//			StoreFunc: func(ctx context.Context, article model.Article) error {
+//			RecentFingerprintsFunc: func(ctx context.Context, since time.Time) ([]model.ArticleFingerprint, error) {
+//				panic("mock out the RecentFingerprints method")
+//			},
+//			StoreFunc: func(ctx context.Context, article model.Article) (bool, error) {
 //				panic("mock out the Store method")
 //			},
+//			StoreDuplicateFunc: func(ctx context.Context, originalLink string, duplicate model.Article) error {
+//				panic("mock out the StoreDuplicate method")
+//			},
 //		}
 //
 //		// use mockedArticleStorage in code that requires fetcher.ArticleStorage
@@ -30,11 +37,24 @@ var _ fetcher.ArticleStorage = &ArticleStorageMock{}
 //
 //	}
 type ArticleStorageMock struct {
+	// RecentFingerprintsFunc mocks the RecentFingerprints method.
+	RecentFingerprintsFunc func(ctx context.Context, since time.Time) ([]model.ArticleFingerprint, error)
+
 	// StoreFunc mocks the Store method.
-	StoreFunc func(ctx context.Context, article model.Article) error
+	StoreFunc func(ctx context.Context, article model.Article) (bool, error)
+
+	// StoreDuplicateFunc mocks the StoreDuplicate method.
+	StoreDuplicateFunc func(ctx context.Context, originalLink string, duplicate model.Article) error
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// RecentFingerprints holds details about calls to the RecentFingerprints method.
+		RecentFingerprints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Since is the since argument value.
+			Since time.Time
+		}
 		// Store holds details about calls to the Store method.
 		Store []struct {
 			// Ctx is the ctx argument value.
@@ -42,12 +62,59 @@ type ArticleStorageMock struct {
 			// Article is the article argument value.
 			Article model.Article
 		}
+		// StoreDuplicate holds details about calls to the StoreDuplicate method.
+		StoreDuplicate []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// OriginalLink is the originalLink argument value.
+			OriginalLink string
+			// Duplicate is the duplicate argument value.
+			Duplicate model.Article
+		}
+	}
+	lockRecentFingerprints sync.RWMutex
+	lockStore              sync.RWMutex
+	lockStoreDuplicate     sync.RWMutex
+}
+
+// RecentFingerprints calls RecentFingerprintsFunc.
+func (mock *ArticleStorageMock) RecentFingerprints(ctx context.Context, since time.Time) ([]model.ArticleFingerprint, error) {
+	if mock.RecentFingerprintsFunc == nil {
+		panic("ArticleStorageMock.RecentFingerprintsFunc: method is nil but ArticleStorage.RecentFingerprints was just called")
+	}
+	callInfo := struct {
+		Ctx   context.Context
+		Since time.Time
+	}{
+		Ctx:   ctx,
+		Since: since,
+	}
+	mock.lockRecentFingerprints.Lock()
+	mock.calls.RecentFingerprints = append(mock.calls.RecentFingerprints, callInfo)
+	mock.lockRecentFingerprints.Unlock()
+	return mock.RecentFingerprintsFunc(ctx, since)
+}
+
+// RecentFingerprintsCalls gets all the calls that were made to RecentFingerprints.
+// Check the length with:
+//
+//	len(mockedArticleStorage.RecentFingerprintsCalls())
+func (mock *ArticleStorageMock) RecentFingerprintsCalls() []struct {
+	Ctx   context.Context
+	Since time.Time
+} {
+	var calls []struct {
+		Ctx   context.Context
+		Since time.Time
 	}
-	lockStore sync.RWMutex
+	mock.lockRecentFingerprints.RLock()
+	calls = mock.calls.RecentFingerprints
+	mock.lockRecentFingerprints.RUnlock()
+	return calls
 }
 
 // Store calls StoreFunc.
-func (mock *ArticleStorageMock) Store(ctx context.Context, article model.Article) error {
+func (mock *ArticleStorageMock) Store(ctx context.Context, article model.Article) (bool, error) {
 	if mock.StoreFunc == nil {
 		panic("ArticleStorageMock.StoreFunc: method is nil but ArticleStorage.Store was just called")
 	}
@@ -81,3 +148,43 @@ func (mock *ArticleStorageMock) StoreCalls() []struct {
 	mock.lockStore.RUnlock()
 	return calls
 }
+
+// StoreDuplicate calls StoreDuplicateFunc.
+func (mock *ArticleStorageMock) StoreDuplicate(ctx context.Context, originalLink string, duplicate model.Article) error {
+	if mock.StoreDuplicateFunc == nil {
+		panic("ArticleStorageMock.StoreDuplicateFunc: method is nil but ArticleStorage.StoreDuplicate was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		OriginalLink string
+		Duplicate    model.Article
+	}{
+		Ctx:          ctx,
+		OriginalLink: originalLink,
+		Duplicate:    duplicate,
+	}
+	mock.lockStoreDuplicate.Lock()
+	mock.calls.StoreDuplicate = append(mock.calls.StoreDuplicate, callInfo)
+	mock.lockStoreDuplicate.Unlock()
+	return mock.StoreDuplicateFunc(ctx, originalLink, duplicate)
+}
+
+// StoreDuplicateCalls gets all the calls that were made to StoreDuplicate.
+// Check the length with:
+//
+//	len(mockedArticleStorage.StoreDuplicateCalls())
+func (mock *ArticleStorageMock) StoreDuplicateCalls() []struct {
+	Ctx          context.Context
+	OriginalLink string
+	Duplicate    model.Article
+} {
+	var calls []struct {
+		Ctx          context.Context
+		OriginalLink string
+		Duplicate    model.Article
+	}
+	mock.lockStoreDuplicate.RLock()
+	calls = mock.calls.StoreDuplicate
+	mock.lockStoreDuplicate.RUnlock()
+	return calls
+}
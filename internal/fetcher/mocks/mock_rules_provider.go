@@ -0,0 +1,77 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/defer-panic/news-feed-bot/internal/fetcher"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"sync"
+)
+
+// Ensure, that RulesProviderMock does implement fetcher.RulesProvider.
+// If this is not the case, regenerate this file with moq.
+var _ fetcher.RulesProvider = &RulesProviderMock{}
+
+// RulesProviderMock is a mock implementation of fetcher.RulesProvider.
+//
+//	func TestSomethingThatUsesRulesProvider(t *testing.T) {
+//
+//		// make and configure a mocked fetcher.RulesProvider
+//		mockedRulesProvider := &RulesProviderMock{
+//			FilterRulesFunc: func(ctx context.Context) ([]model.FilterRule, error) {
+//				panic("mock out the FilterRules method")
+//			},
+//		}
+//
+//		// use mockedRulesProvider in code that requires fetcher.RulesProvider
+//		// and then make assertions.
+//
+//	}
+type RulesProviderMock struct {
+	// FilterRulesFunc mocks the FilterRules method.
+	FilterRulesFunc func(ctx context.Context) ([]model.FilterRule, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// FilterRules holds details about calls to the FilterRules method.
+		FilterRules []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockFilterRules sync.RWMutex
+}
+
+// FilterRules calls FilterRulesFunc.
+func (mock *RulesProviderMock) FilterRules(ctx context.Context) ([]model.FilterRule, error) {
+	if mock.FilterRulesFunc == nil {
+		panic("RulesProviderMock.FilterRulesFunc: method is nil but RulesProvider.FilterRules was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockFilterRules.Lock()
+	mock.calls.FilterRules = append(mock.calls.FilterRules, callInfo)
+	mock.lockFilterRules.Unlock()
+	return mock.FilterRulesFunc(ctx)
+}
+
+// FilterRulesCalls gets all the calls that were made to FilterRules.
+// Check the length with:
+//
+//	len(mockedRulesProvider.FilterRulesCalls())
+func (mock *RulesProviderMock) FilterRulesCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockFilterRules.RLock()
+	calls = mock.calls.FilterRules
+	mock.lockFilterRules.RUnlock()
+	return calls
+}
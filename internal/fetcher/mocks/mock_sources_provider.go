@@ -8,6 +8,7 @@ import (
 	"github.com/defer-panic/news-feed-bot/internal/fetcher"
 	"github.com/defer-panic/news-feed-bot/internal/model"
 	"sync"
+	"time"
 )
 
 // Ensure, that SourcesProviderMock does implement fetcher.SourcesProvider.
@@ -20,6 +21,18 @@ var _ fetcher.SourcesProvider = &SourcesProviderMock{}
 //
 //		// make and configure a mocked fetcher.SourcesProvider
 //		mockedSourcesProvider := &SourcesProviderMock{
+//			RecordFetchFailureFunc: func(ctx context.Context, sourceID int64, fetchErr string, disableAfter int) (bool, error) {
+//				panic("mock out the RecordFetchFailure method")
+//			},
+//			RecordFetchSuccessFunc: func(ctx context.Context, sourceID int64) error {
+//				panic("mock out the RecordFetchSuccess method")
+//			},
+//			SetCacheValidatorsFunc: func(ctx context.Context, sourceID int64, validators model.CacheValidators) error {
+//				panic("mock out the SetCacheValidators method")
+//			},
+//			SetNextFetchFunc: func(ctx context.Context, sourceID int64, nextFetchAt time.Time, adaptiveInterval time.Duration) error {
+//				panic("mock out the SetNextFetch method")
+//			},
 //			SourcesFunc: func(ctx context.Context) ([]model.Source, error) {
 //				panic("mock out the Sources method")
 //			},
@@ -30,18 +43,236 @@ var _ fetcher.SourcesProvider = &SourcesProviderMock{}
 //
 //	}
 type SourcesProviderMock struct {
+	// RecordFetchFailureFunc mocks the RecordFetchFailure method.
+	RecordFetchFailureFunc func(ctx context.Context, sourceID int64, fetchErr string, disableAfter int) (bool, error)
+
+	// RecordFetchSuccessFunc mocks the RecordFetchSuccess method.
+	RecordFetchSuccessFunc func(ctx context.Context, sourceID int64) error
+
+	// SetCacheValidatorsFunc mocks the SetCacheValidators method.
+	SetCacheValidatorsFunc func(ctx context.Context, sourceID int64, validators model.CacheValidators) error
+
+	// SetNextFetchFunc mocks the SetNextFetch method.
+	SetNextFetchFunc func(ctx context.Context, sourceID int64, nextFetchAt time.Time, adaptiveInterval time.Duration) error
+
 	// SourcesFunc mocks the Sources method.
 	SourcesFunc func(ctx context.Context) ([]model.Source, error)
 
 	// calls tracks calls to the methods.
 	calls struct {
+		// RecordFetchFailure holds details about calls to the RecordFetchFailure method.
+		RecordFetchFailure []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// SourceID is the sourceID argument value.
+			SourceID int64
+			// FetchErr is the fetchErr argument value.
+			FetchErr string
+			// DisableAfter is the disableAfter argument value.
+			DisableAfter int
+		}
+		// RecordFetchSuccess holds details about calls to the RecordFetchSuccess method.
+		RecordFetchSuccess []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// SourceID is the sourceID argument value.
+			SourceID int64
+		}
+		// SetCacheValidators holds details about calls to the SetCacheValidators method.
+		SetCacheValidators []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// SourceID is the sourceID argument value.
+			SourceID int64
+			// Validators is the validators argument value.
+			Validators model.CacheValidators
+		}
+		// SetNextFetch holds details about calls to the SetNextFetch method.
+		SetNextFetch []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// SourceID is the sourceID argument value.
+			SourceID int64
+			// NextFetchAt is the nextFetchAt argument value.
+			NextFetchAt time.Time
+			// AdaptiveInterval is the adaptiveInterval argument value.
+			AdaptiveInterval time.Duration
+		}
 		// Sources holds details about calls to the Sources method.
 		Sources []struct {
 			// Ctx is the ctx argument value.
 			Ctx context.Context
 		}
 	}
-	lockSources sync.RWMutex
+	lockRecordFetchFailure sync.RWMutex
+	lockRecordFetchSuccess sync.RWMutex
+	lockSetCacheValidators sync.RWMutex
+	lockSetNextFetch       sync.RWMutex
+	lockSources            sync.RWMutex
+}
+
+// RecordFetchFailure calls RecordFetchFailureFunc.
+func (mock *SourcesProviderMock) RecordFetchFailure(ctx context.Context, sourceID int64, fetchErr string, disableAfter int) (bool, error) {
+	if mock.RecordFetchFailureFunc == nil {
+		panic("SourcesProviderMock.RecordFetchFailureFunc: method is nil but SourcesProvider.RecordFetchFailure was just called")
+	}
+	callInfo := struct {
+		Ctx          context.Context
+		SourceID     int64
+		FetchErr     string
+		DisableAfter int
+	}{
+		Ctx:          ctx,
+		SourceID:     sourceID,
+		FetchErr:     fetchErr,
+		DisableAfter: disableAfter,
+	}
+	mock.lockRecordFetchFailure.Lock()
+	mock.calls.RecordFetchFailure = append(mock.calls.RecordFetchFailure, callInfo)
+	mock.lockRecordFetchFailure.Unlock()
+	return mock.RecordFetchFailureFunc(ctx, sourceID, fetchErr, disableAfter)
+}
+
+// RecordFetchFailureCalls gets all the calls that were made to RecordFetchFailure.
+// Check the length with:
+//
+//	len(mockedSourcesProvider.RecordFetchFailureCalls())
+func (mock *SourcesProviderMock) RecordFetchFailureCalls() []struct {
+	Ctx          context.Context
+	SourceID     int64
+	FetchErr     string
+	DisableAfter int
+} {
+	var calls []struct {
+		Ctx          context.Context
+		SourceID     int64
+		FetchErr     string
+		DisableAfter int
+	}
+	mock.lockRecordFetchFailure.RLock()
+	calls = mock.calls.RecordFetchFailure
+	mock.lockRecordFetchFailure.RUnlock()
+	return calls
+}
+
+// RecordFetchSuccess calls RecordFetchSuccessFunc.
+func (mock *SourcesProviderMock) RecordFetchSuccess(ctx context.Context, sourceID int64) error {
+	if mock.RecordFetchSuccessFunc == nil {
+		panic("SourcesProviderMock.RecordFetchSuccessFunc: method is nil but SourcesProvider.RecordFetchSuccess was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		SourceID int64
+	}{
+		Ctx:      ctx,
+		SourceID: sourceID,
+	}
+	mock.lockRecordFetchSuccess.Lock()
+	mock.calls.RecordFetchSuccess = append(mock.calls.RecordFetchSuccess, callInfo)
+	mock.lockRecordFetchSuccess.Unlock()
+	return mock.RecordFetchSuccessFunc(ctx, sourceID)
+}
+
+// RecordFetchSuccessCalls gets all the calls that were made to RecordFetchSuccess.
+// Check the length with:
+//
+//	len(mockedSourcesProvider.RecordFetchSuccessCalls())
+func (mock *SourcesProviderMock) RecordFetchSuccessCalls() []struct {
+	Ctx      context.Context
+	SourceID int64
+} {
+	var calls []struct {
+		Ctx      context.Context
+		SourceID int64
+	}
+	mock.lockRecordFetchSuccess.RLock()
+	calls = mock.calls.RecordFetchSuccess
+	mock.lockRecordFetchSuccess.RUnlock()
+	return calls
+}
+
+// SetCacheValidators calls SetCacheValidatorsFunc.
+func (mock *SourcesProviderMock) SetCacheValidators(ctx context.Context, sourceID int64, validators model.CacheValidators) error {
+	if mock.SetCacheValidatorsFunc == nil {
+		panic("SourcesProviderMock.SetCacheValidatorsFunc: method is nil but SourcesProvider.SetCacheValidators was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		SourceID   int64
+		Validators model.CacheValidators
+	}{
+		Ctx:        ctx,
+		SourceID:   sourceID,
+		Validators: validators,
+	}
+	mock.lockSetCacheValidators.Lock()
+	mock.calls.SetCacheValidators = append(mock.calls.SetCacheValidators, callInfo)
+	mock.lockSetCacheValidators.Unlock()
+	return mock.SetCacheValidatorsFunc(ctx, sourceID, validators)
+}
+
+// SetCacheValidatorsCalls gets all the calls that were made to SetCacheValidators.
+// Check the length with:
+//
+//	len(mockedSourcesProvider.SetCacheValidatorsCalls())
+func (mock *SourcesProviderMock) SetCacheValidatorsCalls() []struct {
+	Ctx        context.Context
+	SourceID   int64
+	Validators model.CacheValidators
+} {
+	var calls []struct {
+		Ctx        context.Context
+		SourceID   int64
+		Validators model.CacheValidators
+	}
+	mock.lockSetCacheValidators.RLock()
+	calls = mock.calls.SetCacheValidators
+	mock.lockSetCacheValidators.RUnlock()
+	return calls
+}
+
+// SetNextFetch calls SetNextFetchFunc.
+func (mock *SourcesProviderMock) SetNextFetch(ctx context.Context, sourceID int64, nextFetchAt time.Time, adaptiveInterval time.Duration) error {
+	if mock.SetNextFetchFunc == nil {
+		panic("SourcesProviderMock.SetNextFetchFunc: method is nil but SourcesProvider.SetNextFetch was just called")
+	}
+	callInfo := struct {
+		Ctx              context.Context
+		SourceID         int64
+		NextFetchAt      time.Time
+		AdaptiveInterval time.Duration
+	}{
+		Ctx:              ctx,
+		SourceID:         sourceID,
+		NextFetchAt:      nextFetchAt,
+		AdaptiveInterval: adaptiveInterval,
+	}
+	mock.lockSetNextFetch.Lock()
+	mock.calls.SetNextFetch = append(mock.calls.SetNextFetch, callInfo)
+	mock.lockSetNextFetch.Unlock()
+	return mock.SetNextFetchFunc(ctx, sourceID, nextFetchAt, adaptiveInterval)
+}
+
+// SetNextFetchCalls gets all the calls that were made to SetNextFetch.
+// Check the length with:
+//
+//	len(mockedSourcesProvider.SetNextFetchCalls())
+func (mock *SourcesProviderMock) SetNextFetchCalls() []struct {
+	Ctx              context.Context
+	SourceID         int64
+	NextFetchAt      time.Time
+	AdaptiveInterval time.Duration
+} {
+	var calls []struct {
+		Ctx              context.Context
+		SourceID         int64
+		NextFetchAt      time.Time
+		AdaptiveInterval time.Duration
+	}
+	mock.lockSetNextFetch.RLock()
+	calls = mock.calls.SetNextFetch
+	mock.lockSetNextFetch.RUnlock()
+	return calls
 }
 
 // Sources calls SourcesFunc.
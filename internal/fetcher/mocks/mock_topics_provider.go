@@ -0,0 +1,127 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mocks
+
+import (
+	"context"
+	"github.com/defer-panic/news-feed-bot/internal/fetcher"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"sync"
+)
+
+// Ensure, that TopicsProviderMock does implement fetcher.TopicsProvider.
+// If this is not the case, regenerate this file with moq.
+var _ fetcher.TopicsProvider = &TopicsProviderMock{}
+
+// TopicsProviderMock is a mock implementation of fetcher.TopicsProvider.
+//
+//	func TestSomethingThatUsesTopicsProvider(t *testing.T) {
+//
+//		// make and configure a mocked fetcher.TopicsProvider
+//		mockedTopicsProvider := &TopicsProviderMock{
+//			AddAdmittedFunc: func(ctx context.Context, admitted map[int64]int64) error {
+//				panic("mock out the AddAdmitted method")
+//			},
+//			TopicsFunc: func(ctx context.Context) ([]model.Topic, error) {
+//				panic("mock out the Topics method")
+//			},
+//		}
+//
+//		// use mockedTopicsProvider in code that requires fetcher.TopicsProvider
+//		// and then make assertions.
+//
+//	}
+type TopicsProviderMock struct {
+	// AddAdmittedFunc mocks the AddAdmitted method.
+	AddAdmittedFunc func(ctx context.Context, admitted map[int64]int64) error
+
+	// TopicsFunc mocks the Topics method.
+	TopicsFunc func(ctx context.Context) ([]model.Topic, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// AddAdmitted holds details about calls to the AddAdmitted method.
+		AddAdmitted []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Admitted is the admitted argument value.
+			Admitted map[int64]int64
+		}
+		// Topics holds details about calls to the Topics method.
+		Topics []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+		}
+	}
+	lockAddAdmitted sync.RWMutex
+	lockTopics      sync.RWMutex
+}
+
+// AddAdmitted calls AddAdmittedFunc.
+func (mock *TopicsProviderMock) AddAdmitted(ctx context.Context, admitted map[int64]int64) error {
+	if mock.AddAdmittedFunc == nil {
+		panic("TopicsProviderMock.AddAdmittedFunc: method is nil but TopicsProvider.AddAdmitted was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		Admitted map[int64]int64
+	}{
+		Ctx:      ctx,
+		Admitted: admitted,
+	}
+	mock.lockAddAdmitted.Lock()
+	mock.calls.AddAdmitted = append(mock.calls.AddAdmitted, callInfo)
+	mock.lockAddAdmitted.Unlock()
+	return mock.AddAdmittedFunc(ctx, admitted)
+}
+
+// AddAdmittedCalls gets all the calls that were made to AddAdmitted.
+// Check the length with:
+//
+//	len(mockedTopicsProvider.AddAdmittedCalls())
+func (mock *TopicsProviderMock) AddAdmittedCalls() []struct {
+	Ctx      context.Context
+	Admitted map[int64]int64
+} {
+	var calls []struct {
+		Ctx      context.Context
+		Admitted map[int64]int64
+	}
+	mock.lockAddAdmitted.RLock()
+	calls = mock.calls.AddAdmitted
+	mock.lockAddAdmitted.RUnlock()
+	return calls
+}
+
+// Topics calls TopicsFunc.
+func (mock *TopicsProviderMock) Topics(ctx context.Context) ([]model.Topic, error) {
+	if mock.TopicsFunc == nil {
+		panic("TopicsProviderMock.TopicsFunc: method is nil but TopicsProvider.Topics was just called")
+	}
+	callInfo := struct {
+		Ctx context.Context
+	}{
+		Ctx: ctx,
+	}
+	mock.lockTopics.Lock()
+	mock.calls.Topics = append(mock.calls.Topics, callInfo)
+	mock.lockTopics.Unlock()
+	return mock.TopicsFunc(ctx)
+}
+
+// TopicsCalls gets all the calls that were made to Topics.
+// Check the length with:
+//
+//	len(mockedTopicsProvider.TopicsCalls())
+func (mock *TopicsProviderMock) TopicsCalls() []struct {
+	Ctx context.Context
+} {
+	var calls []struct {
+		Ctx context.Context
+	}
+	mock.lockTopics.RLock()
+	calls = mock.calls.Topics
+	mock.lockTopics.RUnlock()
+	return calls
+}
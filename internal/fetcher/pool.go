@@ -0,0 +1,150 @@
+package fetcher
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// Concurrency limits how many sources are fetched at the same time.
+type Concurrency struct {
+	// Max is the maximal number of sources fetched at the same time, zero means no limit.
+	Max int
+	// PerHost is the maximal number of sources on the same host fetched at the same time, zero means no limit.
+	PerHost int
+}
+
+type fetchJob struct {
+	source Source
+	model  model.Source
+	host   string
+}
+
+// runPool runs fn for every job using at most Concurrency.Max workers,
+// making sure that no more than Concurrency.PerHost jobs of the same host run simultaneously.
+func (c Concurrency) runPool(ctx context.Context, jobs []fetchJob, fn func(job fetchJob)) {
+	workers := c.Max
+	if workers <= 0 || workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		hosts   = newHostLimiter(c.PerHost)
+		jobsCh  = make(chan fetchJob)
+		ordered = interleaveByHost(jobs)
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for job := range jobsCh {
+				if !hosts.acquire(ctx, job.host) {
+					continue
+				}
+
+				fn(job)
+				hosts.release(job.host)
+			}
+		}()
+	}
+
+	for _, job := range ordered {
+		jobsCh <- job
+	}
+
+	close(jobsCh)
+	wg.Wait()
+}
+
+// interleaveByHost orders jobs round-robin by host, so workers are not stuck
+// waiting for the same host while jobs of other hosts could be run.
+func interleaveByHost(jobs []fetchJob) []fetchJob {
+	var (
+		hosts  []string
+		byHost = make(map[string][]fetchJob)
+	)
+
+	for _, job := range jobs {
+		if _, ok := byHost[job.host]; !ok {
+			hosts = append(hosts, job.host)
+		}
+
+		byHost[job.host] = append(byHost[job.host], job)
+	}
+
+	ordered := make([]fetchJob, 0, len(jobs))
+
+	for len(ordered) < len(jobs) {
+		for _, host := range hosts {
+			if len(byHost[host]) == 0 {
+				continue
+			}
+
+			ordered = append(ordered, byHost[host][0])
+			byHost[host] = byHost[host][1:]
+		}
+	}
+
+	return ordered
+}
+
+type hostLimiter struct {
+	limit int
+	mu    sync.Mutex
+	sems  map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{limit: limit, sems: make(map[string]chan struct{})}
+}
+
+func (l *hostLimiter) sem(host string) chan struct{} {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	sem, ok := l.sems[host]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.sems[host] = sem
+	}
+
+	return sem
+}
+
+// acquire blocks until a slot for the host is available. Returns false if ctx is done.
+func (l *hostLimiter) acquire(ctx context.Context, host string) bool {
+	if l.limit <= 0 {
+		return ctx.Err() == nil
+	}
+
+	select {
+	case l.sem(host) <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func (l *hostLimiter) release(host string) {
+	if l.limit <= 0 {
+		return
+	}
+
+	<-l.sem(host)
+}
+
+func hostOf(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+
+	return strings.ToLower(u.Hostname())
+}
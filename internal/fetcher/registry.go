@@ -0,0 +1,107 @@
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	src "github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+var ErrUnknownSourceKind = errors.New("unknown source kind")
+
+// SourceFactory creates a Source for the stored source model.
+type SourceFactory func(m model.Source) (Source, error)
+
+// Registry maps source kinds to factories, so new kinds of sources
+// can be added without touching the fetch loop.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]SourceFactory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]SourceFactory)}
+}
+
+// DefaultRegistry returns a registry with all source kinds supported out of the box.
+// Sources make HTTP requests with the given client, nil means the default one.
+func DefaultRegistry(client *src.HTTPClient) *Registry {
+	r := NewRegistry()
+
+	rssFactory := func(m model.Source) (Source, error) {
+		source := src.NewRSSSourceFromModel(m)
+		source.Client = client
+
+		return source, nil
+	}
+
+	// RSS parser handles Atom feeds as well.
+	r.Register(model.SourceKindRSS, rssFactory)
+	r.Register(model.SourceKindAtom, rssFactory)
+	r.Register(model.SourceKindJSONFeed, func(m model.Source) (Source, error) {
+		source := src.NewJSONFeedSourceFromModel(m)
+		source.Client = client
+
+		return source, nil
+	})
+	r.Register(model.SourceKindHTMLScrape, func(m model.Source) (Source, error) {
+		source := src.NewHTMLScrapeSourceFromModel(m)
+		source.Client = client
+
+		return source, nil
+	})
+
+	return r
+}
+
+func (r *Registry) Register(kind string, factory SourceFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[kind] = factory
+}
+
+func (r *Registry) Supports(kind string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.factories[kind]
+
+	return ok
+}
+
+// Kinds returns sorted list of registered source kinds.
+func (r *Registry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	kinds := make([]string, 0, len(r.factories))
+	for kind := range r.factories {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// New creates a Source for the given model. Sources without kind are treated as RSS.
+func (r *Registry) New(m model.Source) (Source, error) {
+	kind := m.Kind
+	if kind == "" {
+		kind = model.SourceKindRSS
+	}
+
+	r.mu.RLock()
+	factory, ok := r.factories[kind]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownSourceKind, kind)
+	}
+
+	return factory(m)
+}
@@ -0,0 +1,100 @@
+package fetcher
+
+import (
+	"sort"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// scheduleCheckInterval is how often fetcher looks for sources which are due to be fetched.
+const scheduleCheckInterval = 30 * time.Second
+
+// adaptiveWindow is how many latest items are used to estimate publishing rate of a source.
+const adaptiveWindow = 10
+
+// Schedule configures how often sources are fetched and how long a single fetch may take.
+type Schedule struct {
+	// DefaultInterval is used for sources without own fetch interval.
+	DefaultInterval time.Duration
+	// MinInterval and MaxInterval bound intervals of sources with adaptive polling.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// DefaultTimeout limits a single fetch of sources without own timeout, zero means no limit.
+	DefaultTimeout time.Duration
+}
+
+func (s Schedule) timeout(source model.Source) time.Duration {
+	if source.FetchTimeout > 0 {
+		return source.FetchTimeout
+	}
+
+	return s.DefaultTimeout
+}
+
+func (s Schedule) isDue(source model.Source, now time.Time) bool {
+	return source.NextFetchAt.IsZero() || !source.NextFetchAt.After(now)
+}
+
+// nextInterval returns interval until the next fetch of the source. For adaptive sources
+// it's the average time between publications of the latest items, so busy sources
+// are polled more often, and silent ones are backed off.
+func (s Schedule) nextInterval(source model.Source, items []model.Item, now time.Time) time.Duration {
+	interval := source.FetchInterval
+	if interval <= 0 {
+		interval = s.DefaultInterval
+	}
+
+	if !source.AdaptiveFetch {
+		return interval
+	}
+
+	if estimated, ok := estimateArrivalInterval(items, now); ok {
+		interval = estimated
+	} else if source.AdaptiveInterval > 0 {
+		// nothing to learn from (e.g. feed is not modified), slowly back off:
+		interval = source.AdaptiveInterval * 3 / 2
+	}
+
+	return s.clamp(interval)
+}
+
+func (s Schedule) clamp(interval time.Duration) time.Duration {
+	if s.MinInterval > 0 && interval < s.MinInterval {
+		return s.MinInterval
+	}
+
+	if s.MaxInterval > 0 && interval > s.MaxInterval {
+		return s.MaxInterval
+	}
+
+	return interval
+}
+
+func estimateArrivalInterval(items []model.Item, now time.Time) (time.Duration, bool) {
+	dates := make([]time.Time, 0, len(items))
+
+	for _, item := range items {
+		if item.Date.IsZero() || item.Date.After(now) {
+			continue
+		}
+
+		dates = append(dates, item.Date)
+	}
+
+	if len(dates) < 2 {
+		return 0, false
+	}
+
+	sort.Slice(dates, func(i, j int) bool { return dates[i].After(dates[j]) })
+
+	if len(dates) > adaptiveWindow {
+		dates = dates[:adaptiveWindow]
+	}
+
+	// time since the oldest of the latest items includes the gap since the newest one,
+	// so the estimate grows while the source stays silent.
+	oldest := dates[len(dates)-1]
+
+	return now.Sub(oldest) / time.Duration(len(dates)), true
+}
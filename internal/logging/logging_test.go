@@ -0,0 +1,74 @@
+package logging_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/logging"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := logging.New(&buf, "warn", logging.FormatJSON)
+	require.NoError(t, err)
+
+	ctx := logging.With(context.Background(), "source_id", int64(1))
+	ctx = logging.With(ctx, slog.String("source", "Go Blog"))
+
+	logger.InfoContext(ctx, "skipped")
+	logger.With("attempt", 2).WarnContext(ctx, "failed to fetch items", "error", "timeout")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+
+	assert.Equal(t, "WARN", record["level"])
+	assert.Equal(t, "failed to fetch items", record["msg"])
+	assert.Equal(t, "timeout", record["error"])
+	assert.Equal(t, float64(2), record["attempt"])
+	assert.Equal(t, float64(1), record["source_id"])
+	assert.Equal(t, "Go Blog", record["source"])
+}
+
+func TestNew_Text(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := logging.New(&buf, "DEBUG", logging.FormatText)
+	require.NoError(t, err)
+
+	logger.DebugContext(logging.With(context.Background(), "command", "addsource"), "executed")
+
+	assert.Contains(t, buf.String(), "level=DEBUG msg=executed command=addsource")
+}
+
+func TestNew_Invalid(t *testing.T) {
+	_, err := logging.New(&bytes.Buffer{}, "verbose", logging.FormatText)
+	assert.Error(t, err)
+
+	_, err = logging.New(&bytes.Buffer{}, "info", "xml")
+	assert.ErrorIs(t, err, logging.ErrUnknownFormat)
+}
+
+func TestWith_DoesNotChangeParent(t *testing.T) {
+	var buf bytes.Buffer
+
+	logger, err := logging.New(&buf, "info", logging.FormatText)
+	require.NoError(t, err)
+
+	parent := logging.With(context.Background(), "channel_id", 1)
+	_ = logging.With(parent, "article_id", 2)
+
+	logger.InfoContext(parent, "posted")
+
+	assert.NotContains(t, buf.String(), "article_id")
+}
@@ -0,0 +1,128 @@
+// Package metrics defines Prometheus metrics of the bot and serves them.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "news_feed_bot"
+
+// Results of processing of fetched items.
+const (
+	ItemFetched   = "fetched"
+	ItemStored    = "stored"
+	ItemSkipped   = "skipped"
+	ItemDuplicate = "duplicate"
+	// ItemExists is an item stored by one of the previous fetches.
+	ItemExists = "exists"
+)
+
+// Types of posts.
+const (
+	PostArticle = "article"
+	PostDigest  = "digest"
+)
+
+var (
+	FetchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "fetcher",
+		Name:      "fetch_duration_seconds",
+		Help:      "Duration of fetches of a source.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 10),
+	}, []string{"source_id"})
+
+	FetchErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fetcher",
+		Name:      "fetch_errors_total",
+		Help:      "Number of failed fetches of a source.",
+	}, []string{"source_id"})
+
+	Items = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fetcher",
+		Name:      "items_total",
+		Help:      "Number of fetched items by result: fetched, stored, skipped by filter, duplicate or already stored.",
+	}, []string{"source_id", "result"})
+
+	TopicItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "fetcher",
+		Name:      "topic_items_total",
+		Help:      "Number of stored items which matched a topic.",
+	}, []string{"topic_id"})
+
+	SummaryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "summarizer",
+		Name:      "duration_seconds",
+		Help:      "Duration of summary generation.",
+		Buckets:   prometheus.ExponentialBuckets(0.25, 2, 10),
+	}, []string{"provider"})
+
+	SummaryErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "summarizer",
+		Name:      "errors_total",
+		Help:      "Number of failed summary generations.",
+	}, []string{"provider"})
+
+	SummaryTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "summarizer",
+		Name:      "tokens_total",
+		Help:      "Number of tokens used for summaries by type: prompt or completion.",
+	}, []string{"provider", "type"})
+
+	SummaryCacheHits = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "summarizer",
+		Name:      "cache_hits_total",
+		Help:      "Number of summaries taken from the cache.",
+	}, []string{"model"})
+
+	SummaryCacheMisses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "summarizer",
+		Name:      "cache_misses_total",
+		Help:      "Number of summaries not found in the cache.",
+	}, []string{"model"})
+
+	Posts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifier",
+		Name:      "posts_total",
+		Help:      "Number of posts to a channel by type: article or digest.",
+	}, []string{"channel_id", "type"})
+
+	PostFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifier",
+		Name:      "post_failures_total",
+		Help:      "Number of failed posts to a channel by type: article or digest.",
+	}, []string{"channel_id", "type"})
+
+	BotCommands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bot",
+		Name:      "commands_total",
+		Help:      "Number of invocations of a bot command.",
+	}, []string{"command"})
+
+	BotCommandErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "bot",
+		Name:      "command_errors_total",
+		Help:      "Number of failed invocations of a bot command.",
+	}, []string{"command"})
+)
+
+// Handler serves all registered metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
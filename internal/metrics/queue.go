@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// queueDepthTimeout limits the query run on every scrape.
+const queueDepthTimeout = 10 * time.Second
+
+type QueueDepthProvider interface {
+	// QueueDepth returns the number of articles published since given time which are not posted yet, by channel.
+	QueueDepth(ctx context.Context, since time.Time) ([]model.QueueDepth, error)
+}
+
+// queueDepthCollector reports the number of unposted articles on scrape, so it's always up-to-date.
+type queueDepthCollector struct {
+	provider QueueDepthProvider
+	window   time.Duration
+	desc     *prometheus.Desc
+}
+
+// NewQueueDepthCollector creates collector of the number of articles to be posted to each channel.
+// Only articles published within the window are counted, as the notifier doesn't post older ones.
+func NewQueueDepthCollector(provider QueueDepthProvider, window time.Duration) prometheus.Collector {
+	return &queueDepthCollector{
+		provider: provider,
+		window:   window,
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "notifier", "queue_depth"),
+			"Number of articles not posted to a channel yet.",
+			[]string{"channel_id", "channel"},
+			nil,
+		),
+	}
+}
+
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueDepthTimeout)
+	defer cancel()
+
+	depths, err := c.provider.QueueDepth(ctx, time.Now().Add(-c.window))
+	if err != nil {
+		// failing the whole scrape would hide the rest of metrics
+		slog.Error("failed to get queue depth", "error", err)
+		return
+	}
+
+	for _, depth := range depths {
+		ch <- prometheus.MustNewConstMetric(
+			c.desc,
+			prometheus.GaugeValue,
+			float64(depth.Depth),
+			strconv.FormatInt(depth.ChannelID, 10),
+			depth.ChannelName,
+		)
+	}
+}
@@ -0,0 +1,54 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type queueDepthFunc func(ctx context.Context, since time.Time) ([]model.QueueDepth, error)
+
+func (f queueDepthFunc) QueueDepth(ctx context.Context, since time.Time) ([]model.QueueDepth, error) {
+	return f(ctx, since)
+}
+
+func TestQueueDepthCollector(t *testing.T) {
+	var since time.Time
+
+	collector := metrics.NewQueueDepthCollector(queueDepthFunc(func(_ context.Context, s time.Time) ([]model.QueueDepth, error) {
+		since = s
+		// channels with the same name are reported separately
+		return []model.QueueDepth{
+			{ChannelID: 1, ChannelName: "Main", Depth: 3},
+			{ChannelID: 2, ChannelName: "Go", Depth: 0},
+			{ChannelID: 3, ChannelName: "Go", Depth: 5},
+		}, nil
+	}), time.Hour)
+
+	expected := `
+# HELP news_feed_bot_notifier_queue_depth Number of articles not posted to a channel yet.
+# TYPE news_feed_bot_notifier_queue_depth gauge
+news_feed_bot_notifier_queue_depth{channel="Main",channel_id="1"} 3
+news_feed_bot_notifier_queue_depth{channel="Go",channel_id="2"} 0
+news_feed_bot_notifier_queue_depth{channel="Go",channel_id="3"} 5
+`
+	require.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	assert.WithinDuration(t, time.Now().Add(-time.Hour), since, 5*time.Second)
+}
+
+func TestQueueDepthCollector_Error(t *testing.T) {
+	collector := metrics.NewQueueDepthCollector(queueDepthFunc(func(context.Context, time.Time) ([]model.QueueDepth, error) {
+		return nil, errors.New("connection refused")
+	}), time.Hour)
+
+	assert.Equal(t, 0, testutil.CollectAndCount(collector))
+}
@@ -13,21 +13,253 @@ type Item struct {
 	SourceName string
 }
 
+const (
+	SourceKindRSS        = "rss"
+	SourceKindAtom       = "atom"
+	SourceKindJSONFeed   = "json-feed"
+	SourceKindHTMLScrape = "html-scrape"
+)
+
 type Source struct {
-	ID        int64
+	ID         int64
+	Name       string
+	FeedURL    string
+	Kind       string
+	Priority   int
+	Selectors  *ScrapeSelectors
+	Validators CacheValidators
+	// FetchInterval is the own fetch interval of the source, zero means the default one.
+	FetchInterval time.Duration
+	// AdaptiveFetch enables adjusting fetch interval to the publishing rate of the source.
+	AdaptiveFetch bool
+	// AdaptiveInterval is the last interval computed for adaptive source.
+	AdaptiveInterval time.Duration
+	// FetchTimeout is the own timeout of a single fetch of the source, zero means the default one.
+	FetchTimeout time.Duration
+	// AllowList makes the source store only items matching topics, even if allow-list mode is off globally.
+	AllowList   bool
+	NextFetchAt time.Time
+	Health      SourceHealth
+	CreatedAt   time.Time
+}
+
+type SourceHealth struct {
+	LastSuccessAt       time.Time
+	LastError           string
+	LastErrorAt         time.Time
+	ConsecutiveFailures int
+	// DisabledAt is set when source was disabled after too many failures in a row.
+	DisabledAt time.Time
+}
+
+func (h SourceHealth) Disabled() bool {
+	return !h.DisabledAt.IsZero()
+}
+
+// CacheValidators are HTTP validators of the last successful fetch used for conditional requests.
+type CacheValidators struct {
+	ETag         string
+	LastModified string
+}
+
+// ScrapeSelectors describe how to extract items from HTML page with CSS selectors.
+// All selectors except Item are evaluated relative to the item container.
+type ScrapeSelectors struct {
+	Item       string
+	Title      string
+	Link       string
+	Date       string
+	DateLayout string
+	Summary    string
+}
+
+type Article struct {
+	ID       int64
+	SourceID int64
+	// SourceName is filled only when articles are selected for posting or listed.
+	SourceName    string
+	Title         string
+	Link          string
+	CanonicalLink string
+	Summary       string
+	// AISummary is the summary generated by the summarizer, empty until it's generated.
+	AISummary string
+	// AISummaryModel, AISummaryPromptHash and AISummaryGeneratedAt describe how AISummary was generated.
+	AISummaryModel       string
+	AISummaryPromptHash  string
+	AISummaryGeneratedAt time.Time
+	// AISummaryAttempts is the number of failed attempts to generate AISummary.
+	AISummaryAttempts int
+	Categories        []string
+	SimHash           uint64
+	// QueuePriority moves the article in the queue of articles to be posted, higher is posted first.
+	QueuePriority int
+	PublishedAt   time.Time
+	// PostedAt is when the article was posted for the first time, filled only for posted articles.
+	PostedAt time.Time
+	// SkippedAt is set when the article was skipped by admin, so it's never posted.
+	SkippedAt time.Time
+	CreatedAt time.Time
+}
+
+// Summary is a summary of an article text generated by a summarizer.
+type Summary struct {
+	Text string
+	// Model is the model which generated the summary, or the name of a local summarizer.
+	Model string
+	// PromptHash identifies the prompt the summary was generated with, empty for local summarizers.
+	PromptHash string
+}
+
+const (
+	ArticleStatusPending = "pending"
+	ArticleStatusPosted  = "posted"
+	ArticleStatusSkipped = "skipped"
+)
+
+// ArticleQuery selects stored articles, zero fields match all articles.
+type ArticleQuery struct {
+	// Text is searched in titles and summaries, case-insensitive.
+	Text     string
+	SourceID int64
+	// Status is one of ArticleStatus* constants.
+	Status string
+	Limit  uint64
+	Offset uint64
+}
+
+// ArticleFingerprint is what is needed to tell whether a new article is a duplicate of the stored one.
+type ArticleFingerprint struct {
+	CanonicalLink  string
+	Title          string
+	SimHash        uint64
+	SourceID       int64
+	SourcePriority int
+}
+
+// QueueDepth is the number of articles not posted to the channel yet.
+type QueueDepth struct {
+	ChannelID   int64
+	ChannelName string
+	Depth       int
+}
+
+const (
+	FilterActionInclude = "include"
+	FilterActionExclude = "exclude"
+)
+
+const (
+	FilterFieldTitle      = "title"
+	FilterFieldSummary    = "summary"
+	FilterFieldCategories = "categories"
+	FilterFieldLink       = "link"
+	FilterFieldDomain     = "domain"
+)
+
+const (
+	FilterOpContains = "contains"
+	FilterOpEquals   = "equals"
+	FilterOpRegex    = "regex"
+)
+
+// FilterRule decides whether fetched item is stored. Rules are checked in order
+// and the first matching one wins: include keeps the item, exclude skips it.
+type FilterRule struct {
+	ID int64
+	// SourceID is the source the rule is applied to, zero for global rules.
+	SourceID int64
+	// ChannelID is the channel the rule selects articles for, zero for rules applied on fetch.
+	ChannelID int64
 	Name      string
-	FeedURL   string
-	Priority  int
+	Action    string
+	Condition FilterCondition
+	Position  int
+	// DryRun rules only report matched items without affecting them.
+	DryRun    bool
 	CreatedAt time.Time
 }
 
-type Article struct {
-	ID          int64
-	SourceID    int64
-	Title       string
-	Link        string
-	Summary     string
-	PublishedAt time.Time
-	PostedAt    time.Time
-	CreatedAt   time.Time
+// FilterCondition is either a combination of nested conditions (All, Any or Not)
+// or a single check of item Field with operator Op against Value.
+type FilterCondition struct {
+	All []FilterCondition
+	Any []FilterCondition
+	Not *FilterCondition
+
+	Field string
+	Op    string
+	Value string
+}
+
+// Topic is a subject the channel is interested in. In allow-list mode only items
+// matching at least one topic are stored: any of Keywords in title or summary,
+// any of Categories, or Pattern regex.
+type Topic struct {
+	ID int64
+	// SourceID is the source the topic is applied to, zero for global topics.
+	SourceID   int64
+	Name       string
+	Keywords   []string
+	Categories []string
+	Pattern    string
+	// Admitted is the number of stored items which matched the topic.
+	Admitted  int64
+	CreatedAt time.Time
+}
+
+const (
+	ChannelKindTelegram   = "telegram"
+	ChannelKindSlack      = "slack"
+	ChannelKindMattermost = "mattermost"
+	ChannelKindDiscord    = "discord"
+	ChannelKindMatrix     = "matrix"
+	ChannelKindWebhook    = "webhook"
+)
+
+// Channel is a chat articles are posted to. The channel receives articles of the sources
+// it's subscribed to, its filter rules include or exclude articles on top of that.
+type Channel struct {
+	ID   int64
+	Kind string
+	// ChatID is the chat of Telegram channel.
+	ChatID int64
+	// URL is the webhook URL, or homeserver URL for Matrix.
+	URL string
+	// RoomID and AccessToken are the room and credentials of Matrix channel.
+	RoomID      string
+	AccessToken string
+	Name        string
+	// PostInterval is the minimal interval between posts, zero to use the default one.
+	PostInterval time.Duration
+	// AllSources channel is subscribed to all sources.
+	AllSources bool
+	// Default is the channel from config, it's created by migration and inherits the posted state of articles.
+	Default bool
+	// Digest, if its schedule is set, replaces posting articles one by one with periodic digests.
+	Digest    Digest
+	SourceIDs []int64
+	Rules     []FilterRule
+	// LastPostedAt is when an article was delivered to the channel last time, zero if never.
+	LastPostedAt time.Time
+	CreatedAt    time.Time
+}
+
+const (
+	DigestGroupBySource = "source"
+	DigestGroupByTopic  = "topic"
+)
+
+// Digest is a single message with the top articles which is posted on schedule.
+type Digest struct {
+	// Schedule is a cron expression, e.g. "0 9 * * *", empty if digest mode is off.
+	Schedule string
+	// Timezone is IANA name of the time zone Schedule is evaluated in, e.g. "Europe/Moscow".
+	Timezone string
+	// Size is the maximal number of articles in the digest.
+	Size int
+	// GroupBy is either DigestGroupBySource, DigestGroupByTopic or empty to list articles without groups.
+	GroupBy string
+	// SentAt is when the last digest was posted or digest mode was configured.
+	SentAt time.Time
 }
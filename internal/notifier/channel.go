@@ -0,0 +1,71 @@
+package notifier
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+// channelCandidatesLimit is how many not delivered articles are checked against rules of the channel at once.
+// More pages of candidates are checked if too few of them are admitted.
+const channelCandidatesLimit = 50
+
+// channelFilter decides which articles are posted to the channel: rules of the channel are checked first,
+// and if none of them matched, the article is posted if the channel is subscribed to its source.
+type channelFilter struct {
+	allSources bool
+	sources    map[int64]struct{}
+	rules      *rules.Set
+	include    bool
+}
+
+func newChannelFilter(ctx context.Context, channel model.Channel) channelFilter {
+	ruleSet, err := rules.Build(channel.Rules, nil)
+	if err != nil {
+		slog.ErrorContext(ctx, "some filter rules of channel are invalid and ignored", "error", err)
+	}
+
+	return channelFilter{
+		allSources: channel.AllSources,
+		sources:    lo.SliceToMap(channel.SourceIDs, func(id int64) (int64, struct{}) { return id, struct{}{} }),
+		rules:      ruleSet,
+		include: lo.SomeBy(channel.Rules, func(rule model.FilterRule) bool {
+			return rule.Action == model.FilterActionInclude && !rule.DryRun
+		}),
+	}
+}
+
+// sourceIDs returns sources the channel may receive articles of, nil for any source.
+// Reports false if the channel receives nothing.
+func (f channelFilter) sourceIDs() ([]int64, bool) {
+	if f.allSources || f.include {
+		return nil, true
+	}
+
+	return lo.Keys(f.sources), len(f.sources) > 0
+}
+
+func (f channelFilter) admit(article model.Article) bool {
+	decision := f.rules.Evaluate(article.SourceID, model.Item{
+		Title:      article.Title,
+		Categories: article.Categories,
+		Link:       article.Link,
+		Summary:    article.Summary,
+	})
+
+	if decision.Rule != nil {
+		return !decision.Skip()
+	}
+
+	if f.allSources {
+		return true
+	}
+
+	_, ok := f.sources[article.SourceID]
+
+	return ok
+}
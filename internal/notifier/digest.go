@@ -0,0 +1,174 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/cron"
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/publisher"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+var (
+	ErrInvalidDigestSize  = errors.New("digest size must be positive")
+	ErrUnknownDigestGroup = errors.New("digest articles can be grouped either by source or by topic")
+)
+
+const otherArticlesGroup = "Другое"
+
+// ValidateDigest reports whether digest settings are valid.
+func ValidateDigest(digest model.Digest) error {
+	if _, _, err := parseDigestSchedule(digest); err != nil {
+		return err
+	}
+
+	if digest.Size <= 0 {
+		return ErrInvalidDigestSize
+	}
+
+	switch digest.GroupBy {
+	case "", model.DigestGroupBySource, model.DigestGroupByTopic:
+		return nil
+	default:
+		return ErrUnknownDigestGroup
+	}
+}
+
+func parseDigestSchedule(digest model.Digest) (*cron.Schedule, *time.Location, error) {
+	schedule, err := cron.Parse(digest.Schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loc, err := time.LoadLocation(digest.Timezone)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return schedule, loc, nil
+}
+
+// sendDigestIfDue posts the digest if the schedule fired since the last one. Digests missed
+// while the bot was down are posted once on start.
+func (n *Notifier) sendDigestIfDue(
+	ctx context.Context,
+	channel model.Channel,
+	pub Publisher,
+	now time.Time,
+) error {
+	schedule, loc, err := parseDigestSchedule(channel.Digest)
+	if err != nil {
+		return err
+	}
+
+	next := schedule.Next(channel.Digest.SentAt.In(loc))
+	if next.IsZero() || next.After(now) {
+		return nil
+	}
+
+	articles, err := n.selectArticles(ctx, channel, n.since(channel, now), channel.Digest.Size)
+	if err != nil {
+		return err
+	}
+
+	if len(articles) == 0 {
+		return n.channels.RecordDigest(ctx, channel.ID, now, nil)
+	}
+
+	groups, err := n.groupDigestArticles(ctx, channel.Digest.GroupBy, articles)
+	if err != nil {
+		return err
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	included, err := pub.PublishDigest(publishCtx, publisher.Digest{Date: now.In(loc), Groups: groups})
+	if err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "digest is posted", "articles", len(included))
+	metrics.Posts.WithLabelValues(strconv.FormatInt(channel.ID, 10), metrics.PostDigest).Inc()
+
+	if err := n.channels.RecordDigest(ctx, channel.ID, now, included); err != nil {
+		// the digest is posted anyway, so it must not be posted again on the next check
+		slog.ErrorContext(ctx, "failed to record posted digest", "error", err)
+		return n.channels.SetDigestSentAt(ctx, channel.ID, now)
+	}
+
+	return nil
+}
+
+// groupDigestArticles splits articles into groups keeping their order: groups are ordered
+// by their first article. Articles matching no topic go to the last group.
+func (n *Notifier) groupDigestArticles(
+	ctx context.Context,
+	groupBy string,
+	articles []model.Article,
+) ([]publisher.DigestGroup, error) {
+	var groupOf func(article model.Article) string
+
+	switch groupBy {
+	case model.DigestGroupBySource:
+		groupOf = func(article model.Article) string { return article.SourceName }
+	case model.DigestGroupByTopic:
+		topics, err := n.topics.Topics(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		// invalid topics are reported by fetcher
+		topicSet, _ := rules.BuildTopics(topics)
+
+		groupOf = func(article model.Article) string {
+			matched := topicSet.Match(article.SourceID, model.Item{
+				Title:      article.Title,
+				Categories: article.Categories,
+				Link:       article.Link,
+				Summary:    article.Summary,
+			})
+			if len(matched) == 0 {
+				return ""
+			}
+
+			return matched[0].Name
+		}
+	default:
+		return []publisher.DigestGroup{{Articles: articles}}, nil
+	}
+
+	var (
+		groups  []publisher.DigestGroup
+		indexOf = make(map[string]int)
+		other   []model.Article
+	)
+
+	for _, article := range articles {
+		name := groupOf(article)
+		if name == "" {
+			other = append(other, article)
+			continue
+		}
+
+		i, ok := indexOf[name]
+		if !ok {
+			i = len(groups)
+			indexOf[name] = i
+			groups = append(groups, publisher.DigestGroup{Name: name})
+		}
+
+		groups[i].Articles = append(groups[i].Articles, article)
+	}
+
+	if len(other) > 0 {
+		groups = append(groups, publisher.DigestGroup{Name: otherArticlesGroup, Articles: other})
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,244 @@
+package notifier_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/notifier"
+	"github.com/defer-panic/news-feed-bot/internal/publisher"
+)
+
+var digestArticles = []model.Article{
+	{ID: 1, SourceID: 1, SourceName: "Go Blog", Title: "Go 1.21 is released", Link: "https://go.dev/blog/go1.21"},
+	{
+		ID:         2,
+		SourceID:   2,
+		SourceName: "Go Time",
+		Title:      "Go Time #281",
+		Link:       "https://changelog.com/gotime/281",
+		Categories: []string{"podcast"},
+	},
+	{ID: 3, SourceID: 1, SourceName: "Go Blog", Title: "Profile-guided optimization", Link: "https://go.dev/blog/pgo"},
+}
+
+func TestNotifier_SelectAndSendArticles_DigestGroups(t *testing.T) {
+	topics := []model.Topic{
+		{ID: 1, Name: "podcasts", Categories: []string{"podcast"}},
+		{ID: 2, Name: "releases", Keywords: []string{"released"}},
+	}
+
+	tests := []struct {
+		groupBy  string
+		expected []publisher.DigestGroup
+	}{
+		{
+			groupBy:  "",
+			expected: []publisher.DigestGroup{{Articles: digestArticles}},
+		},
+		{
+			groupBy: model.DigestGroupBySource,
+			expected: []publisher.DigestGroup{
+				{Name: "Go Blog", Articles: []model.Article{digestArticles[0], digestArticles[2]}},
+				{Name: "Go Time", Articles: []model.Article{digestArticles[1]}},
+			},
+		},
+		{
+			groupBy: model.DigestGroupByTopic,
+			expected: []publisher.DigestGroup{
+				{Name: "releases", Articles: []model.Article{digestArticles[0]}},
+				{Name: "podcasts", Articles: []model.Article{digestArticles[1]}},
+				{Name: "Другое", Articles: []model.Article{digestArticles[2]}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run("group by "+tt.groupBy, func(t *testing.T) {
+			var (
+				channels = &channelProviderStub{channels: []model.Channel{
+					digestChannel(tt.groupBy, time.Now().Add(-48*time.Hour)),
+				}}
+				pub = &publisherStub{}
+				n   = newDigestNotifier(channels, topics, pub)
+			)
+
+			require.NoError(t, n.SelectAndSendArticles(context.Background()))
+
+			require.Len(t, pub.digests, 1)
+			assert.Equal(t, tt.expected, pub.digests[0].Groups)
+		})
+	}
+}
+
+func TestNotifier_SelectAndSendArticles_DigestSchedule(t *testing.T) {
+	t.Run("should not post digest before schedule", func(t *testing.T) {
+		var (
+			channels = &channelProviderStub{channels: []model.Channel{digestChannel("", time.Now())}}
+			pub      = &publisherStub{}
+			n        = newDigestNotifier(channels, nil, pub)
+		)
+
+		require.NoError(t, n.SelectAndSendArticles(context.Background()))
+
+		assert.Empty(t, pub.digests)
+		assert.Empty(t, channels.recorded)
+	})
+
+	t.Run("should post digest missed since the last one", func(t *testing.T) {
+		var (
+			channels = &channelProviderStub{channels: []model.Channel{
+				digestChannel("", time.Now().Add(-48*time.Hour)),
+			}}
+			// the last article doesn't fit into the message
+			pub   = &publisherStub{maxArticles: 2}
+			n     = newDigestNotifier(channels, nil, pub)
+			posts = testutil.ToFloat64(metrics.Posts.WithLabelValues("1", metrics.PostDigest))
+		)
+
+		require.NoError(t, n.SelectAndSendArticles(context.Background()))
+
+		require.Len(t, pub.digests, 1)
+		assert.Equal(t, posts+1, testutil.ToFloat64(metrics.Posts.WithLabelValues("1", metrics.PostDigest)))
+		require.Len(t, channels.recorded, 1)
+		assert.Equal(t, digestArticles[:2], channels.recorded[0])
+	})
+
+	t.Run("should not post digest again if it failed to be recorded", func(t *testing.T) {
+		var (
+			channels = &channelProviderStub{
+				channels:  []model.Channel{digestChannel("", time.Now().Add(-48*time.Hour))},
+				recordErr: errors.New("connection refused"),
+			}
+			pub = &publisherStub{}
+			n   = newDigestNotifier(channels, nil, pub)
+		)
+
+		require.NoError(t, n.SelectAndSendArticles(context.Background()))
+
+		require.Len(t, pub.digests, 1)
+		assert.Len(t, channels.sentAt, 1)
+	})
+}
+
+func digestChannel(groupBy string, sentAt time.Time) model.Channel {
+	return model.Channel{
+		ID:         1,
+		Kind:       "stub",
+		Name:       "digest",
+		AllSources: true,
+		Digest: model.Digest{
+			Schedule: "0 9 * * *",
+			Timezone: "UTC",
+			Size:     10,
+			GroupBy:  groupBy,
+			SentAt:   sentAt,
+		},
+	}
+}
+
+func newDigestNotifier(channels *channelProviderStub, topics []model.Topic, pub *publisherStub) *notifier.Notifier {
+	registry := notifier.NewRegistry()
+	registry.Register("stub", func(model.Channel) (notifier.Publisher, error) { return pub, nil })
+
+	return notifier.New(
+		articleProviderStub{articles: digestArticles},
+		channels,
+		topicProviderStub{topics: topics},
+		summaryProviderStub{},
+		registry,
+		time.Minute,
+		time.Hour,
+	)
+}
+
+type articleProviderStub struct {
+	articles []model.Article
+}
+
+func (s articleProviderStub) NotDelivered(
+	_ context.Context,
+	_ int64,
+	_ []int64,
+	_ time.Time,
+	limit uint64,
+	offset uint64,
+) ([]model.Article, error) {
+	return lo.Subset(s.articles, int(offset), uint(limit)), nil
+}
+
+func (s articleProviderStub) MarkAsDelivered(context.Context, int64, model.Article) error {
+	return nil
+}
+
+type channelProviderStub struct {
+	channels  []model.Channel
+	recordErr error
+	recorded  [][]model.Article
+	sentAt    []time.Time
+}
+
+func (s *channelProviderStub) Channels(context.Context) ([]model.Channel, error) {
+	return s.channels, nil
+}
+
+func (s *channelProviderStub) RecordDigest(_ context.Context, _ int64, _ time.Time, articles []model.Article) error {
+	if s.recordErr != nil {
+		return s.recordErr
+	}
+
+	s.recorded = append(s.recorded, articles)
+
+	return nil
+}
+
+func (s *channelProviderStub) SetDigestSentAt(_ context.Context, _ int64, sentAt time.Time) error {
+	s.sentAt = append(s.sentAt, sentAt)
+	return nil
+}
+
+type topicProviderStub struct {
+	topics []model.Topic
+}
+
+func (s topicProviderStub) Topics(context.Context) ([]model.Topic, error) {
+	return s.topics, nil
+}
+
+type summaryProviderStub struct{}
+
+func (summaryProviderStub) Summary(context.Context, model.Article) string {
+	return ""
+}
+
+// publisherStub records digests, including up to maxArticles articles into each of them if it's set.
+type publisherStub struct {
+	maxArticles int
+	digests     []publisher.Digest
+}
+
+func (p *publisherStub) PublishArticle(context.Context, model.Article, string) error {
+	return nil
+}
+
+func (p *publisherStub) PublishDigest(_ context.Context, digest publisher.Digest) ([]model.Article, error) {
+	p.digests = append(p.digests, digest)
+
+	included := lo.FlatMap(digest.Groups, func(group publisher.DigestGroup, _ int) []model.Article {
+		return group.Articles
+	})
+
+	if p.maxArticles > 0 {
+		included = lo.Subset(included, 0, uint(p.maxArticles))
+	}
+
+	return included, nil
+}
@@ -2,69 +2,104 @@ package notifier
 
 import (
 	"context"
-	"fmt"
-	"io"
-	"log"
-	"net/http"
-	"regexp"
-	"strings"
+	"errors"
+	"log/slog"
+	"strconv"
 	"time"
 
-	"github.com/go-shiori/go-readability"
-	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/samber/lo"
 
-	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/logging"
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
 	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/publisher"
 )
 
 type ArticleProvider interface {
-	AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error)
-	MarkAsPosted(ctx context.Context, article model.Article) error
+	NotDelivered(
+		ctx context.Context,
+		channelID int64,
+		sourceIDs []int64,
+		since time.Time,
+		limit uint64,
+		offset uint64,
+	) ([]model.Article, error)
+	MarkAsDelivered(ctx context.Context, channelID int64, article model.Article) error
 }
 
-type Summarizer interface {
-	Summarize(text string) (string, error)
+type ChannelProvider interface {
+	Channels(ctx context.Context) ([]model.Channel, error)
+	RecordDigest(ctx context.Context, channelID int64, sentAt time.Time, articles []model.Article) error
+	SetDigestSentAt(ctx context.Context, channelID int64, sentAt time.Time) error
 }
 
+type TopicProvider interface {
+	Topics(ctx context.Context) ([]model.Topic, error)
+}
+
+// Publisher posts articles to a channel.
+type Publisher interface {
+	PublishArticle(ctx context.Context, article model.Article, summary string) error
+	// PublishDigest posts the digest and returns articles included into it,
+	// articles which don't fit into a single message are left out.
+	PublishDigest(ctx context.Context, digest publisher.Digest) ([]model.Article, error)
+}
+
+// SummaryProvider returns the summary of the article to be posted with it.
+type SummaryProvider interface {
+	Summary(ctx context.Context, article model.Article) string
+}
+
+const (
+	// channelsCheckInterval is how often channels are checked for being due to post.
+	channelsCheckInterval = 10 * time.Second
+
+	// publishTimeout limits a single post, so a hung target doesn't block posting to other channels.
+	publishTimeout = 30 * time.Second
+)
+
 type Notifier struct {
 	articles         ArticleProvider
-	summarizer       Summarizer
-	bot              *tgbotapi.BotAPI
+	channels         ChannelProvider
+	topics           TopicProvider
+	summaries        SummaryProvider
+	publishers       *Registry
 	sendInterval     time.Duration
 	lookupTimeWindow time.Duration
-	channelID        int64
 }
 
 func New(
 	articleProvider ArticleProvider,
-	summarizer Summarizer,
-	bot *tgbotapi.BotAPI,
+	channelProvider ChannelProvider,
+	topicProvider TopicProvider,
+	summaries SummaryProvider,
+	publishers *Registry,
 	sendInterval time.Duration,
 	lookupTimeWindow time.Duration,
-	channelID int64,
 ) *Notifier {
 	return &Notifier{
 		articles:         articleProvider,
-		summarizer:       summarizer,
-		bot:              bot,
+		channels:         channelProvider,
+		topics:           topicProvider,
+		summaries:        summaries,
+		publishers:       publishers,
 		sendInterval:     sendInterval,
 		lookupTimeWindow: lookupTimeWindow,
-		channelID:        channelID,
 	}
 }
 
 func (n *Notifier) Start(ctx context.Context) error {
-	ticker := time.NewTicker(n.sendInterval)
+	ticker := time.NewTicker(lo.Min([]time.Duration{n.sendInterval, channelsCheckInterval}))
 	defer ticker.Stop()
 
-	if err := n.SelectAndSendArticle(ctx); err != nil {
+	if err := n.SelectAndSendArticles(ctx); err != nil {
 		return err
 	}
 
 	for {
 		select {
 		case <-ticker.C:
-			if err := n.SelectAndSendArticle(ctx); err != nil {
+			if err := n.SelectAndSendArticles(ctx); err != nil {
 				return err
 			}
 		case <-ctx.Done():
@@ -73,79 +108,144 @@ func (n *Notifier) Start(ctx context.Context) error {
 	}
 }
 
-func (n *Notifier) SelectAndSendArticle(ctx context.Context) error {
-	topOneArticles, err := n.articles.AllNotPosted(ctx, time.Now().Add(-n.lookupTimeWindow), 1)
+// SelectAndSendArticles posts an article to each channel whose post interval has passed
+// and a digest to each channel in digest mode which is due on schedule.
+// Failure to post to a channel doesn't prevent posting to the others.
+func (n *Notifier) SelectAndSendArticles(ctx context.Context) error {
+	channels, err := n.channels.Channels(ctx)
 	if err != nil {
 		return err
 	}
 
-	if len(topOneArticles) == 0 {
-		return nil
-	}
+	now := time.Now()
 
-	article := topOneArticles[0]
+	for _, channel := range channels {
+		ctx := logging.With(ctx, "channel_id", channel.ID, "channel", channel.Name)
 
-	summary, err := n.extractSummary(article)
-	if err != nil {
-		log.Printf("[ERROR] failed to extract summary: %v", err)
-	}
+		pub, err := n.publishers.New(channel)
+		if errors.Is(err, publisher.ErrNoChatID) {
+			// Telegram channels with no chat, e.g. the default one which is not bound, are not posted to
+			continue
+		}
 
-	if err := n.sendArticle(article, summary); err != nil {
-		return err
-	}
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to create publisher of channel", "kind", channel.Kind, "error", err)
+			continue
+		}
 
-	return n.articles.MarkAsPosted(ctx, article)
-}
+		if channel.Digest.Schedule != "" {
+			if err := n.sendDigestIfDue(ctx, channel, pub, now); err != nil {
+				slog.ErrorContext(ctx, "failed to post digest", "error", err)
+				metrics.PostFailures.WithLabelValues(strconv.FormatInt(channel.ID, 10), metrics.PostDigest).Inc()
+			}
 
-var redundantNewLines = regexp.MustCompile(`\n{3,}`)
+			continue
+		}
 
-func (n *Notifier) extractSummary(article model.Article) (string, error) {
-	var r io.Reader
+		// the time of the last delivery is stored, so channels keep their pace after restart
+		if now.Before(channel.LastPostedAt.Add(n.postInterval(channel))) {
+			continue
+		}
 
-	if article.Summary != "" {
-		r = strings.NewReader(article.Summary)
-	} else {
-		resp, err := http.Get(article.Link)
+		posted, err := n.selectAndSendArticle(ctx, channel, pub)
 		if err != nil {
-			return "", err
+			slog.ErrorContext(ctx, "failed to post article", "error", err)
+			metrics.PostFailures.WithLabelValues(strconv.FormatInt(channel.ID, 10), metrics.PostArticle).Inc()
+
+			continue
 		}
-		defer resp.Body.Close()
 
-		r = resp.Body
+		if posted {
+			metrics.Posts.WithLabelValues(strconv.FormatInt(channel.ID, 10), metrics.PostArticle).Inc()
+		}
 	}
 
-	doc, err := readability.FromReader(r, nil)
-	if err != nil {
-		return "", err
+	return nil
+}
+
+func (n *Notifier) postInterval(channel model.Channel) time.Duration {
+	if channel.PostInterval > 0 {
+		return channel.PostInterval
 	}
 
-	summary, err := n.summarizer.Summarize(cleanupText(doc.TextContent))
-	if err != nil {
-		return "", err
+	return n.sendInterval
+}
+
+// Queue returns up to limit articles to be posted to the channel next, in the order they would be posted.
+func (n *Notifier) Queue(ctx context.Context, channel model.Channel, limit int) ([]model.Article, error) {
+	return n.selectArticles(ctx, channel, n.since(channel, time.Now()), limit)
+}
+
+// since returns the time articles to be posted to the channel are published after.
+func (n *Notifier) since(channel model.Channel, now time.Time) time.Time {
+	if channel.Digest.Schedule != "" {
+		// articles published shortly before the previous digest may have been fetched after it
+		return channel.Digest.SentAt.Add(-n.lookupTimeWindow)
 	}
 
-	return "\n\n" + summary, nil
+	return now.Add(-n.lookupTimeWindow)
 }
 
-func cleanupText(text string) string {
-	return redundantNewLines.ReplaceAllString(text, "\n")
+func (n *Notifier) selectAndSendArticle(ctx context.Context, channel model.Channel, pub Publisher) (bool, error) {
+	articles, err := n.selectArticles(ctx, channel, n.since(channel, time.Now()), 1)
+	if err != nil || len(articles) == 0 {
+		return false, err
+	}
+
+	article := articles[0]
+	ctx = logging.With(ctx, "article_id", article.ID)
+
+	summary := n.summaries.Summary(ctx, article)
+
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+
+	if err := pub.PublishArticle(publishCtx, article, summary); err != nil {
+		return false, err
+	}
+
+	slog.InfoContext(ctx, "article is posted", "title", article.Title, "link", article.Link)
+
+	return true, n.articles.MarkAsDelivered(ctx, channel.ID, article)
 }
 
-func (n *Notifier) sendArticle(article model.Article, summary string) error {
-	const msgFormat = "*%s*%s\n\n%s"
+// selectArticles returns up to limit articles published since given time which are to be posted to the channel.
+func (n *Notifier) selectArticles(
+	ctx context.Context,
+	channel model.Channel,
+	since time.Time,
+	limit int,
+) ([]model.Article, error) {
+	filter := newChannelFilter(ctx, channel)
+
+	sourceIDs, ok := filter.sourceIDs()
+	if !ok {
+		return nil, nil
+	}
+
+	// channels with rules may skip some of the articles, so there must be more candidates
+	candidates := limit
+	if len(channel.Rules) > 0 {
+		candidates = lo.Max([]int{limit, channelCandidatesLimit})
+	}
 
-	msg := tgbotapi.NewMessage(n.channelID, fmt.Sprintf(
-		msgFormat,
-		markup.EscapeForMarkdown(article.Title),
-		markup.EscapeForMarkdown(summary),
-		markup.EscapeForMarkdown(article.Link),
-	))
-	msg.ParseMode = "MarkdownV2"
+	var admitted []model.Article
 
-	_, err := n.bot.Send(msg)
-	if err != nil {
-		return err
+	// skipped articles are never delivered, so candidates are paged through until enough of them are admitted
+	for offset := 0; len(admitted) < limit; offset += candidates {
+		articles, err := n.articles.NotDelivered(ctx, channel.ID, sourceIDs, since, uint64(candidates), uint64(offset))
+		if err != nil {
+			return nil, err
+		}
+
+		admitted = append(admitted, lo.Filter(articles, func(article model.Article, _ int) bool {
+			return filter.admit(article)
+		})...)
+
+		if len(articles) < candidates {
+			break
+		}
 	}
 
-	return nil
+	return lo.Subset(admitted, 0, uint(limit)), nil
 }
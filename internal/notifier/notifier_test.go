@@ -0,0 +1,58 @@
+package notifier_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/notifier"
+)
+
+func TestNotifier_Queue_SkippedByRules(t *testing.T) {
+	var articles []model.Article
+
+	// skipped articles are never delivered, so they stay ahead of the admitted one
+	for i := 1; i <= 120; i++ {
+		articles = append(articles, model.Article{
+			ID:       int64(i),
+			SourceID: 1,
+			Title:    fmt.Sprintf("Sponsored post #%d", i),
+			Link:     fmt.Sprintf("https://example.com/%d", i),
+		})
+	}
+
+	articles = append(articles, digestArticles[0])
+
+	channel := model.Channel{
+		ID:         1,
+		Kind:       "stub",
+		Name:       "main",
+		AllSources: true,
+		Rules: []model.FilterRule{{
+			ID:        1,
+			ChannelID: 1,
+			Name:      "no sponsored posts",
+			Action:    model.FilterActionExclude,
+			Condition: model.FilterCondition{Field: model.FilterFieldTitle, Op: model.FilterOpContains, Value: "Sponsored"},
+		}},
+	}
+
+	n := notifier.New(
+		articleProviderStub{articles: articles},
+		&channelProviderStub{channels: []model.Channel{channel}},
+		topicProviderStub{},
+		summaryProviderStub{},
+		notifier.NewRegistry(),
+		time.Minute,
+		time.Hour,
+	)
+
+	queue, err := n.Queue(context.Background(), channel, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Article{digestArticles[0]}, queue)
+}
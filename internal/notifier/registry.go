@@ -0,0 +1,103 @@
+package notifier
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/publisher"
+)
+
+var ErrUnknownChannelKind = errors.New("unknown channel kind")
+
+// PublisherFactory creates a Publisher for the stored channel model.
+type PublisherFactory func(channel model.Channel) (Publisher, error)
+
+// Registry maps channel kinds to factories, so new kinds of channels
+// can be added without touching the notification loop.
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]PublisherFactory
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]PublisherFactory)}
+}
+
+// DefaultRegistry returns a registry with all channel kinds supported out of the box.
+// Webhooks are called with the given client, nil means the default one.
+func DefaultRegistry(bot *tgbotapi.BotAPI, client *http.Client) *Registry {
+	r := NewRegistry()
+
+	r.Register(model.ChannelKindTelegram, func(channel model.Channel) (Publisher, error) {
+		return publisher.NewTelegram(bot, channel.ChatID)
+	})
+	r.Register(model.ChannelKindSlack, func(channel model.Channel) (Publisher, error) {
+		return publisher.NewSlack(client, channel.URL)
+	})
+	r.Register(model.ChannelKindMattermost, func(channel model.Channel) (Publisher, error) {
+		return publisher.NewMattermost(client, channel.URL)
+	})
+	r.Register(model.ChannelKindDiscord, func(channel model.Channel) (Publisher, error) {
+		return publisher.NewDiscord(client, channel.URL)
+	})
+	r.Register(model.ChannelKindMatrix, func(channel model.Channel) (Publisher, error) {
+		return publisher.NewMatrix(client, channel.URL, channel.RoomID, channel.AccessToken)
+	})
+	r.Register(model.ChannelKindWebhook, func(channel model.Channel) (Publisher, error) {
+		return publisher.NewWebhook(client, channel.URL)
+	})
+
+	return r
+}
+
+func (r *Registry) Register(kind string, factory PublisherFactory) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.factories[kind] = factory
+}
+
+// Kinds returns sorted list of registered channel kinds.
+func (r *Registry) Kinds() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	kinds := make([]string, 0, len(r.factories))
+	for kind := range r.factories {
+		kinds = append(kinds, kind)
+	}
+
+	sort.Strings(kinds)
+
+	return kinds
+}
+
+// New creates a Publisher for the given channel. Channels without kind are treated as Telegram ones.
+func (r *Registry) New(channel model.Channel) (Publisher, error) {
+	kind := channel.Kind
+	if kind == "" {
+		kind = model.ChannelKindTelegram
+	}
+
+	r.mu.RLock()
+	factory, ok := r.factories[kind]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownChannelKind, kind)
+	}
+
+	return factory(channel)
+}
+
+// Validate reports whether a Publisher can be created for the channel.
+func (r *Registry) Validate(channel model.Channel) error {
+	_, err := r.New(channel)
+	return err
+}
@@ -0,0 +1,295 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-shiori/go-readability"
+
+	"github.com/defer-panic/news-feed-bot/internal/logging"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/summary"
+)
+
+const (
+	// summariesBatchSize is the number of articles summarized in a single run of the worker.
+	summariesBatchSize = 20
+
+	// summaryTimeout limits generation of a single summary, including all requests to LLM for long articles.
+	summaryTimeout = 10 * time.Minute
+)
+
+var (
+	errEmptySummary   = errors.New("summary is empty")
+	errSummaryClaimed = errors.New("summary is being generated by another instance or attempts are exhausted")
+)
+
+type SummaryStorage interface {
+	ArticleByID(ctx context.Context, id int64) (*model.Article, error)
+	Unsummarized(ctx context.Context, since time.Time, maxAttempts int, limit uint64) ([]model.Article, error)
+	ClaimSummary(ctx context.Context, articleID int64, maxAttempts int, staleBefore time.Time) (bool, error)
+	SaveAISummary(ctx context.Context, articleID int64, summary model.Summary) error
+	ReleaseSummary(ctx context.Context, articleID int64) error
+}
+
+type Summarizer interface {
+	Summarize(ctx context.Context, text string) (model.Summary, error)
+}
+
+// Summaries generates summaries of articles and stores them, so an article is summarized once
+// for all channels and feeds. Summaries of new articles are generated by the worker in background,
+// so they are ready by the time articles are posted.
+type Summaries struct {
+	articles         SummaryStorage
+	summarizer       Summarizer
+	interval         time.Duration
+	lookupTimeWindow time.Duration
+	maxAttempts      int
+
+	mu sync.Mutex
+	// generations are summaries being generated by this instance, by article ID.
+	// Callers which need the same summary wait for them instead of generating it again.
+	generations map[int64]*summaryGeneration
+}
+
+type summaryGeneration struct {
+	done    chan struct{}
+	summary model.Summary
+	err     error
+}
+
+// NewSummaries creates summaries generator. The worker summarizes articles published within
+// lookupTimeWindow every interval, giving up on an article after maxAttempts failures.
+func NewSummaries(
+	articles SummaryStorage,
+	summarizer Summarizer,
+	interval time.Duration,
+	lookupTimeWindow time.Duration,
+	maxAttempts int,
+) *Summaries {
+	return &Summaries{
+		articles:         articles,
+		summarizer:       summarizer,
+		interval:         interval,
+		lookupTimeWindow: lookupTimeWindow,
+		maxAttempts:      maxAttempts,
+		generations:      make(map[int64]*summaryGeneration),
+	}
+}
+
+func (s *Summaries) Start(ctx context.Context) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	if err := s.SummarizeNew(ctx); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.SummarizeNew(ctx); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SummarizeNew summarizes a batch of new articles which are not posted yet, in the order they are going to be posted.
+// Failure to summarize an article doesn't prevent summarizing the others.
+func (s *Summaries) SummarizeNew(ctx context.Context) error {
+	articles, err := s.articles.Unsummarized(ctx, time.Now().Add(-s.lookupTimeWindow), s.maxAttempts, summariesBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, article := range articles {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		ctx := logging.With(ctx, "article_id", article.ID)
+
+		if _, err := s.generate(ctx, article); err != nil {
+			if errors.Is(err, errSummaryClaimed) {
+				slog.DebugContext(ctx, "article is not summarized", "reason", err)
+				continue
+			}
+
+			slog.ErrorContext(ctx, "failed to summarize article", "error", err)
+		}
+	}
+
+	return nil
+}
+
+// Summary returns the summary of the article to be posted with it. The summary is generated now
+// if the worker hasn't got to the article yet, or awaited if the worker is generating it.
+// Empty summary is returned if summarizer fails or another instance of the bot is generating the summary.
+func (s *Summaries) Summary(ctx context.Context, article model.Article) string {
+	if article.AISummary != "" || article.AISummaryAttempts >= s.maxAttempts {
+		return article.AISummary
+	}
+
+	summary, err := s.generate(ctx, article)
+	if errors.Is(err, errSummaryClaimed) {
+		// the summary may be generated since the article was selected
+		stored, err := s.articles.ArticleByID(ctx, article.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get article", "error", err)
+			return ""
+		}
+
+		return stored.AISummary
+	}
+
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to summarize article", "error", err)
+		return ""
+	}
+
+	return summary.Text
+}
+
+// Regenerate replaces the summary of the article with a new one, e.g. after the prompt is changed.
+// Cached summaries are skipped, so the summary is generated anew even with the same prompt.
+func (s *Summaries) Regenerate(ctx context.Context, articleID int64) (model.Summary, error) {
+	article, err := s.articles.ArticleByID(ctx, articleID)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	ctx = summary.SkipCache(logging.With(ctx, "article_id", articleID))
+
+	// the article is summarized already, so it can't be claimed, and the admin asked for the summary explicitly
+	return s.once(ctx, articleID, func() (model.Summary, error) { return s.summarizeAndSave(ctx, *article) })
+}
+
+// generate claims the article and summarizes it, so neither this nor another instance of the bot
+// generates the same summary at once. Attempts are counted, so the worker gives up on articles
+// which can't be summarized.
+func (s *Summaries) generate(ctx context.Context, article model.Article) (model.Summary, error) {
+	return s.once(ctx, article.ID, func() (model.Summary, error) {
+		claimed, err := s.articles.ClaimSummary(ctx, article.ID, s.maxAttempts, time.Now().Add(-summaryTimeout))
+		if err != nil {
+			return model.Summary{}, err
+		}
+
+		if !claimed {
+			return model.Summary{}, errSummaryClaimed
+		}
+
+		return s.summarizeAndSave(ctx, article)
+	})
+}
+
+// once runs generation of the summary of the article unless this instance is generating it already,
+// in which case the result of that generation is awaited.
+func (s *Summaries) once(
+	ctx context.Context,
+	articleID int64,
+	generate func() (model.Summary, error),
+) (model.Summary, error) {
+	s.mu.Lock()
+
+	if generation, ok := s.generations[articleID]; ok {
+		s.mu.Unlock()
+
+		select {
+		case <-generation.done:
+			return generation.summary, generation.err
+		case <-ctx.Done():
+			return model.Summary{}, ctx.Err()
+		}
+	}
+
+	generation := &summaryGeneration{done: make(chan struct{})}
+	s.generations[articleID] = generation
+	s.mu.Unlock()
+
+	generation.summary, generation.err = generate()
+
+	s.mu.Lock()
+	delete(s.generations, articleID)
+	s.mu.Unlock()
+
+	close(generation.done)
+
+	return generation.summary, generation.err
+}
+
+// summarizeAndSave summarizes the article claimed by the caller and stores the summary.
+// The claim is released if summarizer fails.
+func (s *Summaries) summarizeAndSave(ctx context.Context, article model.Article) (model.Summary, error) {
+	summary, err := s.summarize(ctx, article)
+	if err != nil {
+		if err := s.articles.ReleaseSummary(ctx, article.ID); err != nil {
+			slog.ErrorContext(ctx, "failed to release summary claim", "error", err)
+		}
+
+		return model.Summary{}, err
+	}
+
+	if err := s.articles.SaveAISummary(ctx, article.ID, summary); err != nil {
+		return model.Summary{}, err
+	}
+
+	slog.DebugContext(ctx, "article is summarized", "model", summary.Model)
+
+	return summary, nil
+}
+
+var redundantNewLines = regexp.MustCompile(`\n{3,}`)
+
+func (s *Summaries) summarize(ctx context.Context, article model.Article) (model.Summary, error) {
+	ctx, cancel := context.WithTimeout(ctx, summaryTimeout)
+	defer cancel()
+
+	var r io.Reader
+
+	if article.Summary != "" {
+		r = strings.NewReader(article.Summary)
+	} else {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, article.Link, nil)
+		if err != nil {
+			return model.Summary{}, err
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return model.Summary{}, err
+		}
+		defer resp.Body.Close()
+
+		r = resp.Body
+	}
+
+	doc, err := readability.FromReader(r, nil)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	summary, err := s.summarizer.Summarize(ctx, cleanupText(doc.TextContent))
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	if strings.TrimSpace(summary.Text) == "" {
+		return model.Summary{}, errEmptySummary
+	}
+
+	return summary, nil
+}
+
+func cleanupText(text string) string {
+	return redundantNewLines.ReplaceAllString(text, "\n")
+}
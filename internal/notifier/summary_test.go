@@ -0,0 +1,150 @@
+package notifier_test
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/notifier"
+)
+
+var summaryArticle = model.Article{
+	ID:      1,
+	Title:   "Go 1.21 is released",
+	Link:    "https://go.dev/blog/go1.21",
+	Summary: "<p>Go 1.21 adds min, max and clear builtins.</p>",
+}
+
+func TestSummaries_Summary(t *testing.T) {
+	t.Run("should wait for the summary generated by the worker", func(t *testing.T) {
+		var (
+			storage = &summaryStorageStub{
+				articles: []model.Article{summaryArticle},
+				claimed:  make(map[int64]bool),
+				attempts: make(map[int64]int),
+			}
+			summarizer = &summarizerStub{started: make(chan struct{}), release: make(chan struct{})}
+			summaries  = notifier.NewSummaries(storage, summarizer, time.Hour, time.Hour, 3)
+			worker     = make(chan error, 1)
+		)
+
+		go func() { worker <- summaries.SummarizeNew(context.Background()) }()
+
+		<-summarizer.started
+
+		posted := make(chan string, 1)
+		go func() { posted <- summaries.Summary(context.Background(), summaryArticle) }()
+
+		close(summarizer.release)
+
+		require.NoError(t, <-worker)
+		assert.Equal(t, "Go 1.21 adds builtins", <-posted)
+		assert.EqualValues(t, 1, summarizer.calls.Load())
+		assert.Equal(t, 1, storage.attempts[summaryArticle.ID])
+	})
+
+	t.Run("should not generate the summary claimed by another instance", func(t *testing.T) {
+		var (
+			storage = &summaryStorageStub{
+				articles: []model.Article{summaryArticle},
+				claimed:  map[int64]bool{summaryArticle.ID: true},
+				attempts: map[int64]int{summaryArticle.ID: 1},
+			}
+			summarizer = &summarizerStub{}
+			summaries  = notifier.NewSummaries(storage, summarizer, time.Hour, time.Hour, 3)
+		)
+
+		assert.Empty(t, summaries.Summary(context.Background(), summaryArticle))
+		assert.Zero(t, summarizer.calls.Load())
+	})
+}
+
+// summaryStorageStub claims articles like the storage does: once until the claim is released.
+type summaryStorageStub struct {
+	mu       sync.Mutex
+	articles []model.Article
+	claimed  map[int64]bool
+	attempts map[int64]int
+}
+
+func (s *summaryStorageStub) ArticleByID(_ context.Context, id int64) (*model.Article, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, article := range s.articles {
+		if article.ID == id {
+			return &article, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+func (s *summaryStorageStub) Unsummarized(context.Context, time.Time, int, uint64) ([]model.Article, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.articles), nil
+}
+
+func (s *summaryStorageStub) ClaimSummary(_ context.Context, articleID int64, maxAttempts int, _ time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.claimed[articleID] || s.attempts[articleID] >= maxAttempts {
+		return false, nil
+	}
+
+	s.claimed[articleID] = true
+	s.attempts[articleID]++
+
+	return true, nil
+}
+
+func (s *summaryStorageStub) SaveAISummary(_ context.Context, articleID int64, summary model.Summary) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.articles {
+		if s.articles[i].ID == articleID {
+			s.articles[i].AISummary = summary.Text
+		}
+	}
+
+	return nil
+}
+
+func (s *summaryStorageStub) ReleaseSummary(_ context.Context, articleID int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.claimed, articleID)
+
+	return nil
+}
+
+// summarizerStub counts calls and, if started is set, signals it and blocks until release is closed.
+type summarizerStub struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (s *summarizerStub) Summarize(context.Context, string) (model.Summary, error) {
+	s.calls.Add(1)
+
+	if s.started != nil {
+		close(s.started)
+		<-s.release
+	}
+
+	return model.Summary{Text: "Go 1.21 adds builtins", Model: "stub"}, nil
+}
@@ -0,0 +1,54 @@
+package publisher
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var discordFormat = textFormat{
+	escape:    escapeMarkdown,
+	bold:      func(text string) string { return "**" + text + "**" },
+	link:      markdownLink,
+	maxLength: 2000,
+}
+
+// Discord posts to Discord webhook.
+type Discord struct {
+	client *http.Client
+	url    string
+}
+
+func NewDiscord(client *http.Client, url string) (*Discord, error) {
+	if url == "" {
+		return nil, ErrNoWebhookURL
+	}
+
+	return &Discord{client: clientOrDefault(client), url: url}, nil
+}
+
+type discordPayload struct {
+	Content string `json:"content"`
+}
+
+func (d *Discord) PublishArticle(ctx context.Context, article model.Article, summary string) error {
+	return postJSON(
+		ctx,
+		d.client,
+		http.MethodPost,
+		d.url,
+		discordPayload{Content: discordFormat.formatArticle(article, summary)},
+		nil,
+	)
+}
+
+func (d *Discord) PublishDigest(ctx context.Context, digest Digest) ([]model.Article, error) {
+	text, included := discordFormat.formatDigest(digest)
+
+	if err := postJSON(ctx, d.client, http.MethodPost, d.url, discordPayload{Content: text}, nil); err != nil {
+		return nil, err
+	}
+
+	return included, nil
+}
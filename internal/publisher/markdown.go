@@ -0,0 +1,29 @@
+package publisher
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	markdownReplacer = strings.NewReplacer(
+		"\\", "\\\\",
+		"*", "\\*",
+		"_", "\\_",
+		"~", "\\~",
+		"`", "\\`",
+		"[", "\\[",
+		"]", "\\]",
+		"|", "\\|",
+	)
+	markdownURLReplacer = strings.NewReplacer("(", "%28", ")", "%29", " ", "%20")
+)
+
+// escapeMarkdown escapes text for Markdown of Discord and Mattermost.
+func escapeMarkdown(text string) string {
+	return markdownReplacer.Replace(text)
+}
+
+func markdownLink(text, url string) string {
+	return fmt.Sprintf("[%s](%s)", text, markdownURLReplacer.Replace(url))
+}
@@ -0,0 +1,135 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var ErrIncompleteMatrixChannel = errors.New("matrix channel must have homeserver URL, room ID and access token")
+
+var (
+	matrixHTMLFormat = textFormat{
+		escape: html.EscapeString,
+		bold:   func(text string) string { return "<b>" + text + "</b>" },
+		link: func(text, url string) string {
+			return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), text)
+		},
+		maxLength: 30000,
+	}
+
+	// matrixPlainFormat renders body of the message for clients which don't support HTML.
+	matrixPlainFormat = textFormat{
+		escape: func(text string) string { return text },
+		bold:   func(text string) string { return text },
+		link:   func(text, url string) string { return fmt.Sprintf("%s (%s)", text, url) },
+	}
+)
+
+// Matrix posts to a Matrix room with Client-Server API.
+type Matrix struct {
+	client      *http.Client
+	homeserver  string
+	roomID      string
+	accessToken string
+	txnCounter  atomic.Int64
+}
+
+func NewMatrix(client *http.Client, homeserver, roomID, accessToken string) (*Matrix, error) {
+	if homeserver == "" || roomID == "" || accessToken == "" {
+		return nil, ErrIncompleteMatrixChannel
+	}
+
+	return &Matrix{
+		client:      clientOrDefault(client),
+		homeserver:  strings.TrimSuffix(homeserver, "/"),
+		roomID:      roomID,
+		accessToken: accessToken,
+	}, nil
+}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+func (m *Matrix) PublishArticle(ctx context.Context, article model.Article, summary string) error {
+	return m.send(
+		ctx,
+		matrixPlainFormat.formatArticle(article, summary),
+		matrixHTMLFormat.formatArticle(article, summary),
+	)
+}
+
+func (m *Matrix) PublishDigest(ctx context.Context, digest Digest) ([]model.Article, error) {
+	formatted, included := matrixHTMLFormat.formatDigest(digest)
+	body, _ := matrixPlainFormat.formatDigest(Digest{Date: digest.Date, Groups: onlyIncluded(digest.Groups, included)})
+
+	if err := m.send(ctx, body, formatted); err != nil {
+		return nil, err
+	}
+
+	return included, nil
+}
+
+func (m *Matrix) send(ctx context.Context, body, formatted string) error {
+	// transaction ID makes retries of the same request idempotent, so it must be unique per message
+	txnID := fmt.Sprintf("nfb-%d-%d", time.Now().UnixNano(), m.txnCounter.Add(1))
+
+	endpoint := fmt.Sprintf(
+		"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+		m.homeserver,
+		url.PathEscape(m.roomID),
+		txnID,
+	)
+
+	return postJSON(
+		ctx,
+		m.client,
+		http.MethodPut,
+		endpoint,
+		matrixMessage{
+			MsgType:       "m.text",
+			Body:          body,
+			Format:        "org.matrix.custom.html",
+			FormattedBody: strings.ReplaceAll(formatted, "\n", "<br>"),
+		},
+		map[string]string{"Authorization": "Bearer " + m.accessToken},
+	)
+}
+
+// onlyIncluded drops articles which are not included from the groups.
+func onlyIncluded(groups []DigestGroup, included []model.Article) []DigestGroup {
+	ids := make(map[int64]struct{}, len(included))
+	for _, article := range included {
+		ids[article.ID] = struct{}{}
+	}
+
+	result := make([]DigestGroup, 0, len(groups))
+
+	for _, group := range groups {
+		var articles []model.Article
+
+		for _, article := range group.Articles {
+			if _, ok := ids[article.ID]; ok {
+				articles = append(articles, article)
+			}
+		}
+
+		if len(articles) > 0 {
+			result = append(result, DigestGroup{Name: group.Name, Articles: articles})
+		}
+	}
+
+	return result
+}
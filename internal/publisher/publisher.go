@@ -0,0 +1,149 @@
+// Package publisher posts articles and digests to Telegram and to chats behind webhooks,
+// each in the markup of its target.
+package publisher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// Digest is a single message with links to several articles.
+type Digest struct {
+	Date   time.Time
+	Groups []DigestGroup
+}
+
+// DigestGroup is a group of digest articles, e.g. of the same source. Name is empty if articles are not grouped.
+type DigestGroup struct {
+	Name     string
+	Articles []model.Article
+}
+
+// textFormat renders articles and digests in the markup of the target.
+type textFormat struct {
+	escape func(text string) string
+	// bold and link get text which is already escaped.
+	bold func(text string) string
+	link func(text, url string) string
+	// maxLength is the limit of message length, zero for no limit.
+	maxLength int
+}
+
+func (f textFormat) formatArticle(article model.Article, summary string) string {
+	text := f.bold(f.link(f.escape(article.Title), article.Link))
+
+	if summary != "" {
+		if f.maxLength > 0 {
+			// leave some room for the title and escaping
+			summary = truncate(summary, f.maxLength/2)
+		}
+
+		text += "\n\n" + f.escape(summary)
+	}
+
+	return text
+}
+
+// formatDigest renders the digest. Returns the text and articles which fit into the message,
+// the rest are left for the next digest.
+func (f textFormat) formatDigest(digest Digest) (string, []model.Article) {
+	var (
+		text     strings.Builder
+		length   int
+		included []model.Article
+	)
+
+	write := func(s string) {
+		text.WriteString(s)
+		length += utf8.RuneCountInString(s)
+	}
+
+	write("📰 " + f.bold(f.escape("Дайджест за "+digest.Date.Format("02.01.2006"))))
+
+	for _, group := range digest.Groups {
+		header := "\n"
+		if group.Name != "" {
+			header = "\n\n" + f.bold(f.escape(group.Name))
+		}
+
+		for i, article := range group.Articles {
+			line := "\n• " + f.link(f.escape(article.Title), article.Link)
+			if i == 0 {
+				line = header + line
+			}
+
+			if f.maxLength > 0 && length+utf8.RuneCountInString(line) > f.maxLength {
+				return text.String(), included
+			}
+
+			write(line)
+			included = append(included, article)
+		}
+	}
+
+	return text.String(), included
+}
+
+func truncate(s string, maxLength int) string {
+	if utf8.RuneCountInString(s) <= maxLength {
+		return s
+	}
+
+	return string([]rune(s)[:maxLength-1]) + "…"
+}
+
+// postJSON sends payload to the webhook and checks that it's accepted.
+func postJSON(
+	ctx context.Context,
+	client *http.Client,
+	method string,
+	url string,
+	payload any,
+	headers map[string]string,
+) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	return nil
+}
+
+func clientOrDefault(client *http.Client) *http.Client {
+	if client == nil {
+		return http.DefaultClient
+	}
+
+	return client
+}
@@ -0,0 +1,378 @@
+package publisher_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/publisher"
+)
+
+var (
+	article = model.Article{
+		ID:          1,
+		SourceID:    1,
+		SourceName:  "Go Blog",
+		Title:       "Go 1.21 <is> released",
+		Link:        "https://go.dev/blog/go1.21",
+		Categories:  []string{"go"},
+		PublishedAt: time.Date(2023, 8, 8, 0, 0, 0, 0, time.UTC),
+	}
+	otherArticle = model.Article{
+		ID:         2,
+		SourceID:   2,
+		SourceName: "Go Time",
+		Title:      "Go Time #281",
+		Link:       "https://changelog.com/gotime/281",
+	}
+	digest = publisher.Digest{
+		Date: time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+		Groups: []publisher.DigestGroup{
+			{Name: "Go Blog", Articles: []model.Article{article}},
+			{Name: "Go Time", Articles: []model.Article{otherArticle}},
+		},
+	}
+)
+
+type request struct {
+	Method  string
+	Path    string
+	Headers http.Header
+	Body    map[string]any
+}
+
+// recorder is a stand-in for the webhook target which records requests.
+type recorder struct {
+	mu       sync.Mutex
+	requests []request
+	status   int
+}
+
+func newRecorder(t *testing.T, status int) (*recorder, *httptest.Server) {
+	rec := &recorder{status: status}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, request{
+			Method:  r.Method,
+			Path:    r.URL.EscapedPath(),
+			Headers: r.Header,
+			Body:    body,
+		})
+		rec.mu.Unlock()
+
+		w.WriteHeader(rec.status)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	return rec, srv
+}
+
+func (r *recorder) last(t *testing.T) request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	require.NotEmpty(t, r.requests)
+
+	return r.requests[len(r.requests)-1]
+}
+
+type articlePublisher interface {
+	PublishArticle(ctx context.Context, article model.Article, summary string) error
+	PublishDigest(ctx context.Context, digest publisher.Digest) ([]model.Article, error)
+}
+
+func TestWebhookPublishers(t *testing.T) {
+	tests := []struct {
+		name           string
+		new            func(url string) (articlePublisher, error)
+		field          string
+		articleText    string
+		digestContains []string
+	}{
+		{
+			name:        "slack",
+			new:         func(url string) (articlePublisher, error) { return publisher.NewSlack(nil, url) },
+			field:       "text",
+			articleText: "*<https://go.dev/blog/go1.21|Go 1.21 &lt;is&gt; released>*\n\nNew *slog* package",
+			digestContains: []string{
+				"📰 *Дайджест за 18.10.2026*",
+				"\n\n*Go Blog*\n• <https://go.dev/blog/go1.21|Go 1.21 &lt;is&gt; released>",
+				"\n\n*Go Time*\n• <https://changelog.com/gotime/281|Go Time #281>",
+			},
+		},
+		{
+			name:        "mattermost",
+			new:         func(url string) (articlePublisher, error) { return publisher.NewMattermost(nil, url) },
+			field:       "text",
+			articleText: "**[Go 1.21 <is> released](https://go.dev/blog/go1.21)**\n\nNew \\*slog\\* package",
+			digestContains: []string{
+				"📰 **Дайджест за 18.10.2026**",
+				"\n\n**Go Time**\n• [Go Time #281](https://changelog.com/gotime/281)",
+			},
+		},
+		{
+			name:        "discord",
+			new:         func(url string) (articlePublisher, error) { return publisher.NewDiscord(nil, url) },
+			field:       "content",
+			articleText: "**[Go 1.21 <is> released](https://go.dev/blog/go1.21)**\n\nNew \\*slog\\* package",
+			digestContains: []string{
+				"\n\n**Go Blog**\n• [Go 1.21 <is> released](https://go.dev/blog/go1.21)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, srv := newRecorder(t, http.StatusOK)
+
+			pub, err := tt.new(srv.URL)
+			require.NoError(t, err)
+
+			require.NoError(t, pub.PublishArticle(context.Background(), article, "New *slog* package"))
+
+			req := rec.last(t)
+			assert.Equal(t, http.MethodPost, req.Method)
+			assert.Equal(t, "application/json", req.Headers.Get("Content-Type"))
+			assert.Equal(t, tt.articleText, req.Body[tt.field])
+
+			included, err := pub.PublishDigest(context.Background(), digest)
+			require.NoError(t, err)
+			assert.Len(t, included, 2)
+
+			for _, s := range tt.digestContains {
+				assert.Contains(t, rec.last(t).Body[tt.field], s)
+			}
+		})
+
+		t.Run(tt.name+" without url", func(t *testing.T) {
+			_, err := tt.new("")
+			assert.ErrorIs(t, err, publisher.ErrNoWebhookURL)
+		})
+	}
+}
+
+func TestWebhookPublishers_Error(t *testing.T) {
+	_, srv := newRecorder(t, http.StatusNotFound)
+
+	pub, err := publisher.NewSlack(nil, srv.URL)
+	require.NoError(t, err)
+
+	assert.ErrorContains(t, pub.PublishArticle(context.Background(), article, ""), "unexpected status code 404")
+
+	included, err := pub.PublishDigest(context.Background(), digest)
+	assert.Error(t, err)
+	assert.Empty(t, included)
+}
+
+func TestDiscord_PublishDigest_MessageLimit(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusNoContent)
+
+	pub, err := publisher.NewDiscord(nil, srv.URL)
+	require.NoError(t, err)
+
+	articles := make([]model.Article, 100)
+	for i := range articles {
+		articles[i] = model.Article{ID: int64(i + 1), Title: strings.Repeat("a", 50), Link: "https://example.com/"}
+	}
+
+	included, err := pub.PublishDigest(context.Background(), publisher.Digest{
+		Groups: []publisher.DigestGroup{{Articles: articles}},
+	})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, included)
+	assert.Less(t, len(included), len(articles))
+	assert.LessOrEqual(t, len([]rune(rec.last(t).Body["content"].(string))), 2000)
+}
+
+func TestMatrix(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusOK)
+
+	pub, err := publisher.NewMatrix(nil, srv.URL+"/", "!room:example.org", "secret")
+	require.NoError(t, err)
+
+	require.NoError(t, pub.PublishArticle(context.Background(), article, "New slog package"))
+	first := rec.last(t)
+
+	assert.Equal(t, http.MethodPut, first.Method)
+	assert.True(
+		t,
+		strings.HasPrefix(first.Path, "/_matrix/client/v3/rooms/"+url.PathEscape("!room:example.org")+"/send/m.room.message/"),
+		first.Path,
+	)
+	assert.Equal(t, "Bearer secret", first.Headers.Get("Authorization"))
+	assert.Equal(t, "m.text", first.Body["msgtype"])
+	assert.Equal(t, "Go 1.21 <is> released (https://go.dev/blog/go1.21)\n\nNew slog package", first.Body["body"])
+	assert.Equal(t, "org.matrix.custom.html", first.Body["format"])
+	assert.Equal(
+		t,
+		`<b><a href="https://go.dev/blog/go1.21">Go 1.21 &lt;is&gt; released</a></b><br><br>New slog package`,
+		first.Body["formatted_body"],
+	)
+
+	included, err := pub.PublishDigest(context.Background(), digest)
+	require.NoError(t, err)
+	assert.Len(t, included, 2)
+
+	second := rec.last(t)
+	assert.NotEqual(t, first.Path, second.Path, "transaction IDs must be unique")
+	assert.Contains(t, second.Body["formatted_body"], "<b>Go Time</b><br>• ")
+
+	_, err = publisher.NewMatrix(nil, srv.URL, "", "secret")
+	assert.ErrorIs(t, err, publisher.ErrIncompleteMatrixChannel)
+}
+
+func TestWebhook(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusAccepted)
+
+	pub, err := publisher.NewWebhook(nil, srv.URL)
+	require.NoError(t, err)
+
+	require.NoError(t, pub.PublishArticle(context.Background(), article, "New slog package"))
+
+	body := rec.last(t).Body
+	assert.Equal(t, "article", body["type"])
+	assert.Equal(t, map[string]any{
+		"id":           float64(1),
+		"source_id":    float64(1),
+		"source":       "Go Blog",
+		"title":        "Go 1.21 <is> released",
+		"link":         "https://go.dev/blog/go1.21",
+		"summary":      "New slog package",
+		"categories":   []any{"go"},
+		"published_at": "2023-08-08T00:00:00Z",
+	}, body["article"])
+
+	included, err := pub.PublishDigest(context.Background(), digest)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Article{article, otherArticle}, included)
+
+	body = rec.last(t).Body
+	assert.Equal(t, "digest", body["type"])
+	assert.Equal(t, "2026-10-18T09:00:00Z", body["date"])
+	require.Len(t, body["groups"], 2)
+	assert.Equal(t, "Go Time", body["groups"].([]any)[1].(map[string]any)["name"])
+}
+
+func TestTelegram(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		sent []url.Values
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			_, _ = io.WriteString(w, `{"ok": true, "result": {"id": 1, "is_bot": true, "username": "news_bot"}}`)
+			return
+		}
+
+		require.NoError(t, r.ParseForm())
+
+		mu.Lock()
+		sent = append(sent, r.PostForm)
+		mu.Unlock()
+
+		_, _ = io.WriteString(w, `{"ok": true, "result": {"message_id": 1, "date": 0, "chat": {"id": -100}}}`)
+	}))
+	t.Cleanup(srv.Close)
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	require.NoError(t, err)
+
+	pub, err := publisher.NewTelegram(bot, -100)
+	require.NoError(t, err)
+
+	require.NoError(t, pub.PublishArticle(context.Background(), article, "New slog package."))
+
+	included, err := pub.PublishDigest(context.Background(), digest)
+	require.NoError(t, err)
+	assert.Len(t, included, 2)
+
+	require.Len(t, sent, 2)
+
+	assert.Equal(t, "-100", sent[0].Get("chat_id"))
+	assert.Equal(t, "MarkdownV2", sent[0].Get("parse_mode"))
+	assert.Equal(
+		t,
+		"*Go 1\\.21 <is\\> released*\n\nNew slog package\\.\n\nhttps://go\\.dev/blog/go1\\.21",
+		sent[0].Get("text"),
+	)
+
+	assert.Equal(t, "true", sent[1].Get("disable_web_page_preview"))
+	assert.Contains(t, sent[1].Get("text"), "\n\n*Go Time*\n• [Go Time \\#281](https://changelog.com/gotime/281)")
+
+	// the digest is cut to the message limit of Telegram, the rest of articles are left for the next one
+	articles := make([]model.Article, 200)
+	for i := range articles {
+		articles[i] = model.Article{ID: int64(i + 1), Title: strings.Repeat("a", 50), Link: "https://example.com/"}
+	}
+
+	included, err = pub.PublishDigest(context.Background(), publisher.Digest{
+		Groups: []publisher.DigestGroup{{Articles: articles}},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sent, 3)
+	assert.NotEmpty(t, included)
+	assert.Less(t, len(included), len(articles))
+	assert.LessOrEqual(t, len([]rune(sent[2].Get("text"))), 4096)
+
+	// long summaries are cut to the message limit as well
+	require.NoError(t, pub.PublishArticle(context.Background(), article, strings.Repeat("New slog package. ", 500)))
+	require.Len(t, sent, 4)
+	assert.LessOrEqual(t, len([]rune(sent[3].Get("text"))), 4096)
+	assert.True(t, strings.HasSuffix(sent[3].Get("text"), "https://go\\.dev/blog/go1\\.21"))
+
+	_, err = publisher.NewTelegram(bot, 0)
+	assert.ErrorIs(t, err, publisher.ErrNoChatID)
+}
+
+func TestTelegram_Timeout(t *testing.T) {
+	release := make(chan struct{})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if strings.HasSuffix(r.URL.Path, "/getMe") {
+			_, _ = io.WriteString(w, `{"ok": true, "result": {"id": 1, "is_bot": true, "username": "news_bot"}}`)
+			return
+		}
+
+		// Bot API hangs
+		<-release
+
+		_, _ = io.WriteString(w, `{"ok": true, "result": {"message_id": 1, "date": 0, "chat": {"id": -100}}}`)
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	bot, err := tgbotapi.NewBotAPIWithAPIEndpoint("token", srv.URL+"/bot%s/%s")
+	require.NoError(t, err)
+
+	pub, err := publisher.NewTelegram(bot, -100)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, pub.PublishArticle(ctx, article, ""), context.DeadlineExceeded)
+}
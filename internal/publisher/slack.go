@@ -0,0 +1,73 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var ErrNoWebhookURL = errors.New("channel must have webhook URL")
+
+var (
+	slackReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+	slackFormat = textFormat{
+		escape:    slackReplacer.Replace,
+		bold:      func(text string) string { return "*" + text + "*" },
+		link:      func(text, url string) string { return fmt.Sprintf("<%s|%s>", url, text) },
+		maxLength: 40000,
+	}
+
+	// mattermostFormat is used for Mattermost: its incoming webhooks accept Slack payloads, but the text is Markdown.
+	mattermostFormat = textFormat{
+		escape:    escapeMarkdown,
+		bold:      func(text string) string { return "**" + text + "**" },
+		link:      markdownLink,
+		maxLength: 16383,
+	}
+)
+
+// Slack posts to Slack or Mattermost incoming webhook.
+type Slack struct {
+	client *http.Client
+	url    string
+	format textFormat
+}
+
+func NewSlack(client *http.Client, url string) (*Slack, error) {
+	return newSlack(client, url, slackFormat)
+}
+
+func NewMattermost(client *http.Client, url string) (*Slack, error) {
+	return newSlack(client, url, mattermostFormat)
+}
+
+func newSlack(client *http.Client, url string, format textFormat) (*Slack, error) {
+	if url == "" {
+		return nil, ErrNoWebhookURL
+	}
+
+	return &Slack{client: clientOrDefault(client), url: url, format: format}, nil
+}
+
+type slackPayload struct {
+	Text string `json:"text"`
+}
+
+func (s *Slack) PublishArticle(ctx context.Context, article model.Article, summary string) error {
+	return postJSON(ctx, s.client, http.MethodPost, s.url, slackPayload{Text: s.format.formatArticle(article, summary)}, nil)
+}
+
+func (s *Slack) PublishDigest(ctx context.Context, digest Digest) ([]model.Article, error) {
+	text, included := s.format.formatDigest(digest)
+
+	if err := postJSON(ctx, s.client, http.MethodPost, s.url, slackPayload{Text: text}, nil); err != nil {
+		return nil, err
+	}
+
+	return included, nil
+}
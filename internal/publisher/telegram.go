@@ -0,0 +1,85 @@
+package publisher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+
+	"github.com/defer-panic/news-feed-bot/internal/botkit/markup"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var ErrNoChatID = errors.New("telegram channel must have chat ID")
+
+var telegramFormat = textFormat{
+	escape:    markup.EscapeForMarkdown,
+	bold:      func(text string) string { return "*" + text + "*" },
+	link:      func(text, url string) string { return fmt.Sprintf("[%s](%s)", text, markup.EscapeForLink(url)) },
+	maxLength: 4096,
+}
+
+// Telegram posts to a Telegram chat with MarkdownV2 markup.
+type Telegram struct {
+	bot    *tgbotapi.BotAPI
+	chatID int64
+}
+
+func NewTelegram(bot *tgbotapi.BotAPI, chatID int64) (*Telegram, error) {
+	if chatID == 0 {
+		return nil, ErrNoChatID
+	}
+
+	return &Telegram{bot: bot, chatID: chatID}, nil
+}
+
+// PublishArticle posts the article with a link at the end, so Telegram shows its preview.
+func (t *Telegram) PublishArticle(ctx context.Context, article model.Article, summary string) error {
+	text := "*" + markup.EscapeForMarkdown(article.Title) + "*"
+
+	if summary != "" {
+		// leave some room for the title, the link and escaping
+		text += "\n\n" + markup.EscapeForMarkdown(truncate(summary, telegramFormat.maxLength/2))
+	}
+
+	text += "\n\n" + markup.EscapeForMarkdown(article.Link)
+
+	return t.send(ctx, text, false)
+}
+
+func (t *Telegram) PublishDigest(ctx context.Context, digest Digest) ([]model.Article, error) {
+	text, included := telegramFormat.formatDigest(digest)
+
+	if err := t.send(ctx, text, true); err != nil {
+		return nil, err
+	}
+
+	return included, nil
+}
+
+// send posts the message until the context is done. Bot API client doesn't support contexts,
+// so the request isn't canceled, but the caller doesn't wait for it.
+func (t *Telegram) send(ctx context.Context, text string, disablePreview bool) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg := tgbotapi.NewMessage(t.chatID, text)
+	msg.ParseMode = "MarkdownV2"
+	msg.DisableWebPagePreview = disablePreview
+
+	sent := make(chan error, 1)
+
+	go func() {
+		_, err := t.bot.Send(msg)
+		sent <- err
+	}()
+
+	select {
+	case err := <-sent:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
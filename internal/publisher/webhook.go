@@ -0,0 +1,96 @@
+package publisher
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const (
+	webhookEventArticle = "article"
+	webhookEventDigest  = "digest"
+)
+
+// Webhook posts articles and digests as JSON, so any service can consume them.
+type Webhook struct {
+	client *http.Client
+	url    string
+}
+
+func NewWebhook(client *http.Client, url string) (*Webhook, error) {
+	if url == "" {
+		return nil, ErrNoWebhookURL
+	}
+
+	return &Webhook{client: clientOrDefault(client), url: url}, nil
+}
+
+type webhookPayload struct {
+	Type    string               `json:"type"`
+	Article *webhookArticle      `json:"article,omitempty"`
+	Date    *time.Time           `json:"date,omitempty"`
+	Groups  []webhookDigestGroup `json:"groups,omitempty"`
+}
+
+type webhookArticle struct {
+	ID          int64     `json:"id"`
+	SourceID    int64     `json:"source_id"`
+	Source      string    `json:"source,omitempty"`
+	Title       string    `json:"title"`
+	Link        string    `json:"link"`
+	Summary     string    `json:"summary,omitempty"`
+	Categories  []string  `json:"categories,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+type webhookDigestGroup struct {
+	Name     string           `json:"name,omitempty"`
+	Articles []webhookArticle `json:"articles"`
+}
+
+func toWebhookArticle(article model.Article, summary string) webhookArticle {
+	return webhookArticle{
+		ID:          article.ID,
+		SourceID:    article.SourceID,
+		Source:      article.SourceName,
+		Title:       article.Title,
+		Link:        article.Link,
+		Summary:     summary,
+		Categories:  article.Categories,
+		PublishedAt: article.PublishedAt,
+	}
+}
+
+func (w *Webhook) PublishArticle(ctx context.Context, article model.Article, summary string) error {
+	payload := webhookPayload{
+		Type:    webhookEventArticle,
+		Article: lo.ToPtr(toWebhookArticle(article, summary)),
+	}
+
+	return postJSON(ctx, w.client, http.MethodPost, w.url, payload, nil)
+}
+
+func (w *Webhook) PublishDigest(ctx context.Context, digest Digest) ([]model.Article, error) {
+	payload := webhookPayload{
+		Type: webhookEventDigest,
+		Date: &digest.Date,
+		Groups: lo.Map(digest.Groups, func(group DigestGroup, _ int) webhookDigestGroup {
+			return webhookDigestGroup{
+				Name: group.Name,
+				Articles: lo.Map(group.Articles, func(article model.Article, _ int) webhookArticle {
+					return toWebhookArticle(article, "")
+				}),
+			}
+		}),
+	}
+
+	if err := postJSON(ctx, w.client, http.MethodPost, w.url, payload, nil); err != nil {
+		return nil, err
+	}
+
+	return lo.FlatMap(digest.Groups, func(group DigestGroup, _ int) []model.Article { return group.Articles }), nil
+}
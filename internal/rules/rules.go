@@ -0,0 +1,309 @@
+// Package rules decides which fetched items are stored using filter rules configured by admins.
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var (
+	ErrInvalidAction    = errors.New("action must be either include or exclude")
+	ErrInvalidCondition = errors.New("condition must have exactly one of all, any, not or field")
+	ErrUnknownField     = errors.New("unknown field")
+	ErrUnknownOp        = errors.New("unknown operator")
+	ErrEmptyValue       = errors.New("value must not be empty")
+)
+
+// KeywordsRuleName is the name of the rule built from keywords of the legacy global filter.
+const KeywordsRuleName = "filter_keywords"
+
+// Rule is a compiled filter rule ready to be matched against items.
+type Rule struct {
+	model.FilterRule
+	match matcher
+}
+
+type matcher func(item model.Item) bool
+
+// Compile validates the rule and prepares it for matching.
+func Compile(rule model.FilterRule) (*Rule, error) {
+	if rule.Action != model.FilterActionInclude && rule.Action != model.FilterActionExclude {
+		return nil, ErrInvalidAction
+	}
+
+	match, err := compileCondition(rule.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Rule{FilterRule: rule, match: match}, nil
+}
+
+// Validate reports whether the rule can be compiled.
+func Validate(rule model.FilterRule) error {
+	_, err := Compile(rule)
+	return err
+}
+
+// KeywordsRule builds a global exclude rule which skips items with any of keywords
+// in title or categories. It's checked after all other rules, so they can override it.
+// Reports false if there are no keywords besides blank ones, as a rule with no conditions is invalid.
+func KeywordsRule(keywords []string) (model.FilterRule, bool) {
+	conditions := make([]model.FilterCondition, 0, 2*len(keywords))
+
+	for _, keyword := range keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword == "" {
+			continue
+		}
+
+		conditions = append(
+			conditions,
+			model.FilterCondition{Field: model.FilterFieldTitle, Op: model.FilterOpContains, Value: keyword},
+			model.FilterCondition{Field: model.FilterFieldCategories, Op: model.FilterOpEquals, Value: keyword},
+		)
+	}
+
+	if len(conditions) == 0 {
+		return model.FilterRule{}, false
+	}
+
+	return model.FilterRule{
+		Name:      KeywordsRuleName,
+		Action:    model.FilterActionExclude,
+		Condition: model.FilterCondition{Any: conditions},
+		Position:  math.MaxInt,
+	}, true
+}
+
+// Build compiles rules and, if keywords are given, the rule built from them with KeywordsRule.
+// Invalid rules are left out of the set and reported in the returned error.
+func Build(rules []model.FilterRule, keywords []string) (*Set, error) {
+	var (
+		compiled = make([]*Rule, 0, len(rules)+1)
+		errs     []error
+	)
+
+	if keywordsRule, ok := KeywordsRule(keywords); ok {
+		rules = append(rules[:len(rules):len(rules)], keywordsRule)
+	}
+
+	for _, rule := range rules {
+		r, err := Compile(rule)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rule %q (#%d): %w", rule.Name, rule.ID, err))
+			continue
+		}
+
+		compiled = append(compiled, r)
+	}
+
+	return NewSet(compiled...), errors.Join(errs...)
+}
+
+// Decision is the result of checking item against the rules.
+type Decision struct {
+	// Rule is the rule which decided whether to store the item, nil if none of the rules matched.
+	Rule *model.FilterRule
+	// DryRun are dry-run rules which matched the item before the deciding one.
+	DryRun []model.FilterRule
+}
+
+// Skip reports whether the item must not be stored.
+func (d Decision) Skip() bool {
+	return d.Rule != nil && d.Rule.Action == model.FilterActionExclude
+}
+
+// Set is an ordered set of rules: rules of the source are checked before global ones,
+// and within each group rules are ordered by position.
+type Set struct {
+	rules []*Rule
+}
+
+func NewSet(rules ...*Rule) *Set {
+	rules = lo.Filter(rules, func(rule *Rule, _ int) bool { return rule != nil })
+
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Position != rules[j].Position {
+			return rules[i].Position < rules[j].Position
+		}
+
+		return rules[i].ID < rules[j].ID
+	})
+
+	return &Set{rules: rules}
+}
+
+// Evaluate checks item of the source against the rules. Items not matched by any rule are stored.
+func (s *Set) Evaluate(sourceID int64, item model.Item) Decision {
+	var decision Decision
+
+	if s == nil {
+		return decision
+	}
+
+	for _, global := range []bool{false, true} {
+		for _, rule := range s.rules {
+			if !rule.appliesTo(sourceID, global) {
+				continue
+			}
+
+			if !rule.match(item) {
+				continue
+			}
+
+			if rule.DryRun {
+				decision.DryRun = append(decision.DryRun, rule.FilterRule)
+				continue
+			}
+
+			matched := rule.FilterRule
+			decision.Rule = &matched
+
+			return decision
+		}
+	}
+
+	return decision
+}
+
+func (r *Rule) appliesTo(sourceID int64, global bool) bool {
+	if global {
+		return r.SourceID == 0
+	}
+
+	return r.SourceID != 0 && r.SourceID == sourceID
+}
+
+func compileCondition(condition model.FilterCondition) (matcher, error) {
+	var kinds int
+
+	for _, set := range []bool{
+		len(condition.All) > 0,
+		len(condition.Any) > 0,
+		condition.Not != nil,
+		condition.Field != "" || condition.Op != "",
+	} {
+		if set {
+			kinds++
+		}
+	}
+
+	if kinds != 1 {
+		return nil, ErrInvalidCondition
+	}
+
+	switch {
+	case len(condition.All) > 0:
+		matchers, err := compileConditions(condition.All)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(item model.Item) bool {
+			return lo.EveryBy(matchers, func(match matcher) bool { return match(item) })
+		}, nil
+	case len(condition.Any) > 0:
+		matchers, err := compileConditions(condition.Any)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(item model.Item) bool {
+			return lo.SomeBy(matchers, func(match matcher) bool { return match(item) })
+		}, nil
+	case condition.Not != nil:
+		match, err := compileCondition(*condition.Not)
+		if err != nil {
+			return nil, err
+		}
+
+		return func(item model.Item) bool { return !match(item) }, nil
+	default:
+		return compileCheck(condition.Field, condition.Op, condition.Value)
+	}
+}
+
+func compileConditions(conditions []model.FilterCondition) ([]matcher, error) {
+	matchers := make([]matcher, 0, len(conditions))
+
+	for _, condition := range conditions {
+		match, err := compileCondition(condition)
+		if err != nil {
+			return nil, err
+		}
+
+		matchers = append(matchers, match)
+	}
+
+	return matchers, nil
+}
+
+func compileCheck(field, op, value string) (matcher, error) {
+	values, err := fieldValues(field)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" {
+		return nil, ErrEmptyValue
+	}
+
+	var check func(s string) bool
+
+	switch op {
+	case model.FilterOpContains:
+		value = strings.ToLower(value)
+		check = func(s string) bool { return strings.Contains(strings.ToLower(s), value) }
+	case model.FilterOpEquals:
+		check = func(s string) bool { return strings.EqualFold(s, value) }
+	case model.FilterOpRegex:
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", value, err)
+		}
+
+		check = re.MatchString
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownOp, op)
+	}
+
+	return func(item model.Item) bool {
+		return lo.SomeBy(values(item), check)
+	}, nil
+}
+
+func fieldValues(field string) (func(item model.Item) []string, error) {
+	switch field {
+	case model.FilterFieldTitle:
+		return func(item model.Item) []string { return []string{item.Title} }, nil
+	case model.FilterFieldSummary:
+		return func(item model.Item) []string { return []string{item.Summary} }, nil
+	case model.FilterFieldCategories:
+		return func(item model.Item) []string { return item.Categories }, nil
+	case model.FilterFieldLink:
+		return func(item model.Item) []string { return []string{item.Link} }, nil
+	case model.FilterFieldDomain:
+		return func(item model.Item) []string { return []string{domainOf(item.Link)} }, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownField, field)
+	}
+}
+
+func domainOf(link string) string {
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
@@ -0,0 +1,267 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+const (
+	include = model.FilterActionInclude
+	exclude = model.FilterActionExclude
+)
+
+var item = model.Item{
+	Title:      "Go 1.21 Release Notes",
+	Categories: []string{"Go", "Release"},
+	Link:       "https://www.go.dev/doc/go1.21?utm_source=rss",
+	Summary:    "New log/slog package for structured logging.",
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name string
+		rule model.FilterRule
+		err  error
+	}{
+		{
+			name: "invalid action",
+			rule: model.FilterRule{
+				Action:    "skip",
+				Condition: model.FilterCondition{Field: model.FilterFieldTitle, Op: model.FilterOpContains, Value: "go"},
+			},
+			err: rules.ErrInvalidAction,
+		},
+		{
+			name: "empty condition",
+			rule: model.FilterRule{Action: exclude},
+			err:  rules.ErrInvalidCondition,
+		},
+		{
+			name: "ambiguous condition",
+			rule: model.FilterRule{
+				Action: exclude,
+				Condition: model.FilterCondition{
+					Any:   []model.FilterCondition{{Field: model.FilterFieldTitle, Op: model.FilterOpContains, Value: "go"}},
+					Field: model.FilterFieldTitle,
+				},
+			},
+			err: rules.ErrInvalidCondition,
+		},
+		{
+			name: "unknown field",
+			rule: model.FilterRule{
+				Action:    exclude,
+				Condition: model.FilterCondition{Field: "author", Op: model.FilterOpContains, Value: "go"},
+			},
+			err: rules.ErrUnknownField,
+		},
+		{
+			name: "unknown op",
+			rule: model.FilterRule{
+				Action:    exclude,
+				Condition: model.FilterCondition{Field: model.FilterFieldTitle, Op: "like", Value: "go"},
+			},
+			err: rules.ErrUnknownOp,
+		},
+		{
+			name: "empty value",
+			rule: model.FilterRule{
+				Action:    exclude,
+				Condition: model.FilterCondition{Field: model.FilterFieldTitle, Op: model.FilterOpEquals},
+			},
+			err: rules.ErrEmptyValue,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, rules.Validate(tt.rule), tt.err)
+		})
+	}
+
+	t.Run("invalid regex", func(t *testing.T) {
+		assert.Error(t, rules.Validate(model.FilterRule{
+			Action:    exclude,
+			Condition: model.FilterCondition{Field: model.FilterFieldTitle, Op: model.FilterOpRegex, Value: "go("},
+		}))
+	})
+}
+
+func TestSet_Evaluate(t *testing.T) {
+	check := func(field, op, value string) model.FilterCondition {
+		return model.FilterCondition{Field: field, Op: op, Value: value}
+	}
+
+	keywordsRule, ok := rules.KeywordsRule([]string{"Release"})
+	require.True(t, ok)
+
+	tests := []struct {
+		name         string
+		rules        []model.FilterRule
+		expectedRule string
+		expectedSkip bool
+	}{
+		{
+			name:         "no rules",
+			expectedRule: "",
+			expectedSkip: false,
+		},
+		{
+			name: "case insensitive contains in title",
+			rules: []model.FilterRule{
+				{Name: "release", Action: exclude, Condition: check("title", "contains", "RELEASE")},
+			},
+			expectedRule: "release",
+			expectedSkip: true,
+		},
+		{
+			name: "equals on categories",
+			rules: []model.FilterRule{
+				{Name: "rust", Action: exclude, Condition: check("categories", "equals", "rust")},
+				{Name: "go", Action: exclude, Condition: check("categories", "equals", "go")},
+			},
+			expectedRule: "go",
+			expectedSkip: true,
+		},
+		{
+			name: "domain and regex on summary",
+			rules: []model.FilterRule{
+				{
+					Name:   "slog",
+					Action: exclude,
+					Condition: model.FilterCondition{All: []model.FilterCondition{
+						check("domain", "equals", "go.dev"),
+						check("summary", "regex", `log/\w+`),
+					}},
+				},
+			},
+			expectedRule: "slog",
+			expectedSkip: true,
+		},
+		{
+			name: "not",
+			rules: []model.FilterRule{
+				{
+					Name:   "not go",
+					Action: exclude,
+					Condition: model.FilterCondition{
+						Not: &model.FilterCondition{Any: []model.FilterCondition{check("link", "contains", "go.dev")}},
+					},
+				},
+			},
+			expectedRule: "",
+			expectedSkip: false,
+		},
+		{
+			name: "first matching rule wins",
+			rules: []model.FilterRule{
+				{ID: 1, Name: "exclude releases", Action: exclude, Condition: check("title", "contains", "release"), Position: 2},
+				{ID: 2, Name: "keep go", Action: include, Condition: check("title", "contains", "go"), Position: 1},
+			},
+			expectedRule: "keep go",
+			expectedSkip: false,
+		},
+		{
+			name: "source rules go before global ones",
+			rules: []model.FilterRule{
+				{ID: 1, Name: "global", Action: exclude, Condition: check("title", "contains", "go")},
+				{ID: 2, Name: "source", SourceID: 1, Action: include, Condition: check("title", "contains", "go"), Position: 10},
+				{ID: 3, Name: "other source", SourceID: 2, Action: exclude, Condition: check("title", "contains", "go")},
+			},
+			expectedRule: "source",
+			expectedSkip: false,
+		},
+		{
+			name: "dry run rules do not decide",
+			rules: []model.FilterRule{
+				{ID: 1, Name: "dry run", Action: exclude, Condition: check("title", "contains", "go"), DryRun: true},
+			},
+			expectedRule: "",
+			expectedSkip: false,
+		},
+		{
+			name:         "keywords rule",
+			rules:        []model.FilterRule{keywordsRule},
+			expectedRule: rules.KeywordsRuleName,
+			expectedSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled := make([]*rules.Rule, 0, len(tt.rules))
+
+			for _, rule := range tt.rules {
+				r, err := rules.Compile(rule)
+				require.NoError(t, err)
+
+				compiled = append(compiled, r)
+			}
+
+			decision := rules.NewSet(compiled...).Evaluate(1, item)
+
+			assert.Equal(t, tt.expectedSkip, decision.Skip())
+
+			if tt.expectedRule == "" {
+				assert.Nil(t, decision.Rule)
+				return
+			}
+
+			require.NotNil(t, decision.Rule)
+			assert.Equal(t, tt.expectedRule, decision.Rule.Name)
+		})
+	}
+
+	t.Run("dry run matches are reported", func(t *testing.T) {
+		rule, err := rules.Compile(model.FilterRule{
+			Name:      "dry run",
+			Action:    exclude,
+			Condition: check("title", "contains", "go"),
+			DryRun:    true,
+		})
+		require.NoError(t, err)
+
+		decision := rules.NewSet(rule).Evaluate(1, item)
+
+		require.Len(t, decision.DryRun, 1)
+		assert.Equal(t, "dry run", decision.DryRun[0].Name)
+	})
+}
+
+func TestBuild(t *testing.T) {
+	set, err := rules.Build([]model.FilterRule{
+		{ID: 1, Name: "broken", Action: exclude},
+		{
+			ID:        2,
+			Name:      "keep releases",
+			Action:    include,
+			Condition: model.FilterCondition{Field: model.FilterFieldTitle, Op: model.FilterOpContains, Value: "notes"},
+			Position:  1,
+		},
+	}, []string{"go"})
+
+	assert.ErrorIs(t, err, rules.ErrInvalidCondition)
+	assert.ErrorContains(t, err, `rule "broken" (#1)`)
+
+	decision := set.Evaluate(1, item)
+	require.NotNil(t, decision.Rule)
+	assert.Equal(t, "keep releases", decision.Rule.Name)
+
+	decision = set.Evaluate(1, model.Item{Title: "Why Go is great"})
+	require.NotNil(t, decision.Rule)
+	assert.Equal(t, rules.KeywordsRuleName, decision.Rule.Name)
+}
+
+func TestBuild_BlankKeywords(t *testing.T) {
+	_, ok := rules.KeywordsRule([]string{"", " "})
+	assert.False(t, ok)
+
+	set, err := rules.Build(nil, []string{"", " "})
+	require.NoError(t, err)
+	assert.Nil(t, set.Evaluate(1, item).Rule)
+}
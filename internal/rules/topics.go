@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var ErrEmptyTopic = errors.New("topic must have keywords, categories or pattern")
+
+// Topic is a compiled topic ready to be matched against items.
+type Topic struct {
+	model.Topic
+	keywords []string
+	pattern  *regexp.Regexp
+}
+
+// CompileTopic validates the topic and prepares it for matching.
+func CompileTopic(topic model.Topic) (*Topic, error) {
+	compiled := &Topic{
+		Topic: topic,
+		keywords: lo.FilterMap(topic.Keywords, func(keyword string, _ int) (string, bool) {
+			keyword = strings.ToLower(strings.TrimSpace(keyword))
+			return keyword, keyword != ""
+		}),
+	}
+
+	if topic.Pattern != "" {
+		pattern, err := regexp.Compile(topic.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex %q: %w", topic.Pattern, err)
+		}
+
+		compiled.pattern = pattern
+	}
+
+	if len(compiled.keywords) == 0 && len(topic.Categories) == 0 && compiled.pattern == nil {
+		return nil, ErrEmptyTopic
+	}
+
+	return compiled, nil
+}
+
+// ValidateTopic reports whether the topic can be compiled.
+func ValidateTopic(topic model.Topic) error {
+	_, err := CompileTopic(topic)
+	return err
+}
+
+func (t *Topic) match(item model.Item) bool {
+	var (
+		title   = strings.ToLower(item.Title)
+		summary = strings.ToLower(item.Summary)
+	)
+
+	for _, keyword := range t.keywords {
+		if strings.Contains(title, keyword) || strings.Contains(summary, keyword) {
+			return true
+		}
+	}
+
+	for _, category := range t.Categories {
+		if lo.ContainsBy(item.Categories, func(c string) bool { return strings.EqualFold(c, category) }) {
+			return true
+		}
+	}
+
+	return t.pattern != nil && (t.pattern.MatchString(item.Title) || t.pattern.MatchString(item.Summary))
+}
+
+// Topics is a set of topics of interest used in allow-list mode.
+type Topics struct {
+	topics []*Topic
+}
+
+// BuildTopics compiles topics. Invalid topics are left out of the set and reported in the returned error.
+func BuildTopics(topics []model.Topic) (*Topics, error) {
+	var (
+		compiled = make([]*Topic, 0, len(topics))
+		errs     []error
+	)
+
+	for _, topic := range topics {
+		t, err := CompileTopic(topic)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("topic %q (#%d): %w", topic.Name, topic.ID, err))
+			continue
+		}
+
+		compiled = append(compiled, t)
+	}
+
+	return &Topics{topics: compiled}, errors.Join(errs...)
+}
+
+// Match returns global topics and topics of the source which the item matches.
+func (t *Topics) Match(sourceID int64, item model.Item) []model.Topic {
+	if t == nil {
+		return nil
+	}
+
+	var matched []model.Topic
+
+	for _, topic := range t.topics {
+		if topic.SourceID != 0 && topic.SourceID != sourceID {
+			continue
+		}
+
+		if topic.match(item) {
+			matched = append(matched, topic.Topic)
+		}
+	}
+
+	return matched
+}
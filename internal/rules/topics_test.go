@@ -0,0 +1,41 @@
+package rules_test
+
+import (
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/rules"
+)
+
+func TestValidateTopic(t *testing.T) {
+	assert.ErrorIs(t, rules.ValidateTopic(model.Topic{Name: "empty", Keywords: []string{" "}}), rules.ErrEmptyTopic)
+	assert.Error(t, rules.ValidateTopic(model.Topic{Name: "broken", Pattern: "go("}))
+	assert.NoError(t, rules.ValidateTopic(model.Topic{Name: "go", Keywords: []string{"golang"}}))
+}
+
+func TestTopics_Match(t *testing.T) {
+	topics, err := rules.BuildTopics([]model.Topic{
+		{ID: 1, Name: "logging", Keywords: []string{"Structured Logging"}},
+		{ID: 2, Name: "releases", Categories: []string{"release"}},
+		{ID: 3, Name: "versions", Pattern: `\bGo 1\.\d+\b`},
+		{ID: 4, Name: "rust", Keywords: []string{"rust"}},
+		{ID: 5, Name: "other source", SourceID: 2, Keywords: []string{"go"}},
+		{ID: 6, Name: "this source", SourceID: 1, Keywords: []string{"go"}},
+		{ID: 7, Name: "broken"},
+	})
+	assert.ErrorIs(t, err, rules.ErrEmptyTopic)
+
+	matched := topics.Match(1, item)
+
+	assert.ElementsMatch(
+		t,
+		[]string{"logging", "releases", "versions", "this source"},
+		lo.Map(matched, func(topic model.Topic, _ int) string { return topic.Name }),
+	)
+
+	require.Empty(t, topics.Match(1, model.Item{Title: "Weekly digest"}))
+}
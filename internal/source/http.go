@@ -0,0 +1,125 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const (
+	DefaultUserAgent   = "news-feed-bot/1.0 (+https://github.com/defer-panic/news-feed-bot)"
+	DefaultMaxBodySize = 10 << 20 // 10 MiB
+)
+
+var ErrResponseTooLarge = errors.New("response body is too large")
+
+// HTTPClient performs conditional GET requests, so feeds which
+// did not change since the previous fetch are not downloaded again.
+// All requests are bound to the context passed, so they are cancelled
+// as soon as the fetch is timed out.
+type HTTPClient struct {
+	client      *http.Client
+	userAgent   string
+	maxBodySize int64
+}
+
+// NewHTTPClient creates client with given User-Agent and response size limit.
+// Empty userAgent and non-positive maxBodySize fall back to defaults.
+func NewHTTPClient(client *http.Client, userAgent string, maxBodySize int64) *HTTPClient {
+	if userAgent == "" {
+		userAgent = DefaultUserAgent
+	}
+
+	if maxBodySize <= 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+
+	return &HTTPClient{
+		client:      client,
+		userAgent:   userAgent,
+		maxBodySize: maxBodySize,
+	}
+}
+
+var defaultHTTPClient = NewHTTPClient(http.DefaultClient, "", 0)
+
+type Response struct {
+	Body        []byte
+	NotModified bool
+	Validators  model.CacheValidators
+}
+
+// Get fetches url sending If-None-Match/If-Modified-Since headers built from validators.
+// If server responds with 304, Response.NotModified is set and validators are kept as is.
+func (c *HTTPClient) Get(
+	ctx context.Context,
+	url string,
+	accept string,
+	validators model.CacheValidators,
+) (*Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", c.userAgent)
+
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+
+	if validators.ETag != "" {
+		req.Header.Set("If-None-Match", validators.ETag)
+	}
+
+	if validators.LastModified != "" {
+		req.Header.Set("If-Modified-Since", validators.LastModified)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return &Response{NotModified: true, Validators: validators}, nil
+	case http.StatusOK:
+	default:
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	if resp.ContentLength > c.maxBodySize {
+		return nil, ErrResponseTooLarge
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBodySize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(len(body)) > c.maxBodySize {
+		return nil, ErrResponseTooLarge
+	}
+
+	return &Response{
+		Body: body,
+		Validators: model.CacheValidators{
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		},
+	}, nil
+}
+
+func clientOrDefault(client *HTTPClient) *HTTPClient {
+	if client != nil {
+		return client
+	}
+
+	return defaultHTTPClient
+}
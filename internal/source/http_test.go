@@ -0,0 +1,101 @@
+package source_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+func TestHTTPClient_Get(t *testing.T) {
+	t.Run("should send user agent and validators", func(t *testing.T) {
+		var headers http.Header
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			headers = r.Header.Clone()
+			w.WriteHeader(http.StatusNotModified)
+		}))
+		defer ts.Close()
+
+		var (
+			client     = source.NewHTTPClient(http.DefaultClient, "test-agent/1.0", 0)
+			validators = model.CacheValidators{ETag: `"abc"`, LastModified: "Sun, 19 Mar 2023 07:04:42 GMT"}
+		)
+
+		resp, err := client.Get(context.Background(), ts.URL, "application/xml", validators)
+		require.NoError(t, err)
+		assert.True(t, resp.NotModified)
+		assert.Equal(t, validators, resp.Validators)
+		assert.Equal(t, "test-agent/1.0", headers.Get("User-Agent"))
+		assert.Equal(t, "application/xml", headers.Get("Accept"))
+		assert.Equal(t, `"abc"`, headers.Get("If-None-Match"))
+		assert.Equal(t, "Sun, 19 Mar 2023 07:04:42 GMT", headers.Get("If-Modified-Since"))
+	})
+
+	t.Run("should use default user agent", func(t *testing.T) {
+		var userAgent string
+
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userAgent = r.UserAgent()
+		}))
+		defer ts.Close()
+
+		_, err := source.NewHTTPClient(http.DefaultClient, "", 0).
+			Get(context.Background(), ts.URL, "", model.CacheValidators{})
+		require.NoError(t, err)
+		assert.Equal(t, source.DefaultUserAgent, userAgent)
+	})
+
+	t.Run("should limit response size", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// chunked response without Content-Length
+			for i := 0; i < 4; i++ {
+				_, _ = w.Write([]byte(strings.Repeat("x", 512)))
+				w.(http.Flusher).Flush()
+			}
+		}))
+		defer ts.Close()
+
+		client := source.NewHTTPClient(http.DefaultClient, "", 1024)
+
+		_, err := client.Get(context.Background(), ts.URL, "", model.CacheValidators{})
+		assert.ErrorIs(t, err, source.ErrResponseTooLarge)
+
+		client = source.NewHTTPClient(http.DefaultClient, "", 2048)
+
+		resp, err := client.Get(context.Background(), ts.URL, "", model.CacheValidators{})
+		require.NoError(t, err)
+		assert.Len(t, resp.Body, 2048)
+	})
+
+	t.Run("should be cancelled with context", func(t *testing.T) {
+		var (
+			release = make(chan struct{})
+			ts      = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+			}))
+		)
+		defer ts.Close()
+		defer close(release)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		started := time.Now()
+
+		_, err := source.NewHTTPClient(http.DefaultClient, "", 0).Get(ctx, ts.URL, "", model.CacheValidators{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(started), time.Second)
+	})
+}
@@ -0,0 +1,142 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// JSONFeedSource fetches items from feeds in JSON Feed format (https://jsonfeed.org).
+type JSONFeedSource struct {
+	URL        string
+	SourceID   int64
+	SourceName string
+	Validators model.CacheValidators
+	Client     *HTTPClient
+}
+
+func NewJSONFeedSourceFromModel(m model.Source) *JSONFeedSource {
+	return &JSONFeedSource{
+		URL:        m.FeedURL,
+		SourceID:   m.ID,
+		SourceName: m.Name,
+		Validators: m.Validators,
+	}
+}
+
+func (s *JSONFeedSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	feed, err := s.loadFeed(ctx, s.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if feed == nil {
+		return nil, nil
+	}
+
+	return lo.Map(feed.Items, func(item jsonFeedItem, _ int) model.Item {
+		return model.Item{
+			Title:      strings.TrimSpace(item.Title),
+			Categories: item.Tags,
+			Link:       item.link(),
+			Date:       item.date(),
+			SourceName: s.SourceName,
+			Summary:    strings.TrimSpace(item.summary()),
+		}
+	}), nil
+}
+
+func (s *JSONFeedSource) ID() int64 {
+	return s.SourceID
+}
+
+func (s *JSONFeedSource) Name() string {
+	return s.SourceName
+}
+
+func (s *JSONFeedSource) CacheValidators() model.CacheValidators {
+	return s.Validators
+}
+
+// loadFeed returns nil feed if it was not modified since the previous fetch.
+func (s *JSONFeedSource) loadFeed(ctx context.Context, url string) (*jsonFeed, error) {
+	resp, err := clientOrDefault(s.Client).Get(ctx, url, "application/feed+json, application/json", s.Validators)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.NotModified {
+		return nil, nil
+	}
+
+	var feed jsonFeed
+	if err := json.Unmarshal(resp.Body, &feed); err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(feed.Version, "https://jsonfeed.org/version/") {
+		return nil, fmt.Errorf("unsupported json feed version %q", feed.Version)
+	}
+
+	s.Validators = resp.Validators
+
+	return &feed, nil
+}
+
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	ID            string   `json:"id"`
+	URL           string   `json:"url"`
+	ExternalURL   string   `json:"external_url"`
+	Title         string   `json:"title"`
+	ContentHTML   string   `json:"content_html"`
+	ContentText   string   `json:"content_text"`
+	Summary       string   `json:"summary"`
+	DatePublished string   `json:"date_published"`
+	DateModified  string   `json:"date_modified"`
+	Tags          []string `json:"tags"`
+}
+
+func (i jsonFeedItem) link() string {
+	if i.URL != "" {
+		return i.URL
+	}
+
+	return i.ExternalURL
+}
+
+func (i jsonFeedItem) summary() string {
+	switch {
+	case i.Summary != "":
+		return i.Summary
+	case i.ContentHTML != "":
+		return i.ContentHTML
+	default:
+		return i.ContentText
+	}
+}
+
+func (i jsonFeedItem) date() time.Time {
+	for _, value := range []string{i.DatePublished, i.DateModified} {
+		if value == "" {
+			continue
+		}
+
+		if date, err := time.Parse(time.RFC3339, value); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
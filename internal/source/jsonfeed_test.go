@@ -0,0 +1,82 @@
+package source_test
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+//go:embed testdata/feed.json
+var jsonFeed []byte
+
+func TestJSONFeedSource_Fetch(t *testing.T) {
+	var (
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/feed+json; charset=utf-8")
+			_, _ = w.Write(jsonFeed)
+		}))
+		src = &source.JSONFeedSource{
+			URL:        ts.URL,
+			SourceName: "The Go Blog",
+		}
+		expected = []model.Item{
+			{
+				Title:      "Go 1.20 is released!",
+				Categories: []string{"release", "go"},
+				Link:       "https://go.dev/blog/go1.20",
+				Date:       parseRFC3339(t, "2023-02-01T10:00:00-05:00"),
+				Summary:    "Go 1.20 brings PGO, faster builds, and various tool, language, and library improvements.",
+				SourceName: "The Go Blog",
+			},
+			{
+				Title:      "Profile-guided optimization preview",
+				Categories: []string{"compiler", "performance"},
+				Link:       "https://go.dev/blog/pgo-preview",
+				Date:       parseRFC3339(t, "2023-02-08T00:00:00Z"),
+				Summary:    "<p>When you build a Go binary, the Go compiler performs optimizations.</p>",
+				SourceName: "The Go Blog",
+			},
+			{
+				Title:      "Code coverage for Go integration tests",
+				Link:       "https://go.dev/blog/integration-test-coverage",
+				Date:       parseRFC3339(t, "2023-03-08T12:30:00Z"),
+				Summary:    "Code coverage tools help developers determine what fraction of a source code base is executed.",
+				SourceName: "The Go Blog",
+			},
+		}
+	)
+	defer ts.Close()
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, expected, items)
+}
+
+func TestJSONFeedSource_Fetch_UnsupportedVersion(t *testing.T) {
+	var (
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"version": "1.0", "items": []}`))
+		}))
+		src = &source.JSONFeedSource{URL: ts.URL}
+	)
+	defer ts.Close()
+
+	_, err := src.Fetch(context.Background())
+	require.Error(t, err)
+}
+
+func parseRFC3339(t *testing.T, dateStr string) time.Time {
+	date, err := time.Parse(time.RFC3339, dateStr)
+	require.NoError(t, err)
+
+	return date
+}
@@ -14,22 +14,29 @@ type RSSSource struct {
 	URL        string
 	SourceID   int64
 	SourceName string
+	Validators model.CacheValidators
+	Client     *HTTPClient
 }
 
-func NewRSSSourceFromModel(m model.Source) RSSSource {
-	return RSSSource{
+func NewRSSSourceFromModel(m model.Source) *RSSSource {
+	return &RSSSource{
 		URL:        m.FeedURL,
 		SourceID:   m.ID,
 		SourceName: m.Name,
+		Validators: m.Validators,
 	}
 }
 
-func (s RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
+func (s *RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
 	feed, err := s.loadFeed(ctx, s.URL)
 	if err != nil {
 		return nil, err
 	}
 
+	if feed == nil {
+		return nil, nil
+	}
+
 	return lo.Map(feed.Items, func(item *rss.Item, _ int) model.Item {
 		return model.Item{
 			Title:      item.Title,
@@ -42,35 +49,40 @@ func (s RSSSource) Fetch(ctx context.Context) ([]model.Item, error) {
 	}), nil
 }
 
-func (s RSSSource) ID() int64 {
+func (s *RSSSource) ID() int64 {
 	return s.SourceID
 }
 
-func (s RSSSource) Name() string {
+func (s *RSSSource) Name() string {
 	return s.SourceName
 }
 
-func (s RSSSource) loadFeed(ctx context.Context, url string) (*rss.Feed, error) {
-	var (
-		feedCh = make(chan *rss.Feed)
-		errCh  = make(chan error)
+func (s *RSSSource) CacheValidators() model.CacheValidators {
+	return s.Validators
+}
+
+// loadFeed returns nil feed if it was not modified since the previous fetch.
+func (s *RSSSource) loadFeed(ctx context.Context, url string) (*rss.Feed, error) {
+	resp, err := clientOrDefault(s.Client).Get(
+		ctx,
+		url,
+		"application/rss+xml, application/atom+xml, application/xml, text/xml",
+		s.Validators,
 	)
+	if err != nil {
+		return nil, err
+	}
 
-	go func() {
-		feed, err := rss.Fetch(url)
-		if err != nil {
-			errCh <- err
-			return
-		}
-		feedCh <- feed
-	}()
+	if resp.NotModified {
+		return nil, nil
+	}
 
-	select {
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	case err := <-errCh:
+	feed, err := rss.Parse(resp.Body)
+	if err != nil {
 		return nil, err
-	case feed := <-feedCh:
-		return feed, nil
 	}
+
+	s.Validators = resp.Validators
+
+	return feed, nil
 }
@@ -64,3 +64,39 @@ func parseDate(t *testing.T, dateStr string) time.Time {
 
 	return date
 }
+
+func TestRSSSource_Fetch_Conditional(t *testing.T) {
+	const etag = `"feed-v1"`
+
+	var (
+		ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == etag {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+
+			w.Header().Add("Content-Type", "application/xml; charset=utf-8")
+			w.Header().Add("ETag", etag)
+			w.Header().Add("Last-Modified", "Sun, 19 Mar 2023 07:04:42 GMT")
+			_, _ = w.Write(feed)
+		}))
+		src = &source.RSSSource{
+			URL:        ts.URL,
+			SourceName: "dev.to",
+		}
+	)
+	defer ts.Close()
+
+	items, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, model.CacheValidators{
+		ETag:         etag,
+		LastModified: "Sun, 19 Mar 2023 07:04:42 GMT",
+	}, src.CacheValidators())
+
+	items, err = src.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, items)
+	assert.Equal(t, etag, src.CacheValidators().ETag)
+}
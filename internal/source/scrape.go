@@ -0,0 +1,273 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"golang.org/x/net/html"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+var ErrNoSelectors = errors.New("scrape selectors are not configured")
+
+var defaultDateLayouts = []string{
+	time.RFC3339,
+	time.RFC1123Z,
+	time.RFC1123,
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"January 2, 2006",
+	"Jan 2, 2006",
+	"2 January 2006",
+	"02.01.2006",
+}
+
+// HTMLScrapeSource extracts items from a regular HTML page using CSS selectors.
+type HTMLScrapeSource struct {
+	URL        string
+	SourceID   int64
+	SourceName string
+	Selectors  *model.ScrapeSelectors
+	Validators model.CacheValidators
+	Client     *HTTPClient
+}
+
+func NewHTMLScrapeSourceFromModel(m model.Source) *HTMLScrapeSource {
+	return &HTMLScrapeSource{
+		URL:        m.FeedURL,
+		SourceID:   m.ID,
+		SourceName: m.Name,
+		Selectors:  m.Selectors,
+		Validators: m.Validators,
+	}
+}
+
+func (s *HTMLScrapeSource) Fetch(ctx context.Context) ([]model.Item, error) {
+	if s.Selectors == nil {
+		return nil, ErrNoSelectors
+	}
+
+	selectors, err := compileScrapeSelectors(*s.Selectors)
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL, err := url.Parse(s.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := s.loadPage(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if doc == nil {
+		return nil, nil
+	}
+
+	var items []model.Item
+
+	for _, node := range selectors.item.MatchAll(doc) {
+		item, ok := selectors.extract(node, baseURL, s.Selectors.DateLayout)
+		if !ok {
+			continue
+		}
+
+		item.SourceName = s.SourceName
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+func (s *HTMLScrapeSource) ID() int64 {
+	return s.SourceID
+}
+
+func (s *HTMLScrapeSource) Name() string {
+	return s.SourceName
+}
+
+func (s *HTMLScrapeSource) CacheValidators() model.CacheValidators {
+	return s.Validators
+}
+
+// loadPage returns nil document if the page was not modified since the previous fetch.
+func (s *HTMLScrapeSource) loadPage(ctx context.Context) (*html.Node, error) {
+	resp, err := clientOrDefault(s.Client).Get(ctx, s.URL, "text/html", s.Validators)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.NotModified {
+		return nil, nil
+	}
+
+	doc, err := html.Parse(bytes.NewReader(resp.Body))
+	if err != nil {
+		return nil, err
+	}
+
+	s.Validators = resp.Validators
+
+	return doc, nil
+}
+
+// ValidateScrapeSelectors checks that all selectors are syntactically correct
+// and the required ones are present.
+func ValidateScrapeSelectors(selectors model.ScrapeSelectors) error {
+	_, err := compileScrapeSelectors(selectors)
+	return err
+}
+
+type compiledSelectors struct {
+	item    cascadia.Selector
+	title   cascadia.Selector
+	link    cascadia.Selector
+	date    cascadia.Selector
+	summary cascadia.Selector
+}
+
+func compileScrapeSelectors(selectors model.ScrapeSelectors) (*compiledSelectors, error) {
+	if selectors.Item == "" {
+		return nil, errors.New("item selector is required")
+	}
+
+	if selectors.Title == "" {
+		return nil, errors.New("title selector is required")
+	}
+
+	var (
+		compiled compiledSelectors
+		err      error
+	)
+
+	for _, sel := range []struct {
+		dst  *cascadia.Selector
+		name string
+		src  string
+	}{
+		{&compiled.item, "item", selectors.Item},
+		{&compiled.title, "title", selectors.Title},
+		{&compiled.link, "link", firstNonEmpty(selectors.Link, "a[href]")},
+		{&compiled.date, "date", selectors.Date},
+		{&compiled.summary, "summary", selectors.Summary},
+	} {
+		if sel.src == "" {
+			continue
+		}
+
+		if *sel.dst, err = cascadia.Compile(sel.src); err != nil {
+			return nil, fmt.Errorf("invalid %s selector %q: %w", sel.name, sel.src, err)
+		}
+	}
+
+	return &compiled, nil
+}
+
+func (c *compiledSelectors) extract(node *html.Node, baseURL *url.URL, dateLayout string) (model.Item, bool) {
+	var item model.Item
+
+	titleNode := c.title.MatchFirst(node)
+	if titleNode == nil {
+		return item, false
+	}
+
+	item.Title = nodeText(titleNode)
+
+	linkNode := c.link.MatchFirst(node)
+	if linkNode == nil {
+		return item, false
+	}
+
+	href := attr(linkNode, "href")
+	if href == "" {
+		href = nodeText(linkNode)
+	}
+
+	link, err := baseURL.Parse(href)
+	if err != nil {
+		return item, false
+	}
+
+	item.Link = link.String()
+
+	if c.date != nil {
+		if dateNode := c.date.MatchFirst(node); dateNode != nil {
+			item.Date = parseDate(firstNonEmpty(attr(dateNode, "datetime"), nodeText(dateNode)), dateLayout)
+		}
+	}
+
+	if c.summary != nil {
+		if summaryNode := c.summary.MatchFirst(node); summaryNode != nil {
+			item.Summary = nodeText(summaryNode)
+		}
+	}
+
+	return item, item.Title != "" && item.Link != ""
+}
+
+func parseDate(value, layout string) time.Time {
+	layouts := defaultDateLayouts
+	if layout != "" {
+		layouts = []string{layout}
+	}
+
+	for _, layout := range layouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+
+	return time.Time{}
+}
+
+func nodeText(node *html.Node) string {
+	var (
+		sb   strings.Builder
+		walk func(n *html.Node)
+	)
+
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			sb.WriteString(" ")
+		}
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+
+	walk(node)
+
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+func attr(node *html.Node, name string) string {
+	for _, a := range node.Attr {
+		if a.Key == name {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,75 @@
+package source_test
+
+import (
+	"context"
+	_ "embed"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/source"
+)
+
+//go:embed testdata/page.html
+var page []byte
+
+func TestHTMLScrapeSource_Fetch(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(page)
+	}))
+	defer ts.Close()
+
+	t.Run("should extract items with selectors", func(t *testing.T) {
+		var (
+			src = &source.HTMLScrapeSource{
+				URL:        ts.URL + "/blog/",
+				SourceName: "Engineering Blog",
+				Selectors: &model.ScrapeSelectors{
+					Item:    "article.post",
+					Title:   ".post-title",
+					Link:    ".post-title a",
+					Date:    "time",
+					Summary: ".post-excerpt",
+				},
+			}
+			expected = []model.Item{
+				{
+					Title:      "Zero downtime migrations in Postgres",
+					Link:       ts.URL + "/posts/zero-downtime-migrations",
+					Date:       parseRFC3339(t, "2023-03-15T09:30:00Z"),
+					Summary:    "How we run schema migrations on a busy database without locking tables.",
+					SourceName: "Engineering Blog",
+				},
+				{
+					Title:      "Profiling Go services in production",
+					Link:       "https://example.com/posts/profiling-go",
+					Date:       parseRFC3339(t, "2023-03-10T00:00:00Z"),
+					Summary:    "Continuous profiling with pprof and a bit of sampling.",
+					SourceName: "Engineering Blog",
+				},
+			}
+		)
+
+		items, err := src.Fetch(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, expected, items)
+	})
+
+	t.Run("should fail without selectors", func(t *testing.T) {
+		src := &source.HTMLScrapeSource{URL: ts.URL}
+
+		_, err := src.Fetch(context.Background())
+		assert.ErrorIs(t, err, source.ErrNoSelectors)
+	})
+}
+
+func TestValidateScrapeSelectors(t *testing.T) {
+	assert.NoError(t, source.ValidateScrapeSelectors(model.ScrapeSelectors{Item: "article", Title: "h2"}))
+	assert.Error(t, source.ValidateScrapeSelectors(model.ScrapeSelectors{Title: "h2"}))
+	assert.Error(t, source.ValidateScrapeSelectors(model.ScrapeSelectors{Item: "article[", Title: "h2"}))
+}
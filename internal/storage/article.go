@@ -3,9 +3,13 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/samber/lo"
 
 	"github.com/defer-panic/news-feed-bot/internal/model"
@@ -19,31 +23,254 @@ func NewArticleStorage(db *sqlx.DB) *ArticlePostgresStorage {
 	return &ArticlePostgresStorage{db: db}
 }
 
-func (s *ArticlePostgresStorage) Store(ctx context.Context, article model.Article) error {
+// Store stores the article unless an article with the same canonical link is already stored.
+// Reports whether the article was stored.
+func (s *ArticlePostgresStorage) Store(ctx context.Context, article model.Article) (bool, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 	defer conn.Close()
 
-	if _, err := conn.ExecContext(
+	result, err := conn.ExecContext(
 		ctx,
-		`INSERT INTO articles (source_id, title, link, summary, published_at)
-	    				VALUES ($1, $2, $3, $4, $5)
+		`INSERT INTO articles (source_id, title, link, canonical_link, summary, categories, simhash, published_at)
+	    				VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	    				ON CONFLICT DO NOTHING;`,
 		article.SourceID,
 		article.Title,
 		article.Link,
+		article.CanonicalLink,
 		article.Summary,
+		dbCategories(article.Categories),
+		dbSimHash(article.SimHash),
 		article.PublishedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CanonicalizeLinks fills canonical links of articles stored before links were canonicalized and then drops
+// the unique constraint of raw links, which is kept until that, so those articles are not stored again
+// when they are re-fetched. Does nothing once the constraint is dropped. If several articles have
+// the same canonical link, only one of them gets it, the rest keep their raw links.
+func (s *ArticlePostgresStorage) CanonicalizeLinks(ctx context.Context, canonicalize func(string) string) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var pending bool
+
+	if err := tx.GetContext(
+		ctx,
+		&pending,
+		`SELECT EXISTS (SELECT 1 FROM pg_constraint WHERE conrelid = 'articles'::regclass AND conname = 'articles_link_key');`,
 	); err != nil {
 		return err
 	}
 
+	if !pending {
+		return nil
+	}
+
+	var articles []struct {
+		ID            int64  `db:"id"`
+		Link          string `db:"link"`
+		CanonicalLink string `db:"canonical_link"`
+	}
+
+	if err := tx.SelectContext(ctx, &articles, `SELECT id, link, canonical_link FROM articles ORDER BY id;`); err != nil {
+		return err
+	}
+
+	var canonicalized int64
+
+	for _, article := range articles {
+		canonicalLink := canonicalize(article.Link)
+		if canonicalLink == article.CanonicalLink {
+			continue
+		}
+
+		result, err := tx.ExecContext(
+			ctx,
+			`UPDATE articles SET canonical_link = $1
+				WHERE id = $2 AND NOT EXISTS (SELECT 1 FROM articles WHERE canonical_link = $1);`,
+			canonicalLink,
+			article.ID,
+		)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		canonicalized += affected
+	}
+
+	if _, err := tx.ExecContext(ctx, `ALTER TABLE articles DROP CONSTRAINT articles_link_key;`); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "links of stored articles are canonicalized", "articles", canonicalized)
+
 	return nil
 }
 
-func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Time, limit uint64) ([]model.Article, error) {
+// RecentFingerprints returns fingerprints of articles stored since given time.
+func (s *ArticlePostgresStorage) RecentFingerprints(
+	ctx context.Context,
+	since time.Time,
+) ([]model.ArticleFingerprint, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var fingerprints []dbArticleFingerprint
+
+	if err := conn.SelectContext(
+		ctx,
+		&fingerprints,
+		`SELECT a.canonical_link, a.title, a.simhash, a.source_id, s.priority AS source_priority
+			FROM articles a JOIN sources s ON s.id = a.source_id
+			WHERE a.created_at >= $1::timestamp;`,
+		since.UTC().Format(time.RFC3339),
+	); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(fingerprints, func(fingerprint dbArticleFingerprint, _ int) model.ArticleFingerprint {
+		return model.ArticleFingerprint{
+			CanonicalLink:  fingerprint.CanonicalLink,
+			Title:          fingerprint.Title,
+			SimHash:        uint64(fingerprint.SimHash.Int64),
+			SourceID:       fingerprint.SourceID,
+			SourcePriority: fingerprint.SourcePriority,
+		}
+	}), nil
+}
+
+// StoreDuplicate links duplicate to the stored article with canonical link originalLink, so the story is posted once.
+// If duplicate comes from a source with higher priority and the article is not posted to any channel yet,
+// duplicate becomes the main version of the article and the original one is linked to it instead;
+// the summary of the original is dropped then, so it's generated for the new text.
+// If there is no article with originalLink (e.g. it has not been stored yet), duplicate is stored as is.
+func (s *ArticlePostgresStorage) StoreDuplicate(ctx context.Context, originalLink string, duplicate model.Article) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var original dbDuplicateCandidate
+
+	err = tx.GetContext(
+		ctx,
+		&original,
+		`SELECT
+				a.id,
+				a.source_id,
+				a.link,
+				EXISTS (SELECT 1 FROM deliveries WHERE article_id = a.id) AS posted,
+				s.priority AS source_priority,
+				(SELECT priority FROM sources WHERE id = $2) AS duplicate_priority,
+				EXISTS (SELECT 1 FROM articles WHERE canonical_link = $3) AS duplicate_stored
+			FROM articles a JOIN sources s ON s.id = a.source_id
+			WHERE a.canonical_link = $1
+			FOR UPDATE OF a;`,
+		originalLink,
+		duplicate.SourceID,
+		duplicate.CanonicalLink,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		_, err := s.Store(ctx, duplicate)
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if original.DuplicateStored || original.SourceID == duplicate.SourceID {
+		return nil
+	}
+
+	if original.Posted || original.DuplicatePriority <= original.SourcePriority {
+		if err := linkArticleSource(ctx, tx, original.ID, duplicate.SourceID, duplicate.Link); err != nil {
+			return err
+		}
+
+		return tx.Commit()
+	}
+
+	if err := linkArticleSource(ctx, tx, original.ID, original.SourceID, original.Link); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`DELETE FROM article_sources WHERE article_id = $1 AND source_id = $2;`,
+		original.ID,
+		duplicate.SourceID,
+	); err != nil {
+		return err
+	}
+
+	// the summary of the replaced version doesn't match the text of the new one
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE articles
+			SET source_id = $1, title = $2, link = $3, canonical_link = $4, summary = $5, categories = $6, simhash = $7,
+				ai_summary = NULL,
+				ai_summary_model = NULL,
+				ai_summary_prompt_hash = NULL,
+				ai_summary_generated_at = NULL,
+				ai_summary_attempts = 0,
+				ai_summary_claimed_at = NULL
+			WHERE id = $8;`,
+		duplicate.SourceID,
+		duplicate.Title,
+		duplicate.Link,
+		duplicate.CanonicalLink,
+		duplicate.Summary,
+		dbCategories(duplicate.Categories),
+		dbSimHash(duplicate.SimHash),
+		original.ID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// NotDelivered returns articles published since given time which are not delivered to the channel yet.
+// If sourceIDs is not nil, only articles of these sources are returned.
+func (s *ArticlePostgresStorage) NotDelivered(
+	ctx context.Context,
+	channelID int64,
+	sourceIDs []int64,
+	since time.Time,
+	limit uint64,
+	offset uint64,
+) ([]model.Article, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
 		return nil, err
@@ -59,36 +286,38 @@ func (s *ArticlePostgresStorage) AllNotPosted(ctx context.Context, since time.Ti
 				a.id AS a_id, 
 				s.priority AS s_priority,
 				s.id AS s_id,
+				s.name AS s_name,
 				a.title AS a_title,
 				a.link AS a_link,
 				a.summary AS a_summary,
+				a.ai_summary AS a_ai_summary,
+				a.ai_summary_model AS a_ai_summary_model,
+				a.ai_summary_prompt_hash AS a_ai_summary_prompt_hash,
+				a.ai_summary_generated_at AS a_ai_summary_generated_at,
+				a.ai_summary_attempts AS a_ai_summary_attempts,
+				a.categories AS a_categories,
+				a.queue_priority AS a_queue_priority,
 				a.published_at AS a_published_at,
-				a.posted_at AS a_posted_at,
 				a.created_at AS a_created_at
 			FROM articles a JOIN sources s ON s.id = a.source_id
-			WHERE a.posted_at IS NULL 
-				AND a.published_at >= $1::timestamp
-			ORDER BY a.created_at DESC, s_priority DESC LIMIT $2;`,
+			WHERE NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.article_id = a.id AND d.channel_id = $1)
+				AND a.skipped_at IS NULL
+				AND ($2::bigint[] IS NULL OR a.source_id = ANY($2))
+				AND a.published_at >= $3::timestamp
+			ORDER BY a.queue_priority DESC, a.created_at DESC, s_priority DESC, a.id DESC LIMIT $4 OFFSET $5;`,
+		channelID,
+		pq.Int64Array(sourceIDs),
 		since.UTC().Format(time.RFC3339),
 		limit,
+		offset,
 	); err != nil {
 		return nil, err
 	}
 
-	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article {
-		return model.Article{
-			ID:          article.ID,
-			SourceID:    article.SourceID,
-			Title:       article.Title,
-			Link:        article.Link,
-			Summary:     article.Summary.String,
-			PublishedAt: article.PublishedAt,
-			CreatedAt:   article.CreatedAt,
-		}
-	}), nil
+	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article { return article.toModel() }), nil
 }
 
-func (s *ArticlePostgresStorage) MarkAsPosted(ctx context.Context, article model.Article) error {
+func (s *ArticlePostgresStorage) MarkAsDelivered(ctx context.Context, channelID int64, article model.Article) error {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
 		return err
@@ -97,9 +326,12 @@ func (s *ArticlePostgresStorage) MarkAsPosted(ctx context.Context, article model
 
 	if _, err := conn.ExecContext(
 		ctx,
-		`UPDATE articles SET posted_at = $1::timestamp WHERE id = $2;`,
-		time.Now().UTC().Format(time.RFC3339),
+		`INSERT INTO deliveries (article_id, channel_id, delivered_at)
+					VALUES ($1, $2, $3::timestamp)
+					ON CONFLICT DO NOTHING;`,
 		article.ID,
+		channelID,
+		time.Now().UTC().Format(time.RFC3339),
 	); err != nil {
 		return err
 	}
@@ -107,14 +339,445 @@ func (s *ArticlePostgresStorage) MarkAsPosted(ctx context.Context, article model
 	return nil
 }
 
+// Posted returns the latest articles posted to the channel, or to any channel if channelID is zero.
+func (s *ArticlePostgresStorage) Posted(ctx context.Context, channelID int64, limit uint64) ([]model.Article, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var articles []dbArticleWithPriority
+
+	if err := conn.SelectContext(
+		ctx,
+		&articles,
+		`SELECT
+				a.id AS a_id,
+				s.priority AS s_priority,
+				s.id AS s_id,
+				s.name AS s_name,
+				a.title AS a_title,
+				a.link AS a_link,
+				a.summary AS a_summary,
+				a.ai_summary AS a_ai_summary,
+				a.categories AS a_categories,
+				a.published_at AS a_published_at,
+				d.posted_at AS a_posted_at,
+				a.created_at AS a_created_at
+			FROM (
+				SELECT article_id, MIN(delivered_at) AS posted_at
+				FROM deliveries
+				WHERE $1 = 0 OR channel_id = $1
+				GROUP BY article_id
+			) d
+				JOIN articles a ON a.id = d.article_id
+				JOIN sources s ON s.id = a.source_id
+			ORDER BY d.posted_at DESC, a.id DESC LIMIT $2;`,
+		channelID,
+		limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article { return article.toModel() }), nil
+}
+
+// ClaimSummary counts an attempt to summarize the article and reports whether the caller may generate the summary.
+// The article can't be claimed if it's summarized already, attempts are exhausted, or it's claimed by someone else
+// after staleBefore, so the summary isn't generated (and paid for) twice at once.
+func (s *ArticlePostgresStorage) ClaimSummary(
+	ctx context.Context,
+	articleID int64,
+	maxAttempts int,
+	staleBefore time.Time,
+) (bool, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var id int64
+
+	err = conn.GetContext(
+		ctx,
+		&id,
+		`UPDATE articles
+			SET ai_summary_attempts = ai_summary_attempts + 1,
+				ai_summary_claimed_at = $1::timestamp
+			WHERE id = $2
+				AND ai_summary IS NULL
+				AND ai_summary_attempts < $3
+				AND (ai_summary_claimed_at IS NULL OR ai_summary_claimed_at < $4::timestamp)
+			RETURNING id`,
+		time.Now().UTC().Format(time.RFC3339),
+		articleID,
+		maxAttempts,
+		staleBefore.UTC().Format(time.RFC3339),
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SaveAISummary stores the summary generated for the article, so it's generated once for all channels.
+func (s *ArticlePostgresStorage) SaveAISummary(ctx context.Context, articleID int64, summary model.Summary) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`UPDATE articles
+			SET ai_summary = $1,
+				ai_summary_model = $2,
+				ai_summary_prompt_hash = $3,
+				ai_summary_generated_at = $4::timestamp,
+				ai_summary_claimed_at = NULL
+			WHERE id = $5`,
+		summary.Text,
+		summary.Model,
+		summary.PromptHash,
+		time.Now().UTC().Format(time.RFC3339),
+		articleID,
+	)
+
+	return err
+}
+
+// ReleaseSummary releases the claim of the article which failed to be summarized, so it's retried
+// by the next attempt. The failed attempt is already counted by ClaimSummary.
+func (s *ArticlePostgresStorage) ReleaseSummary(ctx context.Context, articleID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `UPDATE articles SET ai_summary_claimed_at = NULL WHERE id = $1`, articleID)
+
+	return err
+}
+
+// Unsummarized returns articles published since given time which are not summarized yet and
+// failed to be summarized less than maxAttempts times, in the order they are going to be posted.
+func (s *ArticlePostgresStorage) Unsummarized(
+	ctx context.Context,
+	since time.Time,
+	maxAttempts int,
+	limit uint64,
+) ([]model.Article, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var articles []dbArticleWithPriority
+
+	if err := conn.SelectContext(
+		ctx,
+		&articles,
+		`SELECT `+articleColumns+`
+			WHERE a.ai_summary IS NULL
+				AND a.ai_summary_attempts < $1
+				AND a.skipped_at IS NULL
+				AND d.posted_at IS NULL
+				AND a.published_at >= $2::timestamp
+			ORDER BY a.queue_priority DESC, a.created_at DESC, s.priority DESC LIMIT $3;`,
+		maxAttempts,
+		since.UTC().Format(time.RFC3339),
+		limit,
+	); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article { return article.toModel() }), nil
+}
+
+// articleColumns selects articles joined with their sources and first deliveries as d.
+const articleColumns = `
+				a.id AS a_id,
+				s.priority AS s_priority,
+				s.id AS s_id,
+				s.name AS s_name,
+				a.title AS a_title,
+				a.link AS a_link,
+				a.summary AS a_summary,
+				a.ai_summary AS a_ai_summary,
+				a.ai_summary_model AS a_ai_summary_model,
+				a.ai_summary_prompt_hash AS a_ai_summary_prompt_hash,
+				a.ai_summary_generated_at AS a_ai_summary_generated_at,
+				a.ai_summary_attempts AS a_ai_summary_attempts,
+				a.categories AS a_categories,
+				a.queue_priority AS a_queue_priority,
+				a.published_at AS a_published_at,
+				d.posted_at AS a_posted_at,
+				a.skipped_at AS a_skipped_at,
+				a.created_at AS a_created_at
+			FROM articles a
+				JOIN sources s ON s.id = a.source_id
+				LEFT JOIN (
+					SELECT article_id, MIN(delivered_at) AS posted_at FROM deliveries GROUP BY article_id
+				) d ON d.article_id = a.id`
+
+// Articles returns articles matching the query, latest first.
+func (s *ArticlePostgresStorage) Articles(ctx context.Context, query model.ArticleQuery) ([]model.Article, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var articles []dbArticleWithPriority
+
+	if err := conn.SelectContext(
+		ctx,
+		&articles,
+		`SELECT `+articleColumns+`
+			WHERE ($1::text = '' OR a.title ILIKE $1 OR a.summary ILIKE $1)
+				AND ($2::bigint = 0 OR a.source_id = $2)
+				AND CASE $3::text
+					WHEN 'pending' THEN d.posted_at IS NULL AND a.skipped_at IS NULL
+					WHEN 'posted' THEN d.posted_at IS NOT NULL
+					WHEN 'skipped' THEN a.skipped_at IS NOT NULL
+					ELSE TRUE
+				END
+			ORDER BY a.created_at DESC, a.id DESC LIMIT $4 OFFSET $5;`,
+		likePattern(query.Text),
+		query.SourceID,
+		query.Status,
+		query.Limit,
+		query.Offset,
+	); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(articles, func(article dbArticleWithPriority, _ int) model.Article { return article.toModel() }), nil
+}
+
+func (s *ArticlePostgresStorage) ArticleByID(ctx context.Context, id int64) (*model.Article, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var article dbArticleWithPriority
+	if err := conn.GetContext(ctx, &article, `SELECT `+articleColumns+` WHERE a.id = $1`, id); err != nil {
+		return nil, err
+	}
+
+	m := article.toModel()
+
+	return &m, nil
+}
+
+// MarkAsPosted marks the article as delivered to the channel, or to all channels if channelID is zero,
+// without posting it.
+func (s *ArticlePostgresStorage) MarkAsPosted(ctx context.Context, articleID, channelID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`INSERT INTO deliveries (article_id, channel_id, delivered_at)
+					SELECT $1, c.id, $3::timestamp FROM channels c WHERE $2 = 0 OR c.id = $2
+					ON CONFLICT DO NOTHING;`,
+		articleID,
+		channelID,
+		time.Now().UTC().Format(time.RFC3339),
+	); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "article is marked as posted", "article_id", articleID, "channel_id", channelID)
+
+	return nil
+}
+
+// Skip marks the article as skipped, so it's never posted.
+func (s *ArticlePostgresStorage) Skip(ctx context.Context, articleID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(
+		ctx,
+		`UPDATE articles SET skipped_at = COALESCE(skipped_at, $1::timestamp) WHERE id = $2`,
+		time.Now().UTC().Format(time.RFC3339),
+		articleID,
+	); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "article is skipped", "article_id", articleID)
+
+	return nil
+}
+
+// SetQueuePriority moves the article in the queue of articles to be posted:
+// articles with higher priority are posted first.
+func (s *ArticlePostgresStorage) SetQueuePriority(ctx context.Context, articleID int64, priority int) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `UPDATE articles SET queue_priority = $1 WHERE id = $2`, priority, articleID)
+
+	return err
+}
+
+// QueueDepth returns the number of articles published since given time which are not posted yet, by channel.
+// Articles of sources the channel is subscribed to are counted before filtering with rules of the channel.
+func (s *ArticlePostgresStorage) QueueDepth(ctx context.Context, since time.Time) ([]model.QueueDepth, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var depths []struct {
+		ChannelID   int64  `db:"channel_id"`
+		ChannelName string `db:"channel_name"`
+		Depth       int    `db:"depth"`
+	}
+
+	if err := conn.SelectContext(
+		ctx,
+		&depths,
+		`SELECT c.id AS channel_id, c.name AS channel_name, COUNT(a.id) AS depth
+			FROM channels c
+				LEFT JOIN articles a ON a.published_at >= $1::timestamp
+					AND a.skipped_at IS NULL
+					AND (c.all_sources OR a.source_id IN (SELECT source_id FROM channel_sources WHERE channel_id = c.id))
+					AND NOT EXISTS (SELECT 1 FROM deliveries d WHERE d.article_id = a.id AND d.channel_id = c.id)
+			GROUP BY c.id
+			ORDER BY c.id;`,
+		since.UTC().Format(time.RFC3339),
+	); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.QueueDepth, 0, len(depths))
+	for _, depth := range depths {
+		result = append(result, model.QueueDepth{
+			ChannelID:   depth.ChannelID,
+			ChannelName: depth.ChannelName,
+			Depth:       depth.Depth,
+		})
+	}
+
+	return result, nil
+}
+
+// likePattern turns text into ILIKE pattern matching strings containing it.
+func likePattern(text string) string {
+	if text == "" {
+		return ""
+	}
+
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 type dbArticleWithPriority struct {
 	ID             int64          `db:"a_id"`
 	SourcePriority int64          `db:"s_priority"`
 	SourceID       int64          `db:"s_id"`
+	SourceName     string         `db:"s_name"`
 	Title          string         `db:"a_title"`
 	Link           string         `db:"a_link"`
 	Summary        sql.NullString `db:"a_summary"`
+	AISummary      sql.NullString `db:"a_ai_summary"`
+	AISummaryModel sql.NullString `db:"a_ai_summary_model"`
+	PromptHash     sql.NullString `db:"a_ai_summary_prompt_hash"`
+	GeneratedAt    sql.NullTime   `db:"a_ai_summary_generated_at"`
+	SummaryFails   int            `db:"a_ai_summary_attempts"`
+	Categories     pq.StringArray `db:"a_categories"`
+	QueuePriority  int            `db:"a_queue_priority"`
 	PublishedAt    time.Time      `db:"a_published_at"`
 	PostedAt       sql.NullTime   `db:"a_posted_at"`
+	SkippedAt      sql.NullTime   `db:"a_skipped_at"`
 	CreatedAt      time.Time      `db:"a_created_at"`
 }
+
+func (a dbArticleWithPriority) toModel() model.Article {
+	return model.Article{
+		ID:                   a.ID,
+		SourceID:             a.SourceID,
+		SourceName:           a.SourceName,
+		Title:                a.Title,
+		Link:                 a.Link,
+		Summary:              a.Summary.String,
+		AISummary:            a.AISummary.String,
+		AISummaryModel:       a.AISummaryModel.String,
+		AISummaryPromptHash:  a.PromptHash.String,
+		AISummaryGeneratedAt: a.GeneratedAt.Time,
+		AISummaryAttempts:    a.SummaryFails,
+		Categories:           a.Categories,
+		QueuePriority:        a.QueuePriority,
+		PublishedAt:          a.PublishedAt,
+		PostedAt:             a.PostedAt.Time,
+		SkippedAt:            a.SkippedAt.Time,
+		CreatedAt:            a.CreatedAt,
+	}
+}
+
+func linkArticleSource(ctx context.Context, tx *sqlx.Tx, articleID, sourceID int64, link string) error {
+	_, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO article_sources (article_id, source_id, link)
+					VALUES ($1, $2, $3)
+					ON CONFLICT DO NOTHING;`,
+		articleID,
+		sourceID,
+		link,
+	)
+
+	return err
+}
+
+type dbArticleFingerprint struct {
+	CanonicalLink  string        `db:"canonical_link"`
+	Title          string        `db:"title"`
+	SimHash        sql.NullInt64 `db:"simhash"`
+	SourceID       int64         `db:"source_id"`
+	SourcePriority int           `db:"source_priority"`
+}
+
+type dbDuplicateCandidate struct {
+	ID                int64  `db:"id"`
+	SourceID          int64  `db:"source_id"`
+	Link              string `db:"link"`
+	Posted            bool   `db:"posted"`
+	SourcePriority    int    `db:"source_priority"`
+	DuplicatePriority int    `db:"duplicate_priority"`
+	DuplicateStored   bool   `db:"duplicate_stored"`
+}
+
+// dbSimHash stores SimHash bits as BIGINT, zero hash (no content to compare) is stored as NULL.
+func dbSimHash(simHash uint64) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(simHash), Valid: simHash != 0}
+}
+
+func dbCategories(categories []string) pq.StringArray {
+	return lo.Ternary(categories == nil, []string{}, categories)
+}
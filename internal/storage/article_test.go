@@ -0,0 +1,76 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/storage"
+)
+
+func TestArticlePostgresStorage_StoreDuplicate(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		db       = newTestDB(t)
+		sources  = storage.NewSourceStorage(db)
+		articles = storage.NewArticleStorage(db)
+	)
+
+	weeklyID, err := sources.Add(ctx, model.Source{Name: "Gopher Weekly", FeedURL: "https://weekly.example.com", Priority: 10})
+	require.NoError(t, err)
+
+	blogID, err := sources.Add(ctx, model.Source{Name: "Go Blog", FeedURL: "https://go.dev/blog/feed.atom", Priority: 100})
+	require.NoError(t, err)
+
+	original := model.Article{
+		SourceID:      weeklyID,
+		Title:         "Go 1.21 is out",
+		Link:          "https://weekly.example.com/go1.21",
+		CanonicalLink: "https://weekly.example.com/go1.21",
+		Summary:       "Go 1.21 is out, read more on the blog.",
+		PublishedAt:   time.Now().UTC(),
+	}
+
+	stored, err := articles.Store(ctx, original)
+	require.NoError(t, err)
+	require.True(t, stored)
+
+	queue, err := articles.Unsummarized(ctx, time.Now().Add(-time.Hour), 3, 10)
+	require.NoError(t, err)
+	require.Len(t, queue, 1)
+
+	id := queue[0].ID
+
+	claimed, err := articles.ClaimSummary(ctx, id, 3, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.True(t, claimed)
+	require.NoError(t, articles.SaveAISummary(ctx, id, model.Summary{Text: "Go 1.21 is out.", Model: "gpt"}))
+
+	// the duplicate from the source with higher priority replaces the original
+	require.NoError(t, articles.StoreDuplicate(ctx, original.CanonicalLink, model.Article{
+		SourceID:      blogID,
+		Title:         "Go 1.21 is released",
+		Link:          "https://go.dev/blog/go1.21",
+		CanonicalLink: "https://go.dev/blog/go1.21",
+		Summary:       "Go 1.21 adds min, max and clear builtins.",
+		PublishedAt:   time.Now().UTC(),
+	}))
+
+	article, err := articles.ArticleByID(ctx, id)
+	require.NoError(t, err)
+
+	assert.Equal(t, blogID, article.SourceID)
+	assert.Equal(t, "Go 1.21 is released", article.Title)
+	assert.Equal(t, "https://go.dev/blog/go1.21", article.Link)
+	assert.Empty(t, article.AISummary, "summary of the replaced version must be dropped")
+	assert.Zero(t, article.AISummaryAttempts)
+
+	// the article is summarized anew
+	claimed, err = articles.ClaimSummary(ctx, id, 3, time.Now())
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
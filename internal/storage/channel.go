@@ -0,0 +1,353 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// Postgres error codes of constraint violations.
+const (
+	foreignKeyViolation = "23503"
+	uniqueViolation     = "23505"
+)
+
+var (
+	// ErrChatBound means that another channel is added for the chat of the default channel.
+	ErrChatBound = errors.New("chat is already bound to another channel")
+	// ErrDefaultChannel means that the default channel can't be deleted.
+	ErrDefaultChannel = errors.New("default channel can't be deleted")
+)
+
+type ChannelPostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewChannelStorage(db *sqlx.DB) *ChannelPostgresStorage {
+	return &ChannelPostgresStorage{db: db}
+}
+
+// Channels returns all channels with their source subscriptions and filter rules.
+func (s *ChannelPostgresStorage) Channels(ctx context.Context) ([]model.Channel, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var channels []dbChannel
+	if err := conn.SelectContext(
+		ctx,
+		&channels,
+		`SELECT c.*, d.last_posted_at
+			FROM channels c
+			LEFT JOIN (
+				SELECT channel_id, MAX(delivered_at) AS last_posted_at FROM deliveries GROUP BY channel_id
+			) d ON d.channel_id = c.id
+			ORDER BY c.id`,
+	); err != nil {
+		return nil, err
+	}
+
+	var subscriptions []dbChannelSource
+	if err := conn.SelectContext(ctx, &subscriptions, `SELECT * FROM channel_sources ORDER BY source_id`); err != nil {
+		return nil, err
+	}
+
+	var rules []dbFilterRule
+	if err := conn.SelectContext(
+		ctx,
+		&rules,
+		`SELECT * FROM filter_rules WHERE channel_id IS NOT NULL ORDER BY position, id`,
+	); err != nil {
+		return nil, err
+	}
+
+	var (
+		sourcesByChannel = make(map[int64][]int64)
+		rulesByChannel   = make(map[int64][]model.FilterRule)
+	)
+
+	for _, subscription := range subscriptions {
+		sourcesByChannel[subscription.ChannelID] = append(sourcesByChannel[subscription.ChannelID], subscription.SourceID)
+	}
+
+	for _, rule := range rules {
+		m, err := rule.toModel()
+		if err != nil {
+			return nil, err
+		}
+
+		rulesByChannel[m.ChannelID] = append(rulesByChannel[m.ChannelID], m)
+	}
+
+	return lo.Map(channels, func(channel dbChannel, _ int) model.Channel {
+		m := channel.toModel()
+		m.SourceIDs = sourcesByChannel[m.ID]
+		m.Rules = rulesByChannel[m.ID]
+
+		return m
+	}), nil
+}
+
+func (s *ChannelPostgresStorage) Add(ctx context.Context, channel model.Channel) (int64, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var id int64
+
+	row := conn.QueryRowxContext(
+		ctx,
+		`INSERT INTO channels (kind, chat_id, url, room_id, access_token, name, post_interval_sec, all_sources)
+					VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id;`,
+		channel.Kind,
+		sql.NullInt64{Int64: channel.ChatID, Valid: channel.ChatID != 0},
+		channel.URL,
+		channel.RoomID,
+		channel.AccessToken,
+		channel.Name,
+		int64(channel.PostInterval.Seconds()),
+		channel.AllSources,
+	)
+
+	if err := row.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// BindDefault sets chat of the default channel, which receives articles posted before channels were introduced.
+// Fails with ErrChatBound if another channel is already added for the chat.
+func (s *ChannelPostgresStorage) BindDefault(ctx context.Context, chatID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `UPDATE channels SET chat_id = $1 WHERE is_default`, chatID)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrChatBound
+	}
+
+	return err
+}
+
+// Subscribe subscribes the channel to the source. Fails with sql.ErrNoRows if either of them doesn't exist.
+func (s *ChannelPostgresStorage) Subscribe(ctx context.Context, channelID, sourceID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`INSERT INTO channel_sources (channel_id, source_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+		channelID,
+		sourceID,
+	)
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+		return sql.ErrNoRows
+	}
+
+	return err
+}
+
+// Unsubscribe unsubscribes the channel from the source. Fails with sql.ErrNoRows if it's not subscribed.
+func (s *ChannelPostgresStorage) Unsubscribe(ctx context.Context, channelID, sourceID int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(
+		ctx,
+		`DELETE FROM channel_sources WHERE channel_id = $1 AND source_id = $2`,
+		channelID,
+		sourceID,
+	))
+}
+
+func (s *ChannelPostgresStorage) SetAllSources(ctx context.Context, id int64, allSources bool) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(ctx, `UPDATE channels SET all_sources = $1 WHERE id = $2`, allSources, id))
+}
+
+// SetDigest configures digest mode of the channel, empty schedule turns it off.
+// The next digest is posted on schedule after now.
+func (s *ChannelPostgresStorage) SetDigest(ctx context.Context, id int64, digest model.Digest) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(
+		ctx,
+		`UPDATE channels
+			SET digest_schedule = $1,
+				digest_timezone = $2,
+				digest_size = $3,
+				digest_group_by = $4,
+				digest_sent_at = $5::timestamp
+			WHERE id = $6`,
+		digest.Schedule,
+		digest.Timezone,
+		digest.Size,
+		digest.GroupBy,
+		time.Now().UTC().Format(time.RFC3339),
+		id,
+	))
+}
+
+// RecordDigest marks articles of the digest as delivered to the channel and remembers when the digest was sent.
+// Both are done in a single transaction, so articles of a sent digest are never posted again.
+func (s *ChannelPostgresStorage) RecordDigest(
+	ctx context.Context,
+	channelID int64,
+	sentAt time.Time,
+	articles []model.Article,
+) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`INSERT INTO deliveries (article_id, channel_id, delivered_at)
+					SELECT id, $2, $3::timestamp FROM unnest($1::bigint[]) AS id
+					ON CONFLICT DO NOTHING;`,
+		pq.Int64Array(lo.Map(articles, func(article model.Article, _ int) int64 { return article.ID })),
+		channelID,
+		sentAt.UTC().Format(time.RFC3339),
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(
+		ctx,
+		`UPDATE channels SET digest_sent_at = $1::timestamp WHERE id = $2`,
+		sentAt.UTC().Format(time.RFC3339),
+		channelID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetDigestSentAt remembers when the digest was sent without marking its articles as delivered.
+func (s *ChannelPostgresStorage) SetDigestSentAt(ctx context.Context, channelID int64, sentAt time.Time) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`UPDATE channels SET digest_sent_at = $1::timestamp WHERE id = $2`,
+		sentAt.UTC().Format(time.RFC3339),
+		channelID,
+	)
+
+	return err
+}
+
+// Delete deletes the channel. Fails with ErrDefaultChannel for the default channel,
+// which can't be deleted, and with sql.ErrNoRows if the channel doesn't exist.
+func (s *ChannelPostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var isDefault bool
+	if err := conn.GetContext(ctx, &isDefault, `SELECT is_default FROM channels WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	if isDefault {
+		return ErrDefaultChannel
+	}
+
+	return requireAffected(conn.ExecContext(ctx, `DELETE FROM channels WHERE id = $1 AND NOT is_default`, id))
+}
+
+type dbChannel struct {
+	ID              int64         `db:"id"`
+	Kind            string        `db:"kind"`
+	ChatID          sql.NullInt64 `db:"chat_id"`
+	URL             string        `db:"url"`
+	RoomID          string        `db:"room_id"`
+	AccessToken     string        `db:"access_token"`
+	Name            string        `db:"name"`
+	PostIntervalSec int64         `db:"post_interval_sec"`
+	AllSources      bool          `db:"all_sources"`
+	IsDefault       bool          `db:"is_default"`
+	DigestSchedule  string        `db:"digest_schedule"`
+	DigestTimezone  string        `db:"digest_timezone"`
+	DigestSize      int           `db:"digest_size"`
+	DigestGroupBy   string        `db:"digest_group_by"`
+	DigestSentAt    time.Time     `db:"digest_sent_at"`
+	CreatedAt       time.Time     `db:"created_at"`
+	LastPostedAt    sql.NullTime  `db:"last_posted_at"`
+}
+
+func (c dbChannel) toModel() model.Channel {
+	return model.Channel{
+		ID:           c.ID,
+		Kind:         c.Kind,
+		ChatID:       c.ChatID.Int64,
+		URL:          c.URL,
+		RoomID:       c.RoomID,
+		AccessToken:  c.AccessToken,
+		Name:         c.Name,
+		PostInterval: time.Duration(c.PostIntervalSec) * time.Second,
+		AllSources:   c.AllSources,
+		Default:      c.IsDefault,
+		Digest: model.Digest{
+			Schedule: c.DigestSchedule,
+			Timezone: c.DigestTimezone,
+			Size:     c.DigestSize,
+			GroupBy:  c.DigestGroupBy,
+			SentAt:   c.DigestSentAt,
+		},
+		LastPostedAt: c.LastPostedAt.Time,
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+type dbChannelSource struct {
+	ChannelID int64 `db:"channel_id"`
+	SourceID  int64 `db:"source_id"`
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type FilterRulePostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewFilterRuleStorage(db *sqlx.DB) *FilterRulePostgresStorage {
+	return &FilterRulePostgresStorage{db: db}
+}
+
+// FilterRules returns all rules ordered by position.
+func (s *FilterRulePostgresStorage) FilterRules(ctx context.Context) ([]model.FilterRule, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var rules []dbFilterRule
+	if err := conn.SelectContext(ctx, &rules, `SELECT * FROM filter_rules ORDER BY position, id`); err != nil {
+		return nil, err
+	}
+
+	result := make([]model.FilterRule, 0, len(rules))
+
+	for _, rule := range rules {
+		m, err := rule.toModel()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, m)
+	}
+
+	return result, nil
+}
+
+func (s *FilterRulePostgresStorage) Add(ctx context.Context, rule model.FilterRule) (int64, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	condition, err := json.Marshal(toDBFilterCondition(rule.Condition))
+	if err != nil {
+		return 0, err
+	}
+
+	var id int64
+
+	row := conn.QueryRowxContext(
+		ctx,
+		`INSERT INTO filter_rules (source_id, channel_id, name, action, condition, position, dry_run)
+					VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id;`,
+		sql.NullInt64{Int64: rule.SourceID, Valid: rule.SourceID != 0},
+		sql.NullInt64{Int64: rule.ChannelID, Valid: rule.ChannelID != 0},
+		rule.Name,
+		rule.Action,
+		condition,
+		rule.Position,
+		rule.DryRun,
+	)
+
+	if err := row.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (s *FilterRulePostgresStorage) SetDryRun(ctx context.Context, id int64, dryRun bool) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(ctx, `UPDATE filter_rules SET dry_run = $1 WHERE id = $2`, dryRun, id)
+
+	return err
+}
+
+func (s *FilterRulePostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM filter_rules WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type dbFilterRule struct {
+	ID        int64         `db:"id"`
+	SourceID  sql.NullInt64 `db:"source_id"`
+	ChannelID sql.NullInt64 `db:"channel_id"`
+	Name      string        `db:"name"`
+	Action    string        `db:"action"`
+	Condition []byte        `db:"condition"`
+	Position  int           `db:"position"`
+	DryRun    bool          `db:"dry_run"`
+	CreatedAt time.Time     `db:"created_at"`
+}
+
+func (r dbFilterRule) toModel() (model.FilterRule, error) {
+	var condition dbFilterCondition
+	if err := json.Unmarshal(r.Condition, &condition); err != nil {
+		return model.FilterRule{}, err
+	}
+
+	return model.FilterRule{
+		ID:        r.ID,
+		SourceID:  r.SourceID.Int64,
+		ChannelID: r.ChannelID.Int64,
+		Name:      r.Name,
+		Action:    r.Action,
+		Condition: condition.toModel(),
+		Position:  r.Position,
+		DryRun:    r.DryRun,
+		CreatedAt: r.CreatedAt,
+	}, nil
+}
+
+type dbFilterCondition struct {
+	All   []dbFilterCondition `json:"all,omitempty"`
+	Any   []dbFilterCondition `json:"any,omitempty"`
+	Not   *dbFilterCondition  `json:"not,omitempty"`
+	Field string              `json:"field,omitempty"`
+	Op    string              `json:"op,omitempty"`
+	Value string              `json:"value,omitempty"`
+}
+
+func toDBFilterCondition(condition model.FilterCondition) dbFilterCondition {
+	c := dbFilterCondition{
+		All:   lo.Map(condition.All, func(c model.FilterCondition, _ int) dbFilterCondition { return toDBFilterCondition(c) }),
+		Any:   lo.Map(condition.Any, func(c model.FilterCondition, _ int) dbFilterCondition { return toDBFilterCondition(c) }),
+		Field: condition.Field,
+		Op:    condition.Op,
+		Value: condition.Value,
+	}
+
+	if condition.Not != nil {
+		not := toDBFilterCondition(*condition.Not)
+		c.Not = &not
+	}
+
+	return c
+}
+
+func (c dbFilterCondition) toModel() model.FilterCondition {
+	condition := model.FilterCondition{
+		All:   lo.Map(c.All, func(c dbFilterCondition, _ int) model.FilterCondition { return c.toModel() }),
+		Any:   lo.Map(c.Any, func(c dbFilterCondition, _ int) model.FilterCondition { return c.toModel() }),
+		Field: c.Field,
+		Op:    c.Op,
+		Value: c.Value,
+	}
+
+	if c.Not != nil {
+		not := c.Not.toModel()
+		condition.Not = &not
+	}
+
+	return condition
+}
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN etag          VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN last_modified VARCHAR(64)  NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN etag,
+    DROP COLUMN last_modified;
+-- +goose StatementEnd
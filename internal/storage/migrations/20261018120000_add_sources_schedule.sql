@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN fetch_interval_sec    INT,
+    ADD COLUMN adaptive_fetch        BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN adaptive_interval_sec INT     NOT NULL DEFAULT 0,
+    ADD COLUMN next_fetch_at         TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN fetch_interval_sec,
+    DROP COLUMN adaptive_fetch,
+    DROP COLUMN adaptive_interval_sec,
+    DROP COLUMN next_fetch_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources
+    ADD COLUMN last_success_at      TIMESTAMP,
+    ADD COLUMN last_error           TEXT NOT NULL DEFAULT '',
+    ADD COLUMN last_error_at        TIMESTAMP,
+    ADD COLUMN consecutive_failures INT  NOT NULL DEFAULT 0,
+    ADD COLUMN disabled_at          TIMESTAMP;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE sources
+    DROP COLUMN last_success_at,
+    DROP COLUMN last_error,
+    DROP COLUMN last_error_at,
+    DROP COLUMN consecutive_failures,
+    DROP COLUMN disabled_at;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN simhash BIGINT;
+
+CREATE TABLE article_sources
+(
+    article_id BIGINT    NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    source_id  BIGINT    NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+    link       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (article_id, source_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE article_sources;
+
+ALTER TABLE articles DROP COLUMN simhash;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles ADD COLUMN canonical_link TEXT;
+
+-- links of existing articles are canonicalized by the bot on start, which drops articles_link_key afterwards,
+-- so re-fetched articles are not stored again until their canonical links are known
+UPDATE articles SET canonical_link = link;
+
+ALTER TABLE articles ALTER COLUMN canonical_link SET NOT NULL;
+ALTER TABLE articles ADD CONSTRAINT articles_canonical_link_key UNIQUE (canonical_link);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- articles with the same link may be stored since articles_link_key is dropped, the oldest one is kept
+DELETE FROM articles a USING articles b WHERE a.link = b.link AND a.id > b.id;
+
+ALTER TABLE articles DROP CONSTRAINT IF EXISTS articles_link_key;
+ALTER TABLE articles ADD CONSTRAINT articles_link_key UNIQUE (link);
+ALTER TABLE articles DROP COLUMN canonical_link;
+-- +goose StatementEnd
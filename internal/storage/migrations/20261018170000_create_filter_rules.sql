@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE filter_rules
+(
+    id         SERIAL PRIMARY KEY,
+    source_id  INT          REFERENCES sources (id) ON DELETE CASCADE,
+    name       VARCHAR(255) NOT NULL,
+    action     VARCHAR(16)  NOT NULL,
+    condition  JSONB        NOT NULL,
+    position   INT          NOT NULL DEFAULT 0,
+    dry_run    BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS filter_rules;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE sources ADD COLUMN allow_list BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE topics
+(
+    id         SERIAL PRIMARY KEY,
+    source_id  INT          REFERENCES sources (id) ON DELETE CASCADE,
+    name       VARCHAR(255) NOT NULL,
+    keywords   TEXT[]       NOT NULL DEFAULT '{}',
+    categories TEXT[]       NOT NULL DEFAULT '{}',
+    pattern    TEXT         NOT NULL DEFAULT '',
+    admitted   BIGINT       NOT NULL DEFAULT 0,
+    created_at TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS topics;
+
+ALTER TABLE sources DROP COLUMN allow_list;
+-- +goose StatementEnd
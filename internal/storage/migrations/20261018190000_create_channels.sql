@@ -0,0 +1,62 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE channels
+(
+    id                SERIAL PRIMARY KEY,
+    chat_id           BIGINT UNIQUE,
+    name              VARCHAR(255) NOT NULL,
+    post_interval_sec INT          NOT NULL DEFAULT 0,
+    all_sources       BOOLEAN      NOT NULL DEFAULT FALSE,
+    is_default        BOOLEAN      NOT NULL DEFAULT FALSE,
+    created_at        TIMESTAMP    NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX channels_is_default_key ON channels (is_default) WHERE is_default;
+
+CREATE TABLE channel_sources
+(
+    channel_id INT NOT NULL REFERENCES channels (id) ON DELETE CASCADE,
+    source_id  INT NOT NULL REFERENCES sources (id) ON DELETE CASCADE,
+    PRIMARY KEY (channel_id, source_id)
+);
+
+ALTER TABLE filter_rules ADD COLUMN channel_id INT REFERENCES channels (id) ON DELETE CASCADE;
+
+CREATE TABLE deliveries
+(
+    article_id   BIGINT    NOT NULL REFERENCES articles (id) ON DELETE CASCADE,
+    channel_id   INT       NOT NULL REFERENCES channels (id) ON DELETE CASCADE,
+    delivered_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (article_id, channel_id)
+);
+
+-- the channel from config keeps receiving articles of all sources and inherits posted state;
+-- its chat ID is set on start
+INSERT INTO channels (name, all_sources, is_default) VALUES ('default', TRUE, TRUE);
+
+INSERT INTO deliveries (article_id, channel_id, delivered_at)
+SELECT a.id, c.id, a.posted_at
+FROM articles a, channels c
+WHERE c.is_default AND a.posted_at IS NOT NULL;
+
+ALTER TABLE articles DROP COLUMN posted_at;
+ALTER TABLE articles ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE articles DROP COLUMN categories;
+ALTER TABLE articles ADD COLUMN posted_at TIMESTAMP;
+
+UPDATE articles a
+SET posted_at = d.delivered_at
+FROM deliveries d JOIN channels c ON c.id = d.channel_id
+WHERE c.is_default AND d.article_id = a.id;
+
+DROP TABLE IF EXISTS deliveries;
+
+ALTER TABLE filter_rules DROP COLUMN channel_id;
+
+DROP TABLE IF EXISTS channel_sources;
+DROP TABLE IF EXISTS channels;
+-- +goose StatementEnd
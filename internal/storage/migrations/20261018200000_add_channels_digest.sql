@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE channels ADD COLUMN digest_schedule VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN digest_timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+ALTER TABLE channels ADD COLUMN digest_size INT NOT NULL DEFAULT 10;
+ALTER TABLE channels ADD COLUMN digest_group_by VARCHAR(16) NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN digest_sent_at TIMESTAMP NOT NULL DEFAULT NOW();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN digest_sent_at;
+ALTER TABLE channels DROP COLUMN digest_group_by;
+ALTER TABLE channels DROP COLUMN digest_size;
+ALTER TABLE channels DROP COLUMN digest_timezone;
+ALTER TABLE channels DROP COLUMN digest_schedule;
+-- +goose StatementEnd
@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE channels ADD COLUMN kind VARCHAR(32) NOT NULL DEFAULT 'telegram';
+ALTER TABLE channels ADD COLUMN url TEXT NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN room_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE channels ADD COLUMN access_token TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE channels DROP COLUMN access_token;
+ALTER TABLE channels DROP COLUMN room_id;
+ALTER TABLE channels DROP COLUMN url;
+ALTER TABLE channels DROP COLUMN kind;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE articles
+    ADD COLUMN ai_summary_model TEXT,
+    ADD COLUMN ai_summary_prompt_hash TEXT,
+    ADD COLUMN ai_summary_generated_at TIMESTAMP,
+    ADD COLUMN ai_summary_attempts INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE articles
+    DROP COLUMN ai_summary_model,
+    DROP COLUMN ai_summary_prompt_hash,
+    DROP COLUMN ai_summary_generated_at,
+    DROP COLUMN ai_summary_attempts;
+-- +goose StatementEnd
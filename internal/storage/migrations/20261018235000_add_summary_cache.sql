@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE summary_cache
+(
+    key         TEXT PRIMARY KEY,
+    summary     TEXT      NOT NULL,
+    model       TEXT      NOT NULL,
+    prompt_hash TEXT      NOT NULL,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX summary_cache_created_at_idx ON summary_cache (created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS summary_cache;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX deliveries_channel_id_delivered_at_idx ON deliveries (channel_id, delivered_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS deliveries_channel_id_delivered_at_idx;
+-- +goose StatementEnd
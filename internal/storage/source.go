@@ -2,10 +2,13 @@ package storage
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	"github.com/samber/lo"
 
 	"github.com/defer-panic/news-feed-bot/internal/model"
 )
@@ -18,6 +21,8 @@ func NewSourceStorage(db *sqlx.DB) *SourcePostgresStorage {
 	return &SourcePostgresStorage{db: db}
 }
 
+// Sources returns all sources. Sources which can't be read, e.g. with invalid scrape selectors,
+// are logged and skipped, so they don't stop fetching of the others.
 func (s *SourcePostgresStorage) Sources(ctx context.Context) ([]model.Source, error) {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
@@ -30,7 +35,19 @@ func (s *SourcePostgresStorage) Sources(ctx context.Context) ([]model.Source, er
 		return nil, err
 	}
 
-	return lo.Map(sources, func(source dbSource, _ int) model.Source { return model.Source(source) }), nil
+	result := make([]model.Source, 0, len(sources))
+
+	for _, source := range sources {
+		m, err := source.toModel()
+		if err != nil {
+			slog.ErrorContext(ctx, "source is skipped", "source_id", source.ID, "error", err)
+			continue
+		}
+
+		result = append(result, m)
+	}
+
+	return result, nil
 }
 
 func (s *SourcePostgresStorage) SourceByID(ctx context.Context, id int64) (*model.Source, error) {
@@ -45,7 +62,12 @@ func (s *SourcePostgresStorage) SourceByID(ctx context.Context, id int64) (*mode
 		return nil, err
 	}
 
-	return (*model.Source)(&source), nil
+	m, err := source.toModel()
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
 }
 
 func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (int64, error) {
@@ -59,9 +81,9 @@ func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (i
 
 	row := conn.QueryRowxContext(
 		ctx,
-		`INSERT INTO sources (name, feed_url, priority)
-					VALUES ($1, $2, $3) RETURNING id;`,
-		source.Name, source.FeedURL, source.Priority,
+		`INSERT INTO sources (name, feed_url, kind, priority)
+					VALUES ($1, $2, $3, $4) RETURNING id;`,
+		source.Name, source.FeedURL, source.Kind, source.Priority,
 	)
 
 	if err := row.Err(); err != nil {
@@ -75,6 +97,35 @@ func (s *SourcePostgresStorage) Add(ctx context.Context, source model.Source) (i
 	return id, nil
 }
 
+// Update updates name, URL, kind, priority and allow-list mode of the source. Cache validators are reset
+// if URL or kind is changed, as they belong to the old page.
+func (s *SourcePostgresStorage) Update(ctx context.Context, source model.Source) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(
+		ctx,
+		`UPDATE sources
+			SET name = $1,
+				feed_url = $2,
+				kind = $3,
+				priority = $4,
+				allow_list = $5,
+				etag = CASE WHEN feed_url = $2 AND kind = $3 THEN etag ELSE '' END,
+				last_modified = CASE WHEN feed_url = $2 AND kind = $3 THEN last_modified ELSE '' END
+			WHERE id = $6`,
+		source.Name,
+		source.FeedURL,
+		source.Kind,
+		source.Priority,
+		source.AllowList,
+		source.ID,
+	))
+}
+
 func (s *SourcePostgresStorage) SetPriority(ctx context.Context, id int64, priority int) error {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
@@ -82,11 +133,201 @@ func (s *SourcePostgresStorage) SetPriority(ctx context.Context, id int64, prior
 	}
 	defer conn.Close()
 
-	_, err = conn.ExecContext(ctx, `UPDATE sources SET priority = $1 WHERE id = $2`, priority, id)
+	return requireAffected(conn.ExecContext(ctx, `UPDATE sources SET priority = $1 WHERE id = $2`, priority, id))
+}
+
+// SetScrapeSelectors sets selectors of the scraped source and resets its cache validators,
+// so the page is fetched and scraped with new selectors even if it's not modified.
+func (s *SourcePostgresStorage) SetScrapeSelectors(ctx context.Context, id int64, selectors model.ScrapeSelectors) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	raw, err := json.Marshal(dbScrapeSelectors(selectors))
+	if err != nil {
+		return err
+	}
+
+	return requireAffected(conn.ExecContext(
+		ctx,
+		`UPDATE sources SET scrape_selectors = $1, etag = '', last_modified = '' WHERE id = $2`,
+		raw,
+		id,
+	))
+}
+
+func (s *SourcePostgresStorage) SetCacheValidators(
+	ctx context.Context,
+	id int64,
+	validators model.CacheValidators,
+) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`UPDATE sources SET etag = $1, last_modified = $2 WHERE id = $3`,
+		validators.ETag,
+		validators.LastModified,
+		id,
+	)
+
+	return err
+}
+
+// SetSchedule sets own fetch interval of the source (zero resets it to the default one)
+// and resets the next fetch time, so the new schedule is applied immediately.
+func (s *SourcePostgresStorage) SetSchedule(ctx context.Context, id int64, interval time.Duration, adaptive bool) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(
+		ctx,
+		`UPDATE sources
+			SET fetch_interval_sec = $1, adaptive_fetch = $2, adaptive_interval_sec = 0, next_fetch_at = NULL
+			WHERE id = $3`,
+		sql.NullInt64{Int64: int64(interval.Seconds()), Valid: interval > 0},
+		adaptive,
+		id,
+	))
+}
+
+// SetFetchTimeout sets own fetch timeout of the source, zero resets it to the default one.
+func (s *SourcePostgresStorage) SetFetchTimeout(ctx context.Context, id int64, timeout time.Duration) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(
+		ctx,
+		`UPDATE sources SET fetch_timeout_sec = $1 WHERE id = $2`,
+		sql.NullInt64{Int64: int64(timeout.Seconds()), Valid: timeout > 0},
+		id,
+	))
+}
+
+func (s *SourcePostgresStorage) SetAllowList(ctx context.Context, id int64, allowList bool) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	return requireAffected(conn.ExecContext(ctx, `UPDATE sources SET allow_list = $1 WHERE id = $2`, allowList, id))
+}
+
+func (s *SourcePostgresStorage) SetNextFetch(
+	ctx context.Context,
+	id int64,
+	nextFetchAt time.Time,
+	adaptiveInterval time.Duration,
+) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`UPDATE sources SET next_fetch_at = $1::timestamp, adaptive_interval_sec = $2 WHERE id = $3`,
+		nextFetchAt.UTC().Format(time.RFC3339),
+		int64(adaptiveInterval.Seconds()),
+		id,
+	)
 
 	return err
 }
 
+func (s *SourcePostgresStorage) RecordFetchSuccess(ctx context.Context, id int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`UPDATE sources SET last_success_at = $1::timestamp, consecutive_failures = 0 WHERE id = $2`,
+		time.Now().UTC().Format(time.RFC3339),
+		id,
+	)
+
+	return err
+}
+
+// RecordFetchFailure stores fetch error of the source and disables it if it failed
+// disableAfter times in a row (zero disableAfter means never disable). Returns true
+// if source is disabled after this failure.
+func (s *SourcePostgresStorage) RecordFetchFailure(
+	ctx context.Context,
+	id int64,
+	fetchErr string,
+	disableAfter int,
+) (bool, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	var disabled bool
+
+	if err := conn.GetContext(
+		ctx,
+		&disabled,
+		`UPDATE sources
+			SET last_error = $1,
+				last_error_at = $2::timestamp,
+				consecutive_failures = consecutive_failures + 1,
+				disabled_at = CASE
+					WHEN disabled_at IS NULL AND $3 > 0 AND consecutive_failures + 1 >= $3 THEN $2::timestamp
+					ELSE disabled_at
+				END
+			WHERE id = $4
+			RETURNING disabled_at IS NOT NULL`,
+		fetchErr,
+		time.Now().UTC().Format(time.RFC3339),
+		disableAfter,
+		id,
+	); err != nil {
+		return false, err
+	}
+
+	return disabled, nil
+}
+
+// Enable re-enables source disabled after failures and schedules it to be fetched as soon as possible.
+func (s *SourcePostgresStorage) Enable(ctx context.Context, id int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := requireAffected(conn.ExecContext(
+		ctx,
+		`UPDATE sources SET disabled_at = NULL, consecutive_failures = 0, next_fetch_at = NULL WHERE id = $1`,
+		id,
+	)); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "source is enabled", "source_id", id)
+
+	return nil
+}
+
 func (s *SourcePostgresStorage) Delete(ctx context.Context, id int64) error {
 	conn, err := s.db.Connx(ctx)
 	if err != nil {
@@ -98,13 +339,97 @@ func (s *SourcePostgresStorage) Delete(ctx context.Context, id int64) error {
 		return err
 	}
 
+	slog.InfoContext(ctx, "source is deleted", "source_id", id)
+
 	return nil
 }
 
 type dbSource struct {
-	ID        int64     `db:"id"`
-	Name      string    `db:"name"`
-	FeedURL   string    `db:"feed_url"`
-	Priority  int       `db:"priority"`
-	CreatedAt time.Time `db:"created_at"`
+	ID               int64         `db:"id"`
+	Name             string        `db:"name"`
+	FeedURL          string        `db:"feed_url"`
+	Kind             string        `db:"kind"`
+	Priority         int           `db:"priority"`
+	ScrapeSelectors  []byte        `db:"scrape_selectors"`
+	ETag             string        `db:"etag"`
+	LastModified     string        `db:"last_modified"`
+	FetchInterval    sql.NullInt64 `db:"fetch_interval_sec"`
+	AdaptiveFetch    bool          `db:"adaptive_fetch"`
+	AdaptiveInterval int64         `db:"adaptive_interval_sec"`
+	FetchTimeout     sql.NullInt64 `db:"fetch_timeout_sec"`
+	AllowList        bool          `db:"allow_list"`
+	NextFetchAt      sql.NullTime  `db:"next_fetch_at"`
+	LastSuccessAt    sql.NullTime  `db:"last_success_at"`
+	LastError        string        `db:"last_error"`
+	LastErrorAt      sql.NullTime  `db:"last_error_at"`
+	ConsecutiveFails int           `db:"consecutive_failures"`
+	DisabledAt       sql.NullTime  `db:"disabled_at"`
+	CreatedAt        time.Time     `db:"created_at"`
+}
+
+func (s dbSource) toModel() (model.Source, error) {
+	source := model.Source{
+		ID:       s.ID,
+		Name:     s.Name,
+		FeedURL:  s.FeedURL,
+		Kind:     s.Kind,
+		Priority: s.Priority,
+		Validators: model.CacheValidators{
+			ETag:         s.ETag,
+			LastModified: s.LastModified,
+		},
+		FetchInterval:    time.Duration(s.FetchInterval.Int64) * time.Second,
+		AdaptiveFetch:    s.AdaptiveFetch,
+		AdaptiveInterval: time.Duration(s.AdaptiveInterval) * time.Second,
+		FetchTimeout:     time.Duration(s.FetchTimeout.Int64) * time.Second,
+		AllowList:        s.AllowList,
+		NextFetchAt:      s.NextFetchAt.Time,
+		Health: model.SourceHealth{
+			LastSuccessAt:       s.LastSuccessAt.Time,
+			LastError:           s.LastError,
+			LastErrorAt:         s.LastErrorAt.Time,
+			ConsecutiveFailures: s.ConsecutiveFails,
+			DisabledAt:          s.DisabledAt.Time,
+		},
+		CreatedAt: s.CreatedAt,
+	}
+
+	if len(s.ScrapeSelectors) > 0 {
+		var selectors dbScrapeSelectors
+		if err := json.Unmarshal(s.ScrapeSelectors, &selectors); err != nil {
+			return model.Source{}, fmt.Errorf("invalid scrape selectors of source %d: %w", s.ID, err)
+		}
+
+		source.Selectors = (*model.ScrapeSelectors)(&selectors)
+	}
+
+	return source, nil
+}
+
+// requireAffected fails with sql.ErrNoRows if the statement updated nothing, e.g. the source doesn't exist,
+// so callers don't report success of a no-op.
+func requireAffected(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+type dbScrapeSelectors struct {
+	Item       string `json:"item"`
+	Title      string `json:"title"`
+	Link       string `json:"link"`
+	Date       string `json:"date,omitempty"`
+	DateLayout string `json:"date_layout,omitempty"`
+	Summary    string `json:"summary,omitempty"`
 }
@@ -0,0 +1,71 @@
+package storage_test
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDB connects to the database from NFB_TEST_DATABASE_DSN and migrates a schema created for the test,
+// which is dropped once the test is done. The test is skipped if the database is not configured.
+func newTestDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	dsn := os.Getenv("NFB_TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("NFB_TEST_DATABASE_DSN is not set")
+	}
+
+	admin, err := sqlx.Connect("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = admin.Close() })
+
+	schema := fmt.Sprintf("storage_test_%d", time.Now().UnixNano())
+
+	_, err = admin.Exec(`CREATE SCHEMA ` + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`) })
+
+	u, err := url.Parse(dsn)
+	require.NoError(t, err)
+
+	query := u.Query()
+	query.Set("search_path", schema)
+	u.RawQuery = query.Encode()
+
+	db, err := sqlx.Connect("postgres", u.String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	migrate(t, db)
+
+	return db
+}
+
+// migrate applies up migrations the way goose does.
+func migrate(t *testing.T, db *sqlx.DB) {
+	t.Helper()
+
+	files, err := filepath.Glob(filepath.Join("migrations", "*.sql"))
+	require.NoError(t, err)
+	sort.Strings(files)
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		require.NoError(t, err)
+
+		up, _, _ := strings.Cut(string(content), "-- +goose Down")
+
+		_, err = db.Exec(up)
+		require.NoError(t, err, "failed to apply %s", file)
+	}
+}
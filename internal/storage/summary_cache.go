@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type SummaryCachePostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewSummaryCacheStorage(db *sqlx.DB) *SummaryCachePostgresStorage {
+	return &SummaryCachePostgresStorage{db: db}
+}
+
+// Get returns the summary cached with the key since given time, nil if there is none.
+func (s *SummaryCachePostgresStorage) Get(ctx context.Context, key string, since time.Time) (*model.Summary, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var summary dbCachedSummary
+
+	if err := conn.GetContext(
+		ctx,
+		&summary,
+		`SELECT summary, model, prompt_hash FROM summary_cache WHERE key = $1 AND created_at >= $2::timestamp`,
+		key,
+		since.UTC().Format(time.RFC3339),
+	); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &model.Summary{Text: summary.Summary, Model: summary.Model, PromptHash: summary.PromptHash}, nil
+}
+
+// Put caches the summary with the key, replacing the one cached before.
+func (s *SummaryCachePostgresStorage) Put(ctx context.Context, key string, summary model.Summary) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`INSERT INTO summary_cache (key, summary, model, prompt_hash, created_at)
+					VALUES ($1, $2, $3, $4, $5::timestamp)
+					ON CONFLICT (key) DO UPDATE
+						SET summary = EXCLUDED.summary,
+							model = EXCLUDED.model,
+							prompt_hash = EXCLUDED.prompt_hash,
+							created_at = EXCLUDED.created_at;`,
+		key,
+		summary.Text,
+		summary.Model,
+		summary.PromptHash,
+		time.Now().UTC().Format(time.RFC3339),
+	)
+
+	return err
+}
+
+// Prune deletes summaries cached before given time and the oldest summaries beyond maxEntries.
+func (s *SummaryCachePostgresStorage) Prune(ctx context.Context, before time.Time, maxEntries int) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.ExecContext(
+		ctx,
+		`DELETE FROM summary_cache
+			WHERE created_at < $1::timestamp
+				OR key IN (SELECT key FROM summary_cache ORDER BY created_at DESC, key OFFSET $2);`,
+		before.UTC().Format(time.RFC3339),
+		maxEntries,
+	)
+
+	return err
+}
+
+type dbCachedSummary struct {
+	Summary    string `db:"summary"`
+	Model      string `db:"model"`
+	PromptHash string `db:"prompt_hash"`
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+type TopicPostgresStorage struct {
+	db *sqlx.DB
+}
+
+func NewTopicStorage(db *sqlx.DB) *TopicPostgresStorage {
+	return &TopicPostgresStorage{db: db}
+}
+
+func (s *TopicPostgresStorage) Topics(ctx context.Context) ([]model.Topic, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	var topics []dbTopic
+	if err := conn.SelectContext(ctx, &topics, `SELECT * FROM topics ORDER BY id`); err != nil {
+		return nil, err
+	}
+
+	return lo.Map(topics, func(topic dbTopic, _ int) model.Topic { return topic.toModel() }), nil
+}
+
+func (s *TopicPostgresStorage) Add(ctx context.Context, topic model.Topic) (int64, error) {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	var id int64
+
+	row := conn.QueryRowxContext(
+		ctx,
+		`INSERT INTO topics (source_id, name, keywords, categories, pattern)
+					VALUES ($1, $2, $3, $4, $5) RETURNING id;`,
+		sql.NullInt64{Int64: topic.SourceID, Valid: topic.SourceID != 0},
+		topic.Name,
+		pq.StringArray(lo.Ternary(topic.Keywords == nil, []string{}, topic.Keywords)),
+		pq.StringArray(lo.Ternary(topic.Categories == nil, []string{}, topic.Categories)),
+		topic.Pattern,
+	)
+
+	if err := row.Err(); err != nil {
+		return 0, err
+	}
+
+	if err := row.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// AddAdmitted increases counters of items admitted by topics.
+func (s *TopicPostgresStorage) AddAdmitted(ctx context.Context, admitted map[int64]int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	for topicID, count := range admitted {
+		if _, err := conn.ExecContext(
+			ctx,
+			`UPDATE topics SET admitted = admitted + $1 WHERE id = $2`,
+			count,
+			topicID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *TopicPostgresStorage) Delete(ctx context.Context, id int64) error {
+	conn, err := s.db.Connx(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `DELETE FROM topics WHERE id = $1`, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type dbTopic struct {
+	ID         int64          `db:"id"`
+	SourceID   sql.NullInt64  `db:"source_id"`
+	Name       string         `db:"name"`
+	Keywords   pq.StringArray `db:"keywords"`
+	Categories pq.StringArray `db:"categories"`
+	Pattern    string         `db:"pattern"`
+	Admitted   int64          `db:"admitted"`
+	CreatedAt  time.Time      `db:"created_at"`
+}
+
+func (t dbTopic) toModel() model.Topic {
+	return model.Topic{
+		ID:         t.ID,
+		SourceID:   t.SourceID.Int64,
+		Name:       t.Name,
+		Keywords:   t.Keywords,
+		Categories: t.Categories,
+		Pattern:    t.Pattern,
+		Admitted:   t.Admitted,
+		CreatedAt:  t.CreatedAt,
+	}
+}
@@ -0,0 +1,154 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const (
+	anthropicProvider = "anthropic"
+	anthropicVersion  = "2023-06-01"
+
+	// DefaultAnthropicBaseURL is the base URL of Anthropic API.
+	DefaultAnthropicBaseURL = "https://api.anthropic.com"
+)
+
+// AnthropicSummarizer generates summaries with Anthropic messages API.
+type AnthropicSummarizer struct {
+	client  *http.Client
+	apiKey  string
+	baseURL string
+	model   string
+	prompt  string
+}
+
+func NewAnthropicSummarizer(client *http.Client, apiKey, baseURL, model, prompt string) *AnthropicSummarizer {
+	if baseURL == "" {
+		baseURL = DefaultAnthropicBaseURL
+	}
+
+	slog.Info("anthropic summarizer", "enabled", apiKey != "", "model", model, "base_url", baseURL)
+
+	return &AnthropicSummarizer{
+		client:  client,
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		prompt:  prompt,
+	}
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string             `json:"model"`
+	MaxTokens int                `json:"max_tokens"`
+	System    string             `json:"system,omitempty"`
+	Messages  []anthropicMessage `json:"messages"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (s *AnthropicSummarizer) Summarize(ctx context.Context, text string) (model.Summary, error) {
+	if s.apiKey == "" {
+		return model.Summary{}, fmt.Errorf("anthropic summarizer is disabled")
+	}
+
+	started := time.Now()
+	resp, err := s.createMessage(ctx, anthropicRequest{
+		Model:     s.model,
+		MaxTokens: summaryMaxTokens,
+		System:    s.prompt,
+		Messages:  []anthropicMessage{{Role: "user", Content: text}},
+	})
+	metrics.SummaryDuration.WithLabelValues(anthropicProvider).Observe(time.Since(started).Seconds())
+
+	if err != nil {
+		metrics.SummaryErrors.WithLabelValues(anthropicProvider).Inc()
+		return model.Summary{}, err
+	}
+
+	metrics.SummaryTokens.WithLabelValues(anthropicProvider, "prompt").Add(float64(resp.Usage.InputTokens))
+	metrics.SummaryTokens.WithLabelValues(anthropicProvider, "completion").Add(float64(resp.Usage.OutputTokens))
+
+	var summary strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			summary.WriteString(block.Text)
+		}
+	}
+
+	if summary.Len() == 0 {
+		metrics.SummaryErrors.WithLabelValues(anthropicProvider).Inc()
+		return model.Summary{}, errors.New("no text in anthropic response")
+	}
+
+	return model.Summary{
+		Text:       completeSentences(summary.String()),
+		Model:      s.model,
+		PromptHash: hashPrompt(s.prompt),
+	}, nil
+}
+
+func (s *AnthropicSummarizer) createMessage(ctx context.Context, request anthropicRequest) (*anthropicResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/v1/messages", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Api-Key", s.apiKey)
+	req.Header.Set("Anthropic-Version", anthropicVersion)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode anthropic response with status %d: %w", resp.StatusCode, err)
+	}
+
+	if result.Error != nil {
+		return nil, fmt.Errorf("anthropic error %s: %s", result.Error.Type, result.Error.Message)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status of anthropic response: %d", resp.StatusCode)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,125 @@
+package summary
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// cachePruneInterval is the number of summaries cached between prunes of the cache, so the cache storage
+// isn't scanned on every miss. The cache may exceed its size by this number of summaries until pruned.
+const cachePruneInterval = 100
+
+type CacheStorage interface {
+	// Get returns the summary cached with the key since given time, nil if there is none.
+	Get(ctx context.Context, key string, since time.Time) (*model.Summary, error)
+	Put(ctx context.Context, key string, summary model.Summary) error
+	// Prune deletes summaries cached before given time and the oldest summaries beyond maxEntries.
+	Prune(ctx context.Context, before time.Time, maxEntries int) error
+}
+
+// Cache returns summaries of texts summarized before instead of summarizing them again, e.g. when the same
+// article is fetched from several sources. Summaries are cached by the model, the prompt and the text,
+// so changing any of them makes the summary generated anew.
+//
+// Cache wraps a summarizer of a single model and prompt, as they make up the key. Summarizers mixing models,
+// like Fallback, can't be cached as a whole, so each of the summarizers they combine is wrapped instead.
+type Cache struct {
+	storage    CacheStorage
+	summarizer Summarizer
+	model      string
+	prompt     string
+	ttl        time.Duration
+	maxEntries int
+	// puts counts cached summaries to prune the cache every cachePruneInterval of them.
+	puts atomic.Int64
+}
+
+// NewCache creates cache of summaries generated by the summarizer with given model and prompt.
+// Summaries are cached for ttl, only maxEntries latest summaries are kept after the cache is pruned.
+func NewCache(
+	storage CacheStorage,
+	summarizer Summarizer,
+	model string,
+	prompt string,
+	ttl time.Duration,
+	maxEntries int,
+) *Cache {
+	return &Cache{
+		storage:    storage,
+		summarizer: summarizer,
+		model:      model,
+		prompt:     prompt,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+type skipCacheKey struct{}
+
+// SkipCache makes summarizers ignore cached summaries, the new summary replaces the cached one.
+func SkipCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipCacheKey{}, true)
+}
+
+// Summarize returns the cached summary of the text or summarizes it and caches the summary.
+// The cache failing doesn't fail summarization.
+func (c *Cache) Summarize(ctx context.Context, text string) (model.Summary, error) {
+	key := c.key(text)
+
+	if skip, _ := ctx.Value(skipCacheKey{}).(bool); !skip {
+		cached, err := c.storage.Get(ctx, key, time.Now().Add(-c.ttl))
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to get cached summary", "error", err)
+		}
+
+		if cached != nil {
+			metrics.SummaryCacheHits.WithLabelValues(c.model).Inc()
+			return *cached, nil
+		}
+
+		metrics.SummaryCacheMisses.WithLabelValues(c.model).Inc()
+	}
+
+	summary, err := c.summarizer.Summarize(ctx, text)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	if err := c.storage.Put(ctx, key, summary); err != nil {
+		slog.ErrorContext(ctx, "failed to cache summary", "error", err)
+		return summary, nil
+	}
+
+	// the first put prunes summaries left by the previous run
+	if c.puts.Add(1)%cachePruneInterval != 1 {
+		return summary, nil
+	}
+
+	if err := c.storage.Prune(ctx, time.Now().Add(-c.ttl), c.maxEntries); err != nil {
+		slog.ErrorContext(ctx, "failed to prune summary cache", "error", err)
+	}
+
+	return summary, nil
+}
+
+// key identifies the text summarized with the model and the prompt. Whitespace of the text is normalized,
+// as the same article may be formatted differently by sources.
+func (c *Cache) key(text string) string {
+	h := sha256.New()
+
+	for _, part := range []string{c.model, c.prompt, strings.Join(strings.Fields(text), " ")} {
+		h.Write([]byte(part))
+		// separator keeps parts from running into each other
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
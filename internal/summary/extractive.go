@@ -0,0 +1,330 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const (
+	// extractiveModel is the model name of extractive summaries.
+	extractiveModel = "extractive"
+
+	textRankDamping    = 0.85
+	textRankIterations = 50
+	textRankTolerance  = 1e-6
+
+	// minSentenceWords filters out headings, captions and other fragments left by readability.
+	minSentenceWords = 4
+
+	stemLength = 6
+)
+
+var ErrNothingToSummarize = errors.New("no sentences to summarize")
+
+// ExtractiveSummarizer summarizes text locally, with no network, by picking its most central sentences:
+// sentences are compared by TF-IDF weights of their words except for stopwords of the text's language
+// (Russian or English) and ranked with TextRank. Picked sentences keep their order in the text,
+// so the same text always gets the same summary.
+type ExtractiveSummarizer struct {
+	sentences int
+}
+
+// NewExtractiveSummarizer creates summarizer picking up to given number of sentences.
+func NewExtractiveSummarizer(sentences int) *ExtractiveSummarizer {
+	return &ExtractiveSummarizer{sentences: max(sentences, 1)}
+}
+
+func (s *ExtractiveSummarizer) Summarize(_ context.Context, text string) (model.Summary, error) {
+	sentences := candidates(splitSentences(text))
+	if len(sentences) == 0 {
+		return model.Summary{}, ErrNothingToSummarize
+	}
+
+	if len(sentences) <= s.sentences {
+		return model.Summary{Text: strings.Join(sentences, " "), Model: extractiveModel}, nil
+	}
+
+	stopwords := stopwordsOf(text)
+
+	terms := make([][]string, len(sentences))
+	for i, sentence := range sentences {
+		for _, word := range splitWords(sentence) {
+			if _, ok := stopwords[word]; !ok {
+				terms[i] = append(terms[i], stem(word))
+			}
+		}
+	}
+
+	scores := textRank(similarities(tfidf(terms)))
+
+	indices := make([]int, len(sentences))
+	for i := range indices {
+		indices[i] = i
+	}
+
+	// stable sort keeps earlier sentences first among equally ranked
+	sort.SliceStable(indices, func(i, j int) bool { return scores[indices[i]] > scores[indices[j]] })
+
+	picked := indices[:s.sentences]
+	sort.Ints(picked)
+
+	summary := make([]string, 0, len(picked))
+	for _, i := range picked {
+		summary = append(summary, sentences[i])
+	}
+
+	return model.Summary{Text: strings.Join(summary, " "), Model: extractiveModel}, nil
+}
+
+// splitSentences splits text into sentences. A line break always ends a sentence, while terminal punctuation
+// ends it only if it's followed by a space and the next word isn't lowercase, and "." isn't a part of
+// an abbreviation or initials.
+func splitSentences(text string) []string {
+	var (
+		sentences []string
+		current   strings.Builder
+	)
+
+	flush := func() {
+		// skip lines of punctuation, e.g. separators
+		if sentence := strings.Join(strings.Fields(current.String()), " "); len(splitWords(sentence)) > 0 {
+			sentences = append(sentences, sentence)
+		}
+
+		current.Reset()
+	}
+
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if r == '\n' {
+			flush()
+			continue
+		}
+
+		current.WriteRune(r)
+
+		if !isTerminal(r) {
+			continue
+		}
+
+		period := r == '.'
+
+		// punctuation may be repeated and followed by closing quotes or brackets, e.g. ?!» or ...)
+		for i+1 < len(runes) && (isTerminal(runes[i+1]) || isClosing(runes[i+1])) {
+			i++
+			period = period && !isTerminal(runes[i])
+			current.WriteRune(runes[i])
+		}
+
+		if i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			// numbers and domains: 1.21, go.dev
+			continue
+		}
+
+		if next := nextNonSpace(runes[i+1:]); unicode.IsLower(next) {
+			continue
+		}
+
+		if period && isAbbreviation(current.String()) {
+			continue
+		}
+
+		flush()
+	}
+
+	flush()
+
+	return sentences
+}
+
+func isTerminal(r rune) bool {
+	return r == '.' || r == '!' || r == '?' || r == '…'
+}
+
+func isClosing(r rune) bool {
+	return r == '"' || r == '\'' || r == ')' || r == '»' || r == '”' || r == '’'
+}
+
+func nextNonSpace(runes []rune) rune {
+	for _, r := range runes {
+		if !unicode.IsSpace(r) {
+			return r
+		}
+	}
+
+	return 0
+}
+
+// isAbbreviation reports whether the sentence ending with a single "." ends with an abbreviation or initials.
+func isAbbreviation(sentence string) bool {
+	fields := strings.Fields(sentence)
+	if len(fields) == 0 {
+		return false
+	}
+
+	word := strings.ToLower(strings.TrimLeftFunc(strings.TrimSuffix(fields[len(fields)-1], "."), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	}))
+
+	if len([]rune(word)) == 1 {
+		return true
+	}
+
+	_, ok := abbreviations[word]
+
+	return ok
+}
+
+// candidates returns sentences long enough to be a part of the summary, or all sentences if there are none.
+func candidates(sentences []string) []string {
+	var long []string
+
+	for _, sentence := range sentences {
+		if len(splitWords(sentence)) >= minSentenceWords {
+			long = append(long, sentence)
+		}
+	}
+
+	if len(long) == 0 {
+		return sentences
+	}
+
+	return long
+}
+
+func splitWords(sentence string) []string {
+	return strings.FieldsFunc(strings.ToLower(sentence), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// stem cuts endings of long words, so different forms of a word (which are many in Russian) match.
+// It's crude, but it needs no dictionaries and works the same for both languages.
+func stem(word string) string {
+	runes := []rune(word)
+	if len(runes) <= stemLength {
+		return word
+	}
+
+	return string(runes[:stemLength])
+}
+
+// tfidf returns weights of terms in each sentence: frequent in the sentence and rare in others weigh more.
+func tfidf(sentences [][]string) []map[string]float64 {
+	df := make(map[string]int)
+	for _, terms := range sentences {
+		seen := make(map[string]struct{}, len(terms))
+		for _, term := range terms {
+			if _, ok := seen[term]; !ok {
+				seen[term] = struct{}{}
+				df[term]++
+			}
+		}
+	}
+
+	n := float64(len(sentences))
+
+	vectors := make([]map[string]float64, len(sentences))
+	for i, terms := range sentences {
+		vectors[i] = make(map[string]float64, len(terms))
+		for _, term := range terms {
+			vectors[i][term]++
+		}
+
+		for term, tf := range vectors[i] {
+			vectors[i][term] = tf / float64(len(terms)) * (math.Log(n/float64(df[term])) + 1)
+		}
+	}
+
+	return vectors
+}
+
+// similarities returns cosine similarity of each pair of sentences.
+func similarities(vectors []map[string]float64) [][]float64 {
+	norms := make([]float64, len(vectors))
+	for i, vector := range vectors {
+		for _, w := range vector {
+			norms[i] += w * w
+		}
+
+		norms[i] = math.Sqrt(norms[i])
+	}
+
+	matrix := make([][]float64, len(vectors))
+	for i := range matrix {
+		matrix[i] = make([]float64, len(vectors))
+	}
+
+	for i := range vectors {
+		for j := i + 1; j < len(vectors); j++ {
+			if norms[i] == 0 || norms[j] == 0 {
+				continue
+			}
+
+			var dot float64
+			for term, w := range vectors[i] {
+				dot += w * vectors[j][term]
+			}
+
+			matrix[i][j] = dot / (norms[i] * norms[j])
+			matrix[j][i] = matrix[i][j]
+		}
+	}
+
+	return matrix
+}
+
+// textRank returns scores of graph nodes given as a weighted adjacency matrix.
+func textRank(matrix [][]float64) []float64 {
+	n := len(matrix)
+
+	weights := make([]float64, n)
+	for i, row := range matrix {
+		for _, w := range row {
+			weights[i] += w
+		}
+	}
+
+	scores := make([]float64, n)
+	for i := range scores {
+		scores[i] = 1
+	}
+
+	for iteration := 0; iteration < textRankIterations; iteration++ {
+		next := make([]float64, n)
+
+		var delta float64
+
+		for i := range matrix {
+			// the matrix is symmetric, so the row holds weights of incoming edges
+			var rank float64
+			for j, w := range matrix[i] {
+				if w == 0 || weights[j] == 0 {
+					continue
+				}
+
+				rank += w / weights[j] * scores[j]
+			}
+
+			next[i] = 1 - textRankDamping + textRankDamping*rank
+			delta += math.Abs(next[i] - scores[i])
+		}
+
+		scores = next
+
+		if delta < textRankTolerance {
+			break
+		}
+	}
+
+	return scores
+}
@@ -0,0 +1,43 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// Fallback tries summarizers in order until one of them succeeds, so e.g. a local summarizer
+// can back up an LLM provider when it's unavailable.
+type Fallback struct {
+	summarizers []Summarizer
+}
+
+func NewFallback(summarizers ...Summarizer) *Fallback {
+	return &Fallback{summarizers: summarizers}
+}
+
+func (f *Fallback) Summarize(ctx context.Context, text string) (model.Summary, error) {
+	if len(f.summarizers) == 0 {
+		return model.Summary{}, errors.New("no summarizers configured")
+	}
+
+	var errs []error
+
+	for i, summarizer := range f.summarizers {
+		summary, err := summarizer.Summarize(ctx, text)
+		if err == nil {
+			return summary, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%T: %w", summarizer, err))
+
+		if i < len(f.summarizers)-1 {
+			slog.Warn("summarizer failed, trying the next one", "summarizer", fmt.Sprintf("%T", summarizer), "error", err)
+		}
+	}
+
+	return model.Summary{}, errors.Join(errs...)
+}
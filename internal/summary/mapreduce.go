@@ -0,0 +1,219 @@
+package summary
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+const (
+	// charsPerToken estimates the number of tokens in a text without a tokenizer of the model.
+	// It's lower than the average for English to leave room for Russian, which takes more tokens.
+	charsPerToken = 3
+
+	// promptReserveTokens is reserved in the context window for the prompt and message formatting.
+	promptReserveTokens = 512
+
+	// minChunkTokens keeps chunks meaningful with a tiny context window.
+	minChunkTokens = 256
+
+	// maxReduceDepth limits how many times summaries of chunks are summarized again
+	// if together they still don't fit into the context window.
+	maxReduceDepth = 3
+)
+
+// Budget limits tokens spent on summarizing a single text.
+type Budget struct {
+	// ContextTokens is the context window of the model: the prompt, a chunk of the text and
+	// the summary must fit into it.
+	ContextTokens int
+	// MaxTokens is the maximal number of tokens of the text to be summarized, the rest of the text is cut off.
+	// Zero means no limit.
+	MaxTokens int
+	// Parallelism is the maximal number of chunks summarized at the same time, zero means one.
+	Parallelism int
+}
+
+// MapReduce summarizes texts which don't fit into the context window of the model: the text is split into chunks,
+// the chunks are summarized in parallel, and then summaries of the chunks are summarized into the final summary.
+type MapReduce struct {
+	summarizer Summarizer
+	budget     Budget
+}
+
+func NewMapReduce(summarizer Summarizer, budget Budget) *MapReduce {
+	return &MapReduce{summarizer: summarizer, budget: budget}
+}
+
+func (m *MapReduce) Summarize(ctx context.Context, text string) (model.Summary, error) {
+	if m.budget.MaxTokens > 0 && estimateTokens(text) > m.budget.MaxTokens {
+		slog.WarnContext(ctx, "text exceeds token budget, it's cut off", "tokens", estimateTokens(text), "budget", m.budget.MaxTokens)
+		text = cutTokens(text, m.budget.MaxTokens)
+	}
+
+	return m.summarize(ctx, text, 0)
+}
+
+func (m *MapReduce) summarize(ctx context.Context, text string, depth int) (model.Summary, error) {
+	chunkTokens := m.chunkTokens()
+
+	if estimateTokens(text) <= chunkTokens {
+		return m.summarizer.Summarize(ctx, text)
+	}
+
+	if depth >= maxReduceDepth {
+		return m.summarizer.Summarize(ctx, cutTokens(text, chunkTokens))
+	}
+
+	chunks := splitChunks(text, chunkTokens)
+
+	summaries, err := m.summarizeChunks(ctx, chunks)
+	if err != nil {
+		return model.Summary{}, err
+	}
+
+	return m.summarize(ctx, strings.Join(summaries, "\n\n"), depth+1)
+}
+
+// summarizeChunks summarizes chunks with at most Parallelism summaries generated at the same time.
+// Fails if any chunk fails, as the final summary would miss a part of the text.
+func (m *MapReduce) summarizeChunks(ctx context.Context, chunks []string) ([]string, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg        sync.WaitGroup
+		summaries = make([]string, len(chunks))
+		errs      = make([]error, len(chunks))
+		sem       = make(chan struct{}, max(m.budget.Parallelism, 1))
+	)
+
+	for i, chunk := range chunks {
+		wg.Add(1)
+
+		go func(i int, chunk string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+
+			summary, err := m.summarizer.Summarize(ctx, chunk)
+			if err != nil {
+				errs[i] = err
+				// the rest of chunks are useless now
+				cancel()
+
+				return
+			}
+
+			summaries[i] = summary.Text
+		}(i, chunk)
+	}
+
+	wg.Wait()
+
+	// failure of a chunk cancels the others, so report the failure rather than cancellations
+	for _, err := range errs {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, err
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summaries, nil
+}
+
+func (m *MapReduce) chunkTokens() int {
+	return max(m.budget.ContextTokens-summaryMaxTokens-promptReserveTokens, minChunkTokens)
+}
+
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + charsPerToken - 1) / charsPerToken
+}
+
+// cutTokens cuts the text to fit into given number of tokens at a word boundary.
+func cutTokens(text string, tokens int) string {
+	chunks := splitChunks(text, tokens)
+	if len(chunks) == 0 {
+		return ""
+	}
+
+	return chunks[0]
+}
+
+// splitChunks splits text into chunks of up to given number of tokens. Chunks are split between paragraphs,
+// paragraphs which don't fit into a chunk are split between words.
+func splitChunks(text string, tokens int) []string {
+	var (
+		chunks  []string
+		current strings.Builder
+		// runes counts runes of the current chunk, so it's not counted again on every word
+		runes int
+		limit = tokens * charsPerToken
+	)
+
+	add := func(part, sep string) {
+		partRunes := utf8.RuneCountInString(part)
+
+		if current.Len() > 0 && runes+len(sep)+partRunes > limit {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			runes = 0
+		}
+
+		if current.Len() > 0 {
+			current.WriteString(sep)
+			runes += len(sep)
+		}
+
+		current.WriteString(part)
+		runes += partRunes
+	}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+
+		if estimateTokens(paragraph) <= tokens {
+			add(paragraph, "\n")
+			continue
+		}
+
+		for i, word := range strings.Fields(paragraph) {
+			sep := " "
+			if i == 0 {
+				sep = "\n"
+			}
+
+			// words longer than a chunk (e.g. base64 garbage) are cut
+			if utf8.RuneCountInString(word) > limit {
+				word = string([]rune(word)[:limit])
+			}
+
+			add(word, sep)
+		}
+	}
+
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+
+	return chunks
+}
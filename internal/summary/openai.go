@@ -4,44 +4,52 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
 )
 
+// openAIProvider is the provider label of metrics.
+const openAIProvider = "openai"
+
 type OpenAISummarizer struct {
 	client  *openai.Client
 	prompt  string
 	model   string
 	enabled bool
-	mu      sync.Mutex
 }
 
-func NewOpenAISummarizer(apiKey, model, prompt string) *OpenAISummarizer {
+// NewOpenAISummarizer creates summarizer using chat completions API of OpenAI, or of an OpenAI-compatible
+// server (e.g. llama.cpp or Ollama) if baseURL is set. Self-hosted servers usually need no API key.
+func NewOpenAISummarizer(apiKey, baseURL, model, prompt string) *OpenAISummarizer {
+	cfg := openai.DefaultConfig(apiKey)
+	if baseURL != "" {
+		cfg.BaseURL = strings.TrimSuffix(baseURL, "/")
+	}
+
 	s := &OpenAISummarizer{
-		client: openai.NewClient(apiKey),
+		client: openai.NewClientWithConfig(cfg),
 		prompt: prompt,
 		model:  model,
 	}
 
-	log.Printf("openai summarizer is enabled: %v", apiKey != "")
+	slog.Info("openai summarizer", "enabled", apiKey != "" || baseURL != "", "model", model, "base_url", cfg.BaseURL)
 
-	if apiKey != "" {
+	if apiKey != "" || baseURL != "" {
 		s.enabled = true
 	}
 
 	return s
 }
 
-func (s *OpenAISummarizer) Summarize(text string) (string, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+func (s *OpenAISummarizer) Summarize(ctx context.Context, text string) (model.Summary, error) {
 	if !s.enabled {
-		return "", fmt.Errorf("openai summarizer is disabled")
+		return model.Summary{}, fmt.Errorf("openai summarizer is disabled")
 	}
 
 	request := openai.ChatCompletionRequest{
@@ -56,30 +64,31 @@ func (s *OpenAISummarizer) Summarize(text string) (string, error) {
 				Content: text,
 			},
 		},
-		MaxTokens:   1024,
+		MaxTokens:   summaryMaxTokens,
 		Temperature: 1,
 		TopP:        1,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
-	defer cancel()
-
+	started := time.Now()
 	resp, err := s.client.CreateChatCompletion(ctx, request)
+	metrics.SummaryDuration.WithLabelValues(openAIProvider).Observe(time.Since(started).Seconds())
+
 	if err != nil {
-		return "", err
+		metrics.SummaryErrors.WithLabelValues(openAIProvider).Inc()
+		return model.Summary{}, err
 	}
 
-	if len(resp.Choices) == 0 {
-		return "", errors.New("no choices in openai response")
-	}
+	metrics.SummaryTokens.WithLabelValues(openAIProvider, "prompt").Add(float64(resp.Usage.PromptTokens))
+	metrics.SummaryTokens.WithLabelValues(openAIProvider, "completion").Add(float64(resp.Usage.CompletionTokens))
 
-	rawSummary := strings.TrimSpace(resp.Choices[0].Message.Content)
-	if strings.HasSuffix(rawSummary, ".") {
-		return rawSummary, nil
+	if len(resp.Choices) == 0 {
+		metrics.SummaryErrors.WithLabelValues(openAIProvider).Inc()
+		return model.Summary{}, errors.New("no choices in openai response")
 	}
 
-	// cut all after the last ".":
-	sentences := strings.Split(rawSummary, ".")
-
-	return strings.Join(sentences[:len(sentences)-1], ".") + ".", nil
+	return model.Summary{
+		Text:       completeSentences(resp.Choices[0].Message.Content),
+		Model:      s.model,
+		PromptHash: hashPrompt(s.prompt),
+	}, nil
 }
@@ -0,0 +1,58 @@
+package summary
+
+import (
+	"strings"
+	"unicode"
+)
+
+var (
+	englishStopwords = wordSet(`
+		a about above after again against all also am an and any are as at be because been before being
+		below between both but by can could did do does doing down during each few for from further had has
+		have having he her here hers herself him himself his how i if in into is it its itself just let me
+		more most my myself new no nor not now of off on once one only or other our ours ourselves out over
+		own said same she should so some such than that the their theirs them themselves then there these
+		they this those through to too under until up us very was we were what when where which while who
+		whom why will with would you your yours yourself yourselves`)
+
+	russianStopwords = wordSet(`
+		а без более бы был была были было быть в вам вас весь во вот все всего всех вы где да даже для до
+		его ее её ей если есть еще ещё же за здесь и из или им их к как какой когда кто ли либо меня мне
+		может мы на над надо наш не него нее неё нет ни них но ну о об однако он она они оно от очень по
+		под при про с со так также такой там те тем то того тоже той только том ты у уже хотя чего чей
+		чем что чтобы чье чья эта эти это этого этой этот я`)
+
+	// abbreviations ending with "." which don't end a sentence, single letters are initials.
+	abbreviations = wordSet(`
+		mr mrs ms dr prof sr jr st vs e.g i.e approx fig
+		т.е т.к т.н т.ч напр см рис стр ул гг им англ проф`)
+)
+
+func wordSet(words string) map[string]struct{} {
+	set := make(map[string]struct{})
+	for _, word := range strings.Fields(words) {
+		set[word] = struct{}{}
+	}
+
+	return set
+}
+
+// stopwordsOf returns stopwords of the text's language: Russian if most of letters are Cyrillic, English otherwise.
+func stopwordsOf(text string) map[string]struct{} {
+	var cyrillic, latin int
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Cyrillic, r):
+			cyrillic++
+		case unicode.Is(unicode.Latin, r):
+			latin++
+		}
+	}
+
+	if cyrillic > latin {
+		return russianStopwords
+	}
+
+	return englishStopwords
+}
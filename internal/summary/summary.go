@@ -0,0 +1,39 @@
+// Package summary generates short summaries of articles with LLM providers or locally.
+package summary
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/defer-panic/news-feed-bot/internal/model"
+)
+
+// summaryMaxTokens is the maximal number of tokens LLM generates for a summary.
+const summaryMaxTokens = 1024
+
+// Summarizer is implemented by all summarizers of the package.
+type Summarizer interface {
+	Summarize(ctx context.Context, text string) (model.Summary, error)
+}
+
+// hashPrompt identifies the prompt in stored summaries without storing the prompt itself.
+func hashPrompt(prompt string) string {
+	sum := sha256.Sum256([]byte(prompt))
+	return hex.EncodeToString(sum[:])
+}
+
+// completeSentences cuts an unfinished sentence at the end of the summary,
+// as the model may stop generating in the middle of it.
+func completeSentences(summary string) string {
+	summary = strings.TrimSpace(summary)
+	if strings.HasSuffix(summary, ".") {
+		return summary
+	}
+
+	// cut all after the last ".":
+	sentences := strings.Split(summary, ".")
+
+	return strings.Join(sentences[:len(sentences)-1], ".") + "."
+}
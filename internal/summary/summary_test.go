@@ -0,0 +1,351 @@
+package summary_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/defer-panic/news-feed-bot/internal/metrics"
+	"github.com/defer-panic/news-feed-bot/internal/model"
+	"github.com/defer-panic/news-feed-bot/internal/summary"
+)
+
+const article = `Go 1.21 is released. The release adds min, max and clear built-in functions.
+The new log/slog package brings structured logging to the standard library.
+Profile-guided optimization is now generally available in the Go toolchain.
+The weather was nice on the day of the release.
+Structured logging with slog is the most awaited addition to the standard library in this release.`
+
+func TestOpenAISummarizer_BaseURL(t *testing.T) {
+	var body map[string]any
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		_, _ = w.Write([]byte(`{
+			"choices": [{"message": {"role": "assistant", "content": "Go 1.21 is out. It adds slog. And"}}],
+			"usage": {"prompt_tokens": 10, "completion_tokens": 5}
+		}`))
+	}))
+	defer srv.Close()
+
+	s := summary.NewOpenAISummarizer("", srv.URL+"/v1/", "llama3", "Summarize")
+
+	got, err := s.Summarize(context.Background(), article)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Go 1.21 is out. It adds slog.", got.Text)
+	assert.Equal(t, "llama3", got.Model)
+	assert.NotEmpty(t, got.PromptHash)
+	assert.Equal(t, "llama3", body["model"])
+}
+
+func TestOpenAISummarizer_Disabled(t *testing.T) {
+	_, err := summary.NewOpenAISummarizer("", "", "gpt-3.5-turbo", "").Summarize(context.Background(), article)
+	assert.Error(t, err)
+}
+
+func TestAnthropicSummarizer(t *testing.T) {
+	var (
+		headers http.Header
+		body    map[string]any
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages", r.URL.Path)
+		headers = r.Header
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		_, _ = w.Write([]byte(`{
+			"content": [{"type": "text", "text": "Go 1.21 is out with slog."}],
+			"usage": {"input_tokens": 10, "output_tokens": 5}
+		}`))
+	}))
+	defer srv.Close()
+
+	s := summary.NewAnthropicSummarizer(srv.Client(), "key", srv.URL, "claude-3-5-haiku-latest", "Summarize")
+
+	got, err := s.Summarize(context.Background(), article)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Go 1.21 is out with slog.", got.Text)
+	assert.Equal(t, "claude-3-5-haiku-latest", got.Model)
+	assert.Equal(t, "key", headers.Get("X-Api-Key"))
+	assert.NotEmpty(t, headers.Get("Anthropic-Version"))
+	assert.Equal(t, "claude-3-5-haiku-latest", body["model"])
+	assert.Equal(t, "Summarize", body["system"])
+	assert.Equal(t, []any{map[string]any{"role": "user", "content": article}}, body["messages"])
+}
+
+func TestAnthropicSummarizer_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"type": "error", "error": {"type": "rate_limit_error", "message": "slow down"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := summary.NewAnthropicSummarizer(srv.Client(), "key", srv.URL, "claude", "").Summarize(context.Background(), article)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "slow down")
+}
+
+func TestExtractiveSummarizer(t *testing.T) {
+	s := summary.NewExtractiveSummarizer(2)
+
+	got, err := s.Summarize(context.Background(), article)
+	require.NoError(t, err)
+
+	assert.NotContains(t, got.Text, "weather")
+	assert.Equal(t, 2, strings.Count(got.Text, ". ")+1, got.Text)
+	assert.Equal(t, "extractive", got.Model)
+
+	again, err := s.Summarize(context.Background(), article)
+	require.NoError(t, err)
+	assert.Equal(t, got, again)
+}
+
+func TestExtractiveSummarizer_Russian(t *testing.T) {
+	text := `Вышел Go 1.21 с новым пакетом log/slog для структурированного логирования.
+Пакет slog т.е. структурированное логирование давно ждали в стандартной библиотеке Go.
+В тот день в Москве шел дождь и было очень холодно.
+Также в Go 1.21 добавлены встроенные функции min, max и clear.`
+
+	got, err := summary.NewExtractiveSummarizer(3).Summarize(context.Background(), text)
+	require.NoError(t, err)
+
+	assert.NotContains(t, got.Text, "дождь")
+	assert.Contains(t, got.Text, "т.е. структурированное логирование давно ждали в стандартной библиотеке Go.")
+}
+
+func TestExtractiveSummarizer_Sentences(t *testing.T) {
+	text := `Rob Pike and Dr. R. Griesemer talked about Go 1.21 at go.dev, e.g. about slog and generics.
+"Is it faster?!" asked the audience. The answer was yes, every release is faster.
+Share this`
+
+	got, err := summary.NewExtractiveSummarizer(3).Summarize(context.Background(), text)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		`Rob Pike and Dr. R. Griesemer talked about Go 1.21 at go.dev, e.g. about slog and generics. `+
+			`"Is it faster?!" asked the audience. The answer was yes, every release is faster.`,
+		got.Text,
+	)
+
+	got, err = summary.NewExtractiveSummarizer(3).Summarize(context.Background(), "Go 1.21 is out now!\n\nUpgrade to it today.\nShare")
+	require.NoError(t, err)
+	assert.Equal(t, "Go 1.21 is out now! Upgrade to it today.", got.Text)
+
+	_, err = summary.NewExtractiveSummarizer(3).Summarize(context.Background(), " \n ... ")
+	assert.ErrorIs(t, err, summary.ErrNothingToSummarize)
+}
+
+type summarizerFunc func(text string) (model.Summary, error)
+
+func (f summarizerFunc) Summarize(_ context.Context, text string) (model.Summary, error) {
+	return f(text)
+}
+
+func TestFallback(t *testing.T) {
+	var calls int
+
+	failing := summarizerFunc(func(string) (model.Summary, error) {
+		calls++
+		return model.Summary{}, errors.New("unavailable")
+	})
+	working := summarizerFunc(func(text string) (model.Summary, error) {
+		return model.Summary{Text: "summary of " + text, Model: "test"}, nil
+	})
+
+	got, err := summary.NewFallback(failing, working, failing).Summarize(context.Background(), "text")
+	require.NoError(t, err)
+
+	assert.Equal(t, model.Summary{Text: "summary of text", Model: "test"}, got)
+	assert.Equal(t, 1, calls)
+
+	_, err = summary.NewFallback(failing, failing).Summarize(context.Background(), "text")
+	assert.ErrorContains(t, err, "unavailable")
+	assert.Equal(t, 3, calls)
+}
+
+func TestMapReduce(t *testing.T) {
+	var (
+		mu               sync.Mutex
+		inputs           []string
+		running, maxRuns int
+	)
+
+	s := summarizerFunc(func(text string) (model.Summary, error) {
+		mu.Lock()
+		inputs = append(inputs, text)
+		running++
+		maxRuns = max(maxRuns, running)
+		mu.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		return model.Summary{Text: "summary", Model: "test"}, nil
+	})
+
+	// every paragraph takes about 1000 tokens, so each chunk of 1000 tokens holds one paragraph
+	paragraph := strings.Repeat("word ", 600)
+	text := strings.Repeat(paragraph+"\n", 8)
+
+	got, err := summary.NewMapReduce(s, summary.Budget{ContextTokens: 2536, MaxTokens: 6000, Parallelism: 2}).
+		Summarize(context.Background(), text)
+	require.NoError(t, err)
+
+	assert.Equal(t, model.Summary{Text: "summary", Model: "test"}, got)
+	assert.Equal(t, 2, maxRuns)
+
+	// 6000 tokens of the budget hold 6 paragraphs, then summaries of chunks are summarized together
+	require.Len(t, inputs, 7)
+	assert.Equal(t, strings.Repeat("summary\n\n", 5)+"summary", inputs[6])
+}
+
+func TestMapReduce_Short(t *testing.T) {
+	var calls int
+
+	s := summarizerFunc(func(text string) (model.Summary, error) {
+		calls++
+		return model.Summary{Text: text}, nil
+	})
+
+	got, err := summary.NewMapReduce(s, summary.Budget{ContextTokens: 4096}).Summarize(context.Background(), article)
+	require.NoError(t, err)
+
+	assert.Equal(t, article, got.Text)
+	assert.Equal(t, 1, calls)
+}
+
+func TestMapReduce_Error(t *testing.T) {
+	s := summarizerFunc(func(text string) (model.Summary, error) {
+		return model.Summary{}, errors.New("unavailable")
+	})
+
+	text := strings.Repeat(strings.Repeat("word ", 600)+"\n", 4)
+
+	_, err := summary.NewMapReduce(s, summary.Budget{ContextTokens: 2536, Parallelism: 4}).Summarize(context.Background(), text)
+	assert.ErrorContains(t, err, "unavailable")
+}
+
+type cachedSummary struct {
+	summary  model.Summary
+	cachedAt time.Time
+}
+
+type cacheStorageStub struct {
+	entries map[string]cachedSummary
+	getErr  error
+	pruned  int
+}
+
+func (s *cacheStorageStub) Get(_ context.Context, key string, since time.Time) (*model.Summary, error) {
+	if s.getErr != nil {
+		return nil, s.getErr
+	}
+
+	entry, ok := s.entries[key]
+	if !ok || entry.cachedAt.Before(since) {
+		return nil, nil
+	}
+
+	return &entry.summary, nil
+}
+
+func (s *cacheStorageStub) Put(_ context.Context, key string, summary model.Summary) error {
+	s.entries[key] = cachedSummary{summary: summary, cachedAt: time.Now()}
+	return nil
+}
+
+func (s *cacheStorageStub) Prune(context.Context, time.Time, int) error {
+	s.pruned++
+	return nil
+}
+
+func TestCache(t *testing.T) {
+	var calls int
+
+	s := summarizerFunc(func(text string) (model.Summary, error) {
+		calls++
+		return model.Summary{Text: "summary", Model: "cache-test"}, nil
+	})
+
+	storage := &cacheStorageStub{entries: make(map[string]cachedSummary)}
+	cache := summary.NewCache(storage, s, "cache-test", "Summarize", time.Hour, 100)
+
+	hits := testutil.ToFloat64(metrics.SummaryCacheHits.WithLabelValues("cache-test"))
+	misses := testutil.ToFloat64(metrics.SummaryCacheMisses.WithLabelValues("cache-test"))
+
+	for _, text := range []string{"Go 1.21 is released.\n\nIt adds slog.", "  Go 1.21 is released. It adds\tslog. "} {
+		got, err := cache.Summarize(context.Background(), text)
+		require.NoError(t, err)
+		assert.Equal(t, "summary", got.Text)
+	}
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, storage.pruned)
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.SummaryCacheHits.WithLabelValues("cache-test")))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.SummaryCacheMisses.WithLabelValues("cache-test")))
+
+	// another prompt makes another summary
+	_, err := summary.NewCache(storage, s, "cache-test", "Summarize in Russian", time.Hour, 100).
+		Summarize(context.Background(), "Go 1.21 is released. It adds slog.")
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// expired summaries are generated anew
+	for key, entry := range storage.entries {
+		entry.cachedAt = time.Now().Add(-2 * time.Hour)
+		storage.entries[key] = entry
+	}
+
+	_, err = cache.Summarize(context.Background(), "Go 1.21 is released. It adds slog.")
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	// skipping cache generates the summary anew
+	_, err = cache.Summarize(summary.SkipCache(context.Background()), "Go 1.21 is released. It adds slog.")
+	require.NoError(t, err)
+	assert.Equal(t, 4, calls)
+
+	// failing cache doesn't fail summaries
+	storage.getErr = errors.New("connection refused")
+
+	_, err = cache.Summarize(context.Background(), "Go 1.21 is released. It adds slog.")
+	require.NoError(t, err)
+	assert.Equal(t, 5, calls)
+}
+
+func TestCache_Prune(t *testing.T) {
+	s := summarizerFunc(func(text string) (model.Summary, error) {
+		return model.Summary{Text: "summary", Model: "cache-test"}, nil
+	})
+
+	storage := &cacheStorageStub{entries: make(map[string]cachedSummary)}
+	cache := summary.NewCache(storage, s, "cache-test", "Summarize", time.Hour, 100)
+
+	for i := 0; i < 201; i++ {
+		_, err := cache.Summarize(context.Background(), fmt.Sprintf("Go 1.%d is released.", i))
+		require.NoError(t, err)
+	}
+
+	// the cache is pruned on the first put and every 100 puts after it
+	assert.Equal(t, 3, storage.pruned)
+}